@@ -0,0 +1,158 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// maxDefinitionSize bounds the request body of endpoint definitions.
+const maxDefinitionSize = 1 << 20
+
+// redacted replaces the values of endpoints that may hold secrets.
+const redacted = "<redacted>"
+
+// EndpointDefinition is a monitored endpoint as defined, with its defaults
+// applied. Durations are in nanoseconds; definitions sent to the API may use
+// either nanoseconds or strings such as "30s". Header values, body, body_json
+// and form values are redacted since they often hold credentials.
+type EndpointDefinition struct {
+	common.Endpoint
+	Paused bool `json:"paused"`
+}
+
+func (s *Server) handleEndpoints(w http.ResponseWriter, r *http.Request) {
+	endpoints := s.opts.Endpoints()
+	definitions := make([]EndpointDefinition, 0, len(endpoints))
+	for _, ep := range endpoints {
+		definitions = append(definitions, s.definition(ep))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"endpoints": definitions})
+}
+
+func (s *Server) handleEndpoint(w http.ResponseWriter, r *http.Request) {
+	s.writeEndpoint(w, http.StatusOK, r.PathValue("id"))
+}
+
+// handleAddEndpoint starts monitoring the endpoint defined by the request body.
+func (s *Server) handleAddEndpoint(w http.ResponseWriter, r *http.Request) {
+	definition, err := readDefinition(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ep, err := s.opts.Manager.Add(r.Context(), definition)
+	if err != nil {
+		writeManagerError(w, err)
+		return
+	}
+	s.writeEndpoint(w, http.StatusCreated, ep.ID)
+}
+
+// handleUpdateEndpoint replaces the definition of an endpoint with the request body.
+func (s *Server) handleUpdateEndpoint(w http.ResponseWriter, r *http.Request) {
+	definition, err := readDefinition(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ep, err := s.opts.Manager.Update(r.Context(), r.PathValue("id"), definition)
+	if err != nil {
+		writeManagerError(w, err)
+		return
+	}
+	s.writeEndpoint(w, http.StatusOK, ep.ID)
+}
+
+func (s *Server) handleDeleteEndpoint(w http.ResponseWriter, r *http.Request) {
+	if err := s.opts.Manager.Delete(r.Context(), r.PathValue("id")); err != nil {
+		writeManagerError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handlePauseEndpoint(w http.ResponseWriter, r *http.Request) {
+	if err := s.opts.Manager.Pause(r.Context(), r.PathValue("id")); err != nil {
+		writeManagerError(w, err)
+		return
+	}
+	s.writeEndpoint(w, http.StatusOK, r.PathValue("id"))
+}
+
+func (s *Server) handleResumeEndpoint(w http.ResponseWriter, r *http.Request) {
+	if err := s.opts.Manager.Resume(r.Context(), r.PathValue("id")); err != nil {
+		writeManagerError(w, err)
+		return
+	}
+	s.writeEndpoint(w, http.StatusOK, r.PathValue("id"))
+}
+
+// writeEndpoint sends the definition of an endpoint, or 404 if it is unknown.
+func (s *Server) writeEndpoint(w http.ResponseWriter, code int, id string) {
+	ep, ok := s.endpoint(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown endpoint %q", id))
+		return
+	}
+	writeJSON(w, code, s.definition(ep))
+}
+
+// definition returns ep, redacted, with whether its checks are paused.
+func (s *Server) definition(ep common.Endpoint) EndpointDefinition {
+	return EndpointDefinition{Endpoint: redact(ep), Paused: s.paused(ep.ID)}
+}
+
+// redact returns a copy of ep with the values that may hold secrets masked.
+// The maps are copied, ep is left untouched.
+func redact(ep common.Endpoint) common.Endpoint {
+	ep.Headers = redactValues(ep.Headers)
+	ep.Form = redactValues(ep.Form)
+	if ep.Body != "" {
+		ep.Body = redacted
+	}
+	if ep.BodyJSON != nil {
+		ep.BodyJSON = redacted
+	}
+	return ep
+}
+
+func redactValues(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	masked := make(map[string]string, len(values))
+	for k := range values {
+		masked[k] = redacted
+	}
+	return masked
+}
+
+// readDefinition reads the endpoint definition in the request body.
+func readDefinition(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	definition, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxDefinitionSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading endpoint definition: %w", err)
+	}
+	if len(definition) == 0 {
+		return nil, fmt.Errorf("an endpoint definition is required")
+	}
+	return definition, nil
+}
+
+// writeManagerError answers with the status matching an EndpointManager error.
+func writeManagerError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrUnknownEndpoint):
+		code = http.StatusNotFound
+	case errors.Is(err, ErrEndpointExists):
+		code = http.StatusConflict
+	case errors.Is(err, ErrInvalidEndpoint):
+		code = http.StatusBadRequest
+	}
+	writeError(w, code, err.Error())
+}
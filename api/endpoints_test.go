@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mohamedbeat/pulse/common"
+	"github.com/mohamedbeat/pulse/state"
+	"github.com/mohamedbeat/pulse/store"
+)
+
+// fakeManager keeps endpoints in memory, requiring only a URL.
+type fakeManager struct {
+	endpoints []common.Endpoint
+	paused    map[string]bool
+}
+
+func (f *fakeManager) index(id string) int {
+	return slices.IndexFunc(f.endpoints, func(ep common.Endpoint) bool { return ep.ID == id })
+}
+
+func (f *fakeManager) decode(definition []byte) (common.Endpoint, error) {
+	var ep common.Endpoint
+	if err := json.Unmarshal(definition, &ep); err != nil || ep.URL == "" {
+		return ep, fmt.Errorf("%w: url is required", ErrInvalidEndpoint)
+	}
+	return ep, nil
+}
+
+func (f *fakeManager) Add(_ context.Context, definition []byte) (common.Endpoint, error) {
+	ep, err := f.decode(definition)
+	if err != nil {
+		return ep, err
+	}
+	if f.index(ep.ID) >= 0 {
+		return ep, fmt.Errorf("%q: %w", ep.ID, ErrEndpointExists)
+	}
+	f.endpoints = append(f.endpoints, ep)
+	return ep, nil
+}
+
+func (f *fakeManager) Update(_ context.Context, id string, definition []byte) (common.Endpoint, error) {
+	i := f.index(id)
+	if i < 0 {
+		return common.Endpoint{}, fmt.Errorf("%w %q", ErrUnknownEndpoint, id)
+	}
+	ep, err := f.decode(definition)
+	if err != nil {
+		return ep, err
+	}
+	ep.ID = id
+	f.endpoints[i] = ep
+	return ep, nil
+}
+
+func (f *fakeManager) Delete(_ context.Context, id string) error {
+	i := f.index(id)
+	if i < 0 {
+		return fmt.Errorf("%w %q", ErrUnknownEndpoint, id)
+	}
+	f.endpoints = slices.Delete(f.endpoints, i, i+1)
+	return nil
+}
+
+func (f *fakeManager) Pause(_ context.Context, id string) error {
+	if f.index(id) < 0 {
+		return fmt.Errorf("%w %q", ErrUnknownEndpoint, id)
+	}
+	f.paused[id] = true
+	return nil
+}
+
+func (f *fakeManager) Resume(_ context.Context, id string) error {
+	if f.index(id) < 0 {
+		return fmt.Errorf("%w %q", ErrUnknownEndpoint, id)
+	}
+	delete(f.paused, id)
+	return nil
+}
+
+// testToken is the API token of the test servers, sent by send.
+const testToken = "s3cret"
+
+func newManagedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	st, err := store.OpenSQLite(filepath.Join(t.TempDir(), "pulse.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+
+	manager := &fakeManager{endpoints: slices.Clone(testEndpoints), paused: make(map[string]bool)}
+	srv := NewServer(Config{Token: testToken}, Options{
+		Store:     st,
+		Endpoints: func() []common.Endpoint { return manager.endpoints },
+		Tracker:   state.NewTracker(st),
+		Paused:    func(id string) bool { return manager.paused[id] },
+		Manager:   manager,
+	})
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// send makes a request with the test token.
+func send(t *testing.T, ts *httptest.Server, method, path, body string, wantCode int, out any) {
+	t.Helper()
+	sendWithToken(t, ts, testToken, method, path, body, wantCode, out)
+}
+
+func sendWithToken(t *testing.T, ts *httptest.Server, token, method, path, body string, wantCode int, out any) {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != wantCode {
+		t.Fatalf("%s %s: expected status %d, got %d", method, path, wantCode, resp.StatusCode)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decoding: %v", method, path, err)
+		}
+	}
+}
+
+func TestServer_Endpoints(t *testing.T) {
+	ts := newManagedServer(t)
+
+	var list struct {
+		Endpoints []EndpointDefinition `json:"endpoints"`
+	}
+	get(t, ts, "/api/v1/endpoints", http.StatusOK, &list)
+	if len(list.Endpoints) != 3 || list.Endpoints[0].ID != "api" || list.Endpoints[0].Labels["team"] != "core" {
+		t.Fatalf("Expected the 3 endpoints, got %+v", list.Endpoints)
+	}
+
+	var ep EndpointDefinition
+	send(t, ts, http.MethodPost, "/api/v1/endpoints", `{"id":"web","url":"http://web.local"}`, http.StatusCreated, &ep)
+	if ep.ID != "web" || ep.URL != "http://web.local" || ep.Paused {
+		t.Errorf("Expected the added endpoint, got %+v", ep)
+	}
+	send(t, ts, http.MethodPost, "/api/v1/endpoints", `{"id":"web","url":"http://web.local"}`, http.StatusConflict, nil)
+	send(t, ts, http.MethodPost, "/api/v1/endpoints", `{"id":"nourl"}`, http.StatusBadRequest, nil)
+	send(t, ts, http.MethodPost, "/api/v1/endpoints", ``, http.StatusBadRequest, nil)
+
+	send(t, ts, http.MethodPut, "/api/v1/endpoints/web", `{"url":"http://web.local/health"}`, http.StatusOK, &ep)
+	if ep.ID != "web" || ep.URL != "http://web.local/health" {
+		t.Errorf("Expected the updated endpoint, got %+v", ep)
+	}
+	send(t, ts, http.MethodPut, "/api/v1/endpoints/missing", `{"url":"http://x"}`, http.StatusNotFound, nil)
+
+	send(t, ts, http.MethodPost, "/api/v1/endpoints/web/pause", ``, http.StatusOK, &ep)
+	if !ep.Paused {
+		t.Errorf("Expected web to be paused, got %+v", ep)
+	}
+	var status EndpointStatus
+	get(t, ts, "/api/v1/status/web", http.StatusOK, &status)
+	if !status.Paused {
+		t.Errorf("Expected the status of web to report it paused, got %+v", status)
+	}
+	send(t, ts, http.MethodPost, "/api/v1/endpoints/web/resume", ``, http.StatusOK, &ep)
+	if ep.Paused {
+		t.Errorf("Expected web to be resumed, got %+v", ep)
+	}
+	send(t, ts, http.MethodPost, "/api/v1/endpoints/missing/pause", ``, http.StatusNotFound, nil)
+
+	send(t, ts, http.MethodDelete, "/api/v1/endpoints/web", ``, http.StatusNoContent, nil)
+	get(t, ts, "/api/v1/endpoints/web", http.StatusNotFound, nil)
+	send(t, ts, http.MethodDelete, "/api/v1/endpoints/web", ``, http.StatusNotFound, nil)
+}
+
+func TestServer_EndpointsToken(t *testing.T) {
+	ts := newManagedServer(t)
+
+	definition := `{"id":"web","url":"http://web.local"}`
+	sendWithToken(t, ts, "", http.MethodPost, "/api/v1/endpoints", definition, http.StatusUnauthorized, nil)
+	sendWithToken(t, ts, "wrong", http.MethodPost, "/api/v1/endpoints", definition, http.StatusUnauthorized, nil)
+	sendWithToken(t, ts, "wrong", http.MethodPut, "/api/v1/endpoints/api", definition, http.StatusUnauthorized, nil)
+	sendWithToken(t, ts, "", http.MethodDelete, "/api/v1/endpoints/api", ``, http.StatusUnauthorized, nil)
+	sendWithToken(t, ts, "", http.MethodPost, "/api/v1/endpoints/api/pause", ``, http.StatusUnauthorized, nil)
+	sendWithToken(t, ts, "", http.MethodPost, "/api/v1/incidents/1/ack", ``, http.StatusUnauthorized, nil)
+	get(t, ts, "/api/v1/endpoints/web", http.StatusNotFound, nil)
+	get(t, ts, "/api/v1/endpoints/api", http.StatusOK, nil)
+}
+
+func TestServer_EndpointsRedacted(t *testing.T) {
+	ts := newManagedServer(t)
+
+	var ep EndpointDefinition
+	send(t, ts, http.MethodPost, "/api/v1/endpoints", `{
+		"id": "web",
+		"url": "http://web.local",
+		"headers": {"Authorization": "Bearer abc"},
+		"body": "{\"password\":\"hunter2\"}"
+	}`, http.StatusCreated, &ep)
+	get(t, ts, "/api/v1/endpoints/web", http.StatusOK, &ep)
+	if ep.Headers["Authorization"] != redacted || ep.Body != redacted {
+		t.Errorf("Expected the header values and body to be redacted, got %+v", ep)
+	}
+
+	headers := map[string]string{"Authorization": "Bearer abc"}
+	form := map[string]string{"password": "hunter2"}
+	original := common.Endpoint{Headers: headers, Form: form, BodyJSON: map[string]any{"key": "value"}}
+	masked := redact(original)
+	if masked.Form["password"] != redacted || masked.BodyJSON != redacted {
+		t.Errorf("Expected form values and body_json to be redacted, got %+v", masked)
+	}
+	if headers["Authorization"] != "Bearer abc" || form["password"] != "hunter2" {
+		t.Errorf("Expected the endpoint checked to be left alone, got %v and %v", headers, form)
+	}
+}
+
+func TestServer_EndpointsReadOnly(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	var ep EndpointDefinition
+	get(t, ts, "/api/v1/endpoints/db", http.StatusOK, &ep)
+	if ep.ID != "db" || ep.Type != common.TCPType {
+		t.Errorf("Expected the definition of db, got %+v", ep)
+	}
+	// Without a manager, endpoints cannot be changed
+	send(t, ts, http.MethodPost, "/api/v1/endpoints", `{"id":"web","url":"http://web.local"}`, http.StatusNotFound, nil)
+	send(t, ts, http.MethodDelete, "/api/v1/endpoints/db", ``, http.StatusNotFound, nil)
+}
@@ -0,0 +1,338 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+	"github.com/mohamedbeat/pulse/store"
+)
+
+// Pagination defaults of list endpoints
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// DefaultUptimePeriods are reported by /api/v1/uptime unless periods or a range are given.
+var DefaultUptimePeriods = []time.Duration{24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour}
+
+// EndpointStatus is the live state of a monitored endpoint.
+type EndpointStatus struct {
+	ID     string            `json:"id"`
+	Name   string            `json:"name"`
+	Type   string            `json:"type"`
+	URL    string            `json:"url"`
+	Tags   []string          `json:"tags,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	// Status follows the endpoint's alert rules, empty until its first check
+	Status      string           `json:"status"`
+	Flapping    bool             `json:"flapping"`
+	Maintenance bool             `json:"maintenance"`
+	Paused      bool             `json:"paused"`
+	LastCheck   *common.Result   `json:"last_check,omitempty"`
+	Incident    *common.Incident `json:"incident,omitempty"` // the open incident
+}
+
+// Page describes the slice of a list a response holds. NextOffset is set when
+// there may be more.
+type Page struct {
+	Limit      int `json:"limit"`
+	Offset     int `json:"offset"`
+	NextOffset int `json:"next_offset,omitempty"`
+}
+
+// UptimeSummary is the uptime of an endpoint over a period or range.
+type UptimeSummary struct {
+	store.Uptime
+	Period string `json:"period,omitempty"` // e.g. "24h0m0s", empty for an explicit range
+	// Percent is the share of results that were up or degraded, null without results
+	Percent *float64 `json:"uptime_percent"`
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	latest, err := s.opts.Store.Latest(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	now := time.Now()
+	endpoints := s.opts.Endpoints()
+	statuses := make([]EndpointStatus, 0, len(endpoints))
+	for _, ep := range endpoints {
+		statuses = append(statuses, s.status(ep, latest, now))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"endpoints": statuses})
+}
+
+func (s *Server) handleEndpointStatus(w http.ResponseWriter, r *http.Request) {
+	ep, ok := s.endpoint(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown endpoint %q", r.PathValue("id")))
+		return
+	}
+	latest, err := s.opts.Store.Latest(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, s.status(ep, latest, time.Now()))
+}
+
+// status builds the live state of ep.
+func (s *Server) status(ep common.Endpoint, latest map[string]common.Result, now time.Time) EndpointStatus {
+	st := EndpointStatus{
+		ID:     ep.ID,
+		Name:   ep.Name,
+		Type:   ep.Type,
+		URL:    ep.URL,
+		Tags:   ep.Tags,
+		Labels: ep.Labels,
+	}
+	st.Status, _ = s.opts.Tracker.Status(ep.ID)
+	st.Flapping = s.opts.Tracker.Flapping(ep.ID)
+	if s.opts.InMaintenance != nil {
+		st.Maintenance = s.opts.InMaintenance(ep, now)
+	}
+	st.Paused = s.paused(ep.ID)
+	if res, ok := latest[ep.ID]; ok {
+		st.LastCheck = &res
+	}
+	if incident, ok := s.opts.Tracker.OpenIncident(ep.ID); ok {
+		st.Incident = &incident
+	}
+	return st
+}
+
+// paused reports whether the checks of an endpoint are paused.
+func (s *Server) paused(id string) bool {
+	return s.opts.Paused != nil && s.opts.Paused(id)
+}
+
+// endpoint returns the monitored endpoint with the given ID.
+func (s *Server) endpoint(id string) (common.Endpoint, bool) {
+	for _, ep := range s.opts.Endpoints() {
+		if ep.ID == id {
+			return ep, true
+		}
+	}
+	return common.Endpoint{}, false
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := store.HistoryQuery{Endpoint: q.Get("endpoint")}
+	if query.Endpoint == "" {
+		writeError(w, http.StatusBadRequest, "endpoint is required")
+		return
+	}
+	var (
+		page Page
+		err  error
+	)
+	if query.From, query.To, err = parseRange(q); err == nil {
+		page, err = parsePage(q)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// One more than asked tells whether there is a next page
+	query.Limit, query.Offset = page.Limit+1, page.Offset
+
+	results, err := s.opts.Store.History(r.Context(), query)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(results) > page.Limit {
+		results = results[:page.Limit]
+		page.NextOffset = page.Offset + page.Limit
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"results": results, "page": page})
+}
+
+func (s *Server) handleIncidents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := store.IncidentQuery{Endpoint: q.Get("endpoint")}
+	var (
+		page Page
+		err  error
+	)
+	if query.From, query.To, err = parseRange(q); err == nil {
+		page, err = parsePage(q)
+	}
+	if err == nil && q.Has("open") {
+		query.OnlyOpen, err = strconv.ParseBool(q.Get("open"))
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query.Limit, query.Offset = page.Limit+1, page.Offset
+
+	incidents, err := s.opts.Store.Incidents(r.Context(), query)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(incidents) > page.Limit {
+		incidents = incidents[:page.Limit]
+		page.NextOffset = page.Offset + page.Limit
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"incidents": incidents, "page": page})
+}
+
+// DefaultAcknowledgedBy is recorded when an acknowledgement does not say who is handling the incident.
+const DefaultAcknowledgedBy = "api"
+
+// handleAcknowledgeIncident records that someone is handling an open incident,
+// which stops its escalation. The optional body names them: {"by": "alice"}.
+func (s *Server) handleAcknowledgeIncident(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid incident id %q", r.PathValue("id")))
+		return
+	}
+	var body struct {
+		By string `json:"by"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDefinitionSize)).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("decoding acknowledgement: %v", err))
+		return
+	}
+	if body.By == "" {
+		body.By = DefaultAcknowledgedBy
+	}
+
+	err = s.opts.Store.AcknowledgeIncident(r.Context(), id, body.By, time.Now())
+	switch {
+	case errors.Is(err, store.ErrIncidentNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, store.ErrIncidentNotOpen):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if s.opts.Acknowledged != nil {
+		s.opts.Acknowledged(id, body.By)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleUptime reports the uptime of every endpoint (or the one asked for) over
+// the default periods, the periods asked for, or an explicit from/to range.
+func (s *Server) handleUptime(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	now := time.Now()
+
+	type window struct {
+		period   string
+		from, to time.Time
+	}
+	var windows []window
+	switch {
+	case q.Has("from") || q.Has("to"):
+		from, to, err := parseRange(q)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if to.IsZero() {
+			to = now
+		}
+		windows = append(windows, window{from: from, to: to})
+	default:
+		periods := DefaultUptimePeriods
+		if q.Has("periods") {
+			periods = nil
+			for _, p := range strings.Split(q.Get("periods"), ",") {
+				d, err := time.ParseDuration(strings.TrimSpace(p))
+				if err != nil || d <= 0 {
+					writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid period %q", p))
+					return
+				}
+				periods = append(periods, d)
+			}
+		}
+		for _, d := range periods {
+			windows = append(windows, window{period: d.String(), from: now.Add(-d), to: now})
+		}
+	}
+
+	endpoints := s.opts.Endpoints()
+	if id := q.Get("endpoint"); id != "" {
+		ep, ok := s.endpoint(id)
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("unknown endpoint %q", id))
+			return
+		}
+		endpoints = []common.Endpoint{ep}
+	}
+
+	summaries := make(map[string][]UptimeSummary, len(endpoints))
+	for _, ep := range endpoints {
+		for _, win := range windows {
+			uptime, err := s.opts.Store.Uptime(r.Context(), store.UptimeQuery{Endpoint: ep.ID, From: win.from, To: win.to})
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			summary := UptimeSummary{Uptime: uptime, Period: win.period}
+			if percent, ok := uptime.Percent(); ok {
+				summary.Percent = &percent
+			}
+			summaries[ep.ID] = append(summaries[ep.ID], summary)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"uptime": summaries})
+}
+
+// parseRange reads the optional from and to parameters, RFC 3339 timestamps.
+func parseRange(q url.Values) (from, to time.Time, err error) {
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		if v := q.Get(p.name); v != "" {
+			if *p.t, err = time.Parse(time.RFC3339, v); err != nil {
+				return from, to, fmt.Errorf("invalid %s: use an RFC 3339 timestamp, e.g. 2026-01-02T15:04:05Z", p.name)
+			}
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return from, to, fmt.Errorf("to must not be before from")
+	}
+	return from, to, nil
+}
+
+// parsePage reads the limit and offset parameters.
+func parsePage(q url.Values) (Page, error) {
+	page := Page{Limit: DefaultLimit}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxLimit {
+			return page, fmt.Errorf("invalid limit: must be between 1 and %d", MaxLimit)
+		}
+		page.Limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return page, fmt.Errorf("invalid offset: must be non-negative")
+		}
+		page.Offset = n
+	}
+	return page, nil
+}
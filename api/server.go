@@ -0,0 +1,197 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+	"github.com/mohamedbeat/pulse/store"
+)
+
+// readHeaderTimeout bounds how long a client may take to send request headers.
+const readHeaderTimeout = 10 * time.Second
+
+// Config configures the embedded HTTP server.
+type Config struct {
+	// Listen is the address to serve on, e.g. ":8080" or "127.0.0.1:8080". Empty disables the server.
+	Listen string `mapstructure:"listen" json:"listen,omitempty" yaml:"listen,omitempty"`
+	// Token is the bearer token clients must send to change anything: add, update,
+	// pause or delete endpoints and acknowledge incidents. Environment variables are
+	// expanded. Empty leaves the API read-only.
+	Token string `mapstructure:"token" json:"token,omitempty" yaml:"token,omitempty"`
+}
+
+// ValidateConfig checks the listen address.
+func ValidateConfig(cfg *Config) error {
+	if cfg.Listen == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(cfg.Listen); err != nil {
+		return fmt.Errorf("invalid listen address %q: %w", cfg.Listen, err)
+	}
+	return nil
+}
+
+// Tracker reports the live state of endpoints. *state.Tracker implements it.
+type Tracker interface {
+	Status(endpointID string) (string, bool)
+	Flapping(endpointID string) bool
+	OpenIncident(endpointID string) (common.Incident, bool)
+}
+
+// EndpointManager changes the monitored endpoints at runtime. Definitions are
+// JSON objects with the keys of an endpoint in the config file. Errors wrap
+// ErrUnknownEndpoint, ErrEndpointExists or ErrInvalidEndpoint when they apply.
+type EndpointManager interface {
+	Add(ctx context.Context, definition []byte) (common.Endpoint, error)
+	Update(ctx context.Context, id string, definition []byte) (common.Endpoint, error)
+	Delete(ctx context.Context, id string) error
+	Pause(ctx context.Context, id string) error
+	Resume(ctx context.Context, id string) error
+}
+
+// Errors of an EndpointManager, answered with 404, 409 and 400
+var (
+	ErrUnknownEndpoint = errors.New("unknown endpoint")
+	ErrEndpointExists  = errors.New("endpoint already exists")
+	ErrInvalidEndpoint = errors.New("invalid endpoint")
+)
+
+// Options are what the server reads its answers from.
+type Options struct {
+	Store store.Store
+	// Endpoints returns the monitored endpoints, paused ones included
+	Endpoints func() []common.Endpoint
+	Tracker   Tracker
+	// InMaintenance reports whether an endpoint is in a maintenance window or silenced at a time
+	InMaintenance func(ep common.Endpoint, t time.Time) bool
+	// Paused reports whether the checks of an endpoint are paused
+	Paused func(endpointID string) bool
+	// Manager, when set, lets clients add, change, pause and delete endpoints
+	Manager EndpointManager
+	// Acknowledged, when set, is told of the incidents acknowledged through the API
+	Acknowledged func(incidentID int64, by string)
+}
+
+// Server serves the REST API.
+type Server struct {
+	opts   Options
+	token  string
+	server *http.Server
+	ln     net.Listener
+	served chan error
+}
+
+// NewServer returns a server for the API. It does not listen until Start.
+func NewServer(cfg Config, opts Options) *Server {
+	s := &Server{opts: opts, token: os.ExpandEnv(cfg.Token)}
+	s.server = &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	return s
+}
+
+// Handler returns the API routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/status", s.handleStatus)
+	mux.HandleFunc("GET /api/v1/status/{id}", s.handleEndpointStatus)
+	mux.HandleFunc("GET /api/v1/history", s.handleHistory)
+	mux.HandleFunc("GET /api/v1/incidents", s.handleIncidents)
+	mux.HandleFunc("GET /api/v1/uptime", s.handleUptime)
+	mux.HandleFunc("GET /api/v1/endpoints", s.handleEndpoints)
+	mux.HandleFunc("GET /api/v1/endpoints/{id}", s.handleEndpoint)
+	// Changes need a token: without one the API is read-only
+	if s.token != "" {
+		mux.HandleFunc("POST /api/v1/incidents/{id}/ack", s.authorized(s.handleAcknowledgeIncident))
+		if s.opts.Manager != nil {
+			mux.HandleFunc("POST /api/v1/endpoints", s.authorized(s.handleAddEndpoint))
+			mux.HandleFunc("PUT /api/v1/endpoints/{id}", s.authorized(s.handleUpdateEndpoint))
+			mux.HandleFunc("DELETE /api/v1/endpoints/{id}", s.authorized(s.handleDeleteEndpoint))
+			mux.HandleFunc("POST /api/v1/endpoints/{id}/pause", s.authorized(s.handlePauseEndpoint))
+			mux.HandleFunc("POST /api/v1/endpoints/{id}/resume", s.authorized(s.handleResumeEndpoint))
+		}
+	}
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "not found")
+	})
+	return mux
+}
+
+// authorized answers 401 to requests without the configured bearer token.
+func (s *Server) authorized(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// Start listens on the configured address and serves in the background.
+// Listening errors, e.g. an address already in use, are returned right away.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	s.ln = ln
+	s.served = make(chan error, 1)
+	go func() {
+		s.served <- s.server.Serve(ln)
+	}()
+	return nil
+}
+
+// Addr returns the address the server listens on, once started.
+func (s *Server) Addr() string {
+	if s.ln == nil {
+		return ""
+	}
+	return s.ln.Addr().String()
+}
+
+// Shutdown stops accepting connections and waits up to timeout for in-flight
+// requests to complete. Requests still running after timeout are cut off.
+func (s *Server) Shutdown(timeout time.Duration) error {
+	if s.served == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := s.server.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		s.server.Close()
+		err = fmt.Errorf("requests still running after %s were cut off", timeout)
+	}
+	if serveErr := <-s.served; !errors.Is(serveErr, http.ErrServerClosed) {
+		return serveErr
+	}
+	return err
+}
+
+// writeJSON sends v as the JSON response body.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError sends {"error": msg}.
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
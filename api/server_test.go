@@ -0,0 +1,300 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+	"github.com/mohamedbeat/pulse/state"
+	"github.com/mohamedbeat/pulse/store"
+)
+
+var testEndpoints = []common.Endpoint{
+	{ID: "api", Name: "API", Type: common.HTTPType, URL: "http://api.local/health", Labels: map[string]string{"team": "core"}},
+	{ID: "db", Name: "DB", Type: common.TCPType, URL: "db.local:5432", Tags: []string{"db"}},
+	{ID: "new", Name: "New", Type: common.HTTPType, URL: "http://new.local"},
+}
+
+// newTestServer serves the API over a store holding a few results: api went
+// down an hour ago and is still down, db is up but in maintenance.
+func newTestServer(t *testing.T) (*httptest.Server, time.Time) {
+	t.Helper()
+	st, err := store.OpenSQLite(filepath.Join(t.TempDir(), "pulse.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+
+	ctx := context.Background()
+	tracker := state.NewTracker(st)
+	now := time.Now().UTC().Truncate(time.Second)
+	for i := range 6 {
+		at := now.Add(time.Duration(i-6) * 15 * time.Minute)
+		status := common.StatusUp
+		if i >= 2 {
+			status = common.StatusDown
+		}
+		for _, res := range []common.Result{
+			{EndpointID: "api", URL: "http://api.local/health", Status: status, StatusCode: 200, Timestamp: at},
+			{EndpointID: "db", URL: "db.local:5432", Status: common.StatusUp, Timestamp: at},
+		} {
+			if _, err := tracker.Observe(ctx, res); err != nil {
+				t.Fatal(err)
+			}
+			if err := st.SaveResult(ctx, res); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	srv := NewServer(Config{}, Options{
+		Store:     st,
+		Endpoints: func() []common.Endpoint { return testEndpoints },
+		Tracker:   tracker,
+		InMaintenance: func(ep common.Endpoint, _ time.Time) bool {
+			return ep.ID == "db"
+		},
+	})
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts, now
+}
+
+func get(t *testing.T, ts *httptest.Server, path string, wantCode int, out any) {
+	t.Helper()
+	resp, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != wantCode {
+		t.Fatalf("GET %s: expected status %d, got %d", path, wantCode, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("GET %s: expected a JSON response, got %q", path, ct)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("GET %s: decoding: %v", path, err)
+		}
+	}
+}
+
+func TestServer_Status(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	var body struct {
+		Endpoints []EndpointStatus `json:"endpoints"`
+	}
+	get(t, ts, "/api/v1/status", http.StatusOK, &body)
+	if len(body.Endpoints) != 3 {
+		t.Fatalf("Expected 3 endpoints, got %d", len(body.Endpoints))
+	}
+
+	api, db, fresh := body.Endpoints[0], body.Endpoints[1], body.Endpoints[2]
+	if api.Status != common.StatusDown || api.Incident == nil || !api.Incident.Open() || api.LastCheck == nil {
+		t.Errorf("Expected api to be down with an open incident, got %+v", api)
+	}
+	if api.Labels["team"] != "core" {
+		t.Errorf("Expected labels to be reported, got %v", api.Labels)
+	}
+	if db.Status != common.StatusUp || !db.Maintenance || db.Incident != nil {
+		t.Errorf("Expected db to be up and in maintenance, got %+v", db)
+	}
+	if fresh.Status != "" || fresh.LastCheck != nil {
+		t.Errorf("Expected an unchecked endpoint to have no status, got %+v", fresh)
+	}
+
+	var one EndpointStatus
+	get(t, ts, "/api/v1/status/api", http.StatusOK, &one)
+	if one.ID != "api" || one.Status != common.StatusDown {
+		t.Errorf("Expected the status of api, got %+v", one)
+	}
+	get(t, ts, "/api/v1/status/missing", http.StatusNotFound, nil)
+}
+
+func TestServer_History(t *testing.T) {
+	ts, now := newTestServer(t)
+
+	var body struct {
+		Results []common.Result `json:"results"`
+		Page    Page            `json:"page"`
+	}
+	get(t, ts, "/api/v1/history?endpoint=api&limit=4", http.StatusOK, &body)
+	if len(body.Results) != 4 || body.Page.NextOffset != 4 {
+		t.Fatalf("Expected a first page of 4 with more to come, got %d results and %+v", len(body.Results), body.Page)
+	}
+	if !body.Results[0].Timestamp.After(body.Results[1].Timestamp) {
+		t.Errorf("Expected newest results first")
+	}
+
+	body.Results, body.Page = nil, Page{}
+	get(t, ts, "/api/v1/history?endpoint=api&limit=4&offset=4", http.StatusOK, &body)
+	if len(body.Results) != 2 || body.Page.NextOffset != 0 {
+		t.Errorf("Expected a last page of 2, got %d results and %+v", len(body.Results), body.Page)
+	}
+
+	from := now.Add(-35 * time.Minute).Format(time.RFC3339)
+	body.Results = nil
+	get(t, ts, "/api/v1/history?endpoint=api&from="+from, http.StatusOK, &body)
+	if len(body.Results) != 2 {
+		t.Errorf("Expected 2 results since %s, got %d", from, len(body.Results))
+	}
+
+	for _, path := range []string{
+		"/api/v1/history",
+		"/api/v1/history?endpoint=api&from=yesterday",
+		"/api/v1/history?endpoint=api&limit=0",
+		"/api/v1/history?endpoint=api&limit=5000",
+		"/api/v1/history?endpoint=api&offset=-1",
+		"/api/v1/history?endpoint=api&from=2026-01-02T00:00:00Z&to=2026-01-01T00:00:00Z",
+	} {
+		get(t, ts, path, http.StatusBadRequest, nil)
+	}
+}
+
+func TestServer_Incidents(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	var body struct {
+		Incidents []common.Incident `json:"incidents"`
+	}
+	get(t, ts, "/api/v1/incidents?open=true", http.StatusOK, &body)
+	if len(body.Incidents) != 1 || body.Incidents[0].EndpointID != "api" {
+		t.Errorf("Expected the open incident of api, got %+v", body.Incidents)
+	}
+
+	body.Incidents = nil
+	get(t, ts, "/api/v1/incidents?endpoint=db", http.StatusOK, &body)
+	if len(body.Incidents) != 0 {
+		t.Errorf("Expected no incidents for db, got %+v", body.Incidents)
+	}
+	get(t, ts, "/api/v1/incidents?open=maybe", http.StatusBadRequest, nil)
+}
+
+func TestServer_AcknowledgeIncident(t *testing.T) {
+	st, err := store.OpenSQLite(filepath.Join(t.TempDir(), "pulse.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	ctx := context.Background()
+	incident := common.Incident{EndpointID: "api", Status: common.StatusDown, StartedAt: time.Now()}
+	if err := st.OpenIncident(ctx, &incident); err != nil {
+		t.Fatal(err)
+	}
+
+	acknowledged := make(map[int64]string)
+	srv := NewServer(Config{Token: testToken}, Options{
+		Store:        st,
+		Endpoints:    func() []common.Endpoint { return testEndpoints },
+		Tracker:      state.NewTracker(st),
+		Acknowledged: func(id int64, by string) { acknowledged[id] = by },
+	})
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	path := fmt.Sprintf("/api/v1/incidents/%d/ack", incident.ID)
+	send(t, ts, http.MethodPost, path, `{"by":"alice"}`, http.StatusNoContent, nil)
+	if acknowledged[incident.ID] != "alice" {
+		t.Errorf("Expected the escalation of the incident to be acknowledged by alice, got %v", acknowledged)
+	}
+	open, _ := st.OpenIncidents(ctx)
+	if len(open) != 1 || !open[0].Acknowledged() || open[0].AcknowledgedBy != "alice" {
+		t.Errorf("Expected the acknowledgement to be stored, got %+v", open)
+	}
+
+	send(t, ts, http.MethodPost, path, ``, http.StatusConflict, nil)
+	send(t, ts, http.MethodPost, "/api/v1/incidents/999/ack", ``, http.StatusNotFound, nil)
+	send(t, ts, http.MethodPost, "/api/v1/incidents/abc/ack", ``, http.StatusBadRequest, nil)
+	send(t, ts, http.MethodPost, path, `{"by":`, http.StatusBadRequest, nil)
+	if len(acknowledged) != 1 {
+		t.Errorf("Expected failed acknowledgements not to reach the escalator, got %v", acknowledged)
+	}
+}
+
+func TestServer_Uptime(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	var body struct {
+		Uptime map[string][]UptimeSummary `json:"uptime"`
+	}
+	get(t, ts, "/api/v1/uptime", http.StatusOK, &body)
+	if len(body.Uptime) != 3 || len(body.Uptime["api"]) != len(DefaultUptimePeriods) {
+		t.Fatalf("Expected the default periods of every endpoint, got %+v", body.Uptime)
+	}
+	day := body.Uptime["api"][0]
+	if day.Period != "24h0m0s" || day.Count != 6 || day.Percent == nil || *day.Percent < 33 || *day.Percent > 34 {
+		t.Errorf("Expected a third of the last day up, got %+v", day)
+	}
+	if fresh := body.Uptime["new"][0]; fresh.Count != 0 || fresh.Percent != nil {
+		t.Errorf("Expected no uptime for an unchecked endpoint, got %+v", fresh)
+	}
+
+	body.Uptime = nil
+	get(t, ts, "/api/v1/uptime?endpoint=db&periods=1h,2h", http.StatusOK, &body)
+	if len(body.Uptime) != 1 || len(body.Uptime["db"]) != 2 {
+		t.Fatalf("Expected 2 periods for db, got %+v", body.Uptime)
+	}
+	if hour := body.Uptime["db"][0]; hour.Percent == nil || *hour.Percent != 100 {
+		t.Errorf("Expected db to be up for the last hour, got %+v", hour)
+	}
+
+	body.Uptime = nil
+	get(t, ts, "/api/v1/uptime?endpoint=api&from="+time.Now().Add(-50*time.Minute).UTC().Format(time.RFC3339), http.StatusOK, &body)
+	if r := body.Uptime["api"]; len(r) != 1 || r[0].Period != "" || r[0].Count != 3 || r[0].Down != 3 {
+		t.Errorf("Expected the 3 down results of the range, got %+v", r)
+	}
+
+	get(t, ts, "/api/v1/uptime?periods=1d", http.StatusBadRequest, nil)
+	get(t, ts, "/api/v1/uptime?endpoint=missing", http.StatusNotFound, nil)
+}
+
+func TestServer_StartAndShutdown(t *testing.T) {
+	st, err := store.OpenSQLite(filepath.Join(t.TempDir(), "pulse.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	srv := NewServer(Config{Listen: "127.0.0.1:0"}, Options{
+		Store:     st,
+		Endpoints: func() []common.Endpoint { return nil },
+		Tracker:   state.NewTracker(st),
+	})
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get("http://" + srv.Addr() + "/api/v1/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	if err := srv.Shutdown(time.Second); err != nil {
+		t.Errorf("Expected a clean shutdown, got %v", err)
+	}
+	if _, err := http.Get("http://" + srv.Addr() + "/api/v1/status"); err == nil {
+		t.Errorf("Expected the server to be closed")
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	for _, listen := range []string{"", ":8080", "127.0.0.1:8080", "[::1]:0"} {
+		if err := ValidateConfig(&Config{Listen: listen}); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", listen, err)
+		}
+	}
+	if err := ValidateConfig(&Config{Listen: "8080"}); err == nil {
+		t.Errorf("Expected a listen address without a port to be rejected")
+	}
+}
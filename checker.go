@@ -9,13 +9,3 @@ import (
 type Checker interface {
 	Check(ctx context.Context, ep common.Endpoint) common.Result
 }
-
-type TCPChecker struct{}
-
-func (c *TCPChecker) Check(ctx context.Context, ep common.Endpoint) common.Result {
-	panic("TODO")
-}
-
-type DNSChecker struct{}
-
-// etc.
@@ -0,0 +1,60 @@
+package common
+
+import (
+	"fmt"
+	"time"
+)
+
+// AlertRules decide when the results of an endpoint turn into an alert and when
+// it recovers. Any non-up result counts as a failure. The zero value alerts on
+// the first failure and recovers on the first up result.
+type AlertRules struct {
+	Failures int `mapstructure:"failures" json:"failures,omitempty" yaml:"failures,omitempty"` // consecutive failures before alerting
+	// Window and WindowFailures alert when at least WindowFailures of the last Window results failed
+	Window         int       `mapstructure:"window" json:"window,omitempty" yaml:"window,omitempty"`
+	WindowFailures int       `mapstructure:"window_failures" json:"window_failures,omitempty" yaml:"window_failures,omitempty"`
+	Recoveries     int       `mapstructure:"recoveries" json:"recoveries,omitempty" yaml:"recoveries,omitempty"` // consecutive up results before recovering
+	Flapping       FlapRules `mapstructure:"flapping" json:"flapping,omitzero" yaml:"flapping,omitempty"`
+}
+
+// FlapRules detect an endpoint oscillating between statuses. It is flapping once
+// Start transitions happened within Window, and stable again once the window
+// holds no more than Stop transitions. A zero Window disables detection.
+type FlapRules struct {
+	Window time.Duration `mapstructure:"window" json:"window,omitempty" yaml:"window,omitempty"`
+	Start  int           `mapstructure:"start" json:"start,omitempty" yaml:"start,omitempty"`
+	Stop   int           `mapstructure:"stop" json:"stop,omitempty" yaml:"stop,omitempty"`
+}
+
+// Enabled reports whether flap detection is on.
+func (f FlapRules) Enabled() bool {
+	return f.Window > 0
+}
+
+// ValidateAlertRules applies defaults to r and validates it.
+// Failures defaults to 1 unless a window is set, in which case 0 disables the
+// consecutive rule and only the window applies.
+func ValidateAlertRules(r *AlertRules) error {
+	if r.Failures < 0 || r.Window < 0 || r.WindowFailures < 0 || r.Recoveries < 0 {
+		return fmt.Errorf("alert rules must be non-negative")
+	}
+	if r.Window == 0 && r.WindowFailures > 0 {
+		return fmt.Errorf("window_failures needs a window")
+	}
+	if r.Window > 0 && (r.WindowFailures == 0 || r.WindowFailures > r.Window) {
+		return fmt.Errorf("window_failures must be between 1 and the window (%d)", r.Window)
+	}
+	if f := r.Flapping; f.Window < 0 || f.Start < 0 || f.Stop < 0 {
+		return fmt.Errorf("flapping rules must be non-negative")
+	}
+	if f := r.Flapping; f.Enabled() && (f.Start < 2 || f.Stop >= f.Start) {
+		return fmt.Errorf("flapping needs start of at least 2 transitions and a stop below it")
+	}
+	if r.Failures == 0 && r.Window == 0 {
+		r.Failures = 1
+	}
+	if r.Recoveries == 0 {
+		r.Recoveries = 1
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package common
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// DNS record types
+const (
+	RecordA     = "A"
+	RecordAAAA  = "AAAA"
+	RecordCNAME = "CNAME"
+	RecordMX    = "MX"
+	RecordTXT   = "TXT"
+	RecordSRV   = "SRV"
+)
+
+var ValidRecordTypes = map[string]bool{
+	RecordA:     true,
+	RecordAAAA:  true,
+	RecordCNAME: true,
+	RecordMX:    true,
+	RecordTXT:   true,
+	RecordSRV:   true,
+}
+
+// DNSOptions.Match
+const (
+	MatchExact    = "exact"    // answers must be exactly the expected set
+	MatchContains = "contains" // every expected value must be among the answers
+	MatchRegex    = "regex"    // every expected pattern must match at least one answer
+)
+
+var ValidMatchModes = map[string]bool{
+	MatchExact:    true,
+	MatchContains: true,
+	MatchRegex:    true,
+}
+
+// DNSOptions holds the DNS specific settings of an endpoint.
+// The endpoint URL is the name to resolve.
+//
+// Answers are compared in their textual form:
+//   - A/AAAA: "93.184.216.34", "2606:2800:220:1::248"
+//   - CNAME:  "target.example.com"
+//   - MX:     "10 mail.example.com"
+//   - TXT:    "v=spf1 -all" (multiple strings of one record are concatenated)
+//   - SRV:    "10 5 5060 sip.example.com" (priority weight port target)
+type DNSOptions struct {
+	Record   string   `mapstructure:"record" json:"record,omitempty" yaml:"record,omitempty"`       // defaults to A
+	Resolver string   `mapstructure:"resolver" json:"resolver,omitempty" yaml:"resolver,omitempty"` // host[:port], defaults to the system resolver
+	Expect   []string `mapstructure:"expect" json:"expect,omitempty" yaml:"expect,omitempty"`
+	Match    string   `mapstructure:"match" json:"match,omitempty" yaml:"match,omitempty"` // exact, contains (default), regex
+	// ExpectPatterns is Expect compiled once at config load when Match is regex.
+	ExpectPatterns []*regexp.Regexp `mapstructure:"-" json:"-" yaml:"-"`
+}
+
+// ValidateDNSOptions normalizes and validates o, compiling the expected patterns.
+// It returns nil if valid, or an error otherwise.
+func ValidateDNSOptions(o *DNSOptions) error {
+	o.Record = strings.ToUpper(o.Record)
+	if o.Record == "" {
+		o.Record = RecordA
+	}
+	if !ValidRecordTypes[o.Record] {
+		return fmt.Errorf("invalid record type: %q", o.Record)
+	}
+
+	o.Match = strings.ToLower(o.Match)
+	if o.Match == "" {
+		o.Match = MatchContains
+	}
+	if !ValidMatchModes[o.Match] {
+		return fmt.Errorf("invalid match mode: %q", o.Match)
+	}
+
+	if o.Resolver != "" {
+		resolver, err := NormalizeResolver(o.Resolver)
+		if err != nil {
+			return err
+		}
+		o.Resolver = resolver
+	}
+
+	o.ExpectPatterns = nil
+	if o.Match == MatchRegex {
+		for _, expr := range o.Expect {
+			pattern, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("invalid expect pattern %q: %w", expr, err)
+			}
+			o.ExpectPatterns = append(o.ExpectPatterns, pattern)
+		}
+	}
+
+	return nil
+}
+
+// NormalizeResolver turns a resolver address into "host:port", defaulting the port to 53.
+func NormalizeResolver(resolver string) (string, error) {
+	if _, _, err := net.SplitHostPort(resolver); err == nil {
+		return resolver, nil
+	}
+	host := strings.Trim(resolver, "[]")
+	if host == "" {
+		return "", fmt.Errorf("invalid resolver: %q", resolver)
+	}
+	return net.JoinHostPort(host, "53"), nil
+}
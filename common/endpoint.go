@@ -2,6 +2,7 @@ package common
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -33,10 +34,16 @@ func ValidateMethod(m string) error {
 
 const (
 	HTTPType = "HTTP"
+	TCPType  = "TCP"
+	DNSType  = "DNS"
+	TLSType  = "TLS"
 )
 
 var ValidTypes = map[string]bool{
 	HTTPType: true,
+	TCPType:  true,
+	DNSType:  true,
+	TLSType:  true,
 }
 
 // ValidateMethod checks whether Endpoint.Type is a valid HTTP method.
@@ -50,24 +57,44 @@ func ValidateType(ep *Endpoint) error {
 }
 
 type Endpoint struct {
+	ID              string            `mapstructure:"id" json:"id" yaml:"id"` // stable identity, derived from Name when empty
 	Name            string            `mapstructure:"name" json:"name" yaml:"name"`
+	Tags            []string          `mapstructure:"tags" json:"tags,omitempty" yaml:"tags,omitempty"` // used to select endpoints, e.g. in maintenance windows
+	Labels          map[string]string `mapstructure:"labels" json:"labels,omitempty" yaml:"labels,omitempty"` // attached to alerts and matched by notification routes
 	URL             string            `mapstructure:"url" json:"url" yaml:"url"`
 	Method          string            `mapstructure:"method" json:"method" yaml:"method"`
 	Timeout         time.Duration     `mapstructure:"timeout" json:"timeout" yaml:"timeout"`
 	Interval        time.Duration     `mapstructure:"interval" json:"interval" yaml:"interval"`
 	Headers         map[string]string `mapstructure:"headers" json:"headers,omitempty" yaml:"headers,omitempty"`
-	Type            string            `mapstructure:"type" json:"type" yaml:"type"` // http, tcp, dns
+	Body            string            `mapstructure:"body" json:"body,omitempty" yaml:"body,omitempty"`                // inline request body
+	BodyFile        string            `mapstructure:"body_file" json:"body_file,omitempty" yaml:"body_file,omitempty"` // request body read from a file
+	BodyJSON        any               `mapstructure:"body_json" json:"body_json,omitempty" yaml:"body_json,omitempty"` // JSON text or mapping
+	Form            map[string]string `mapstructure:"form" json:"form,omitempty" yaml:"form,omitempty"`                // url-encoded form fields
+	ContentType     string            `mapstructure:"content_type" json:"content_type,omitempty" yaml:"content_type,omitempty"`
+	Payload         *Payload          `mapstructure:"-" json:"-" yaml:"-"`          // request body compiled once at config load
+	Type            string            `mapstructure:"type" json:"type" yaml:"type"` // http, tcp, dns, tls
 	ExpectedStatus  int               `mapstructure:"expected_status" json:"expected_status" yaml:"expected_status"`
 	MustMatchStatus bool              `mapstructure:"must_match_status" json:"must_match_status" yaml:"must_match_status"`
 	BodyContains    string            `mapstructure:"body_contains" json:"body_contains,omitempty" yaml:"body_contains,omitempty"`
 	BodyRegex       string            `mapstructure:"body_regex" json:"body_regex,omitempty" yaml:"body_regex,omitempty"`
+	BodyPattern     *regexp.Regexp    `mapstructure:"-" json:"-" yaml:"-"` // BodyRegex compiled once at config load
+	JSONAssertions  []JSONAssertion   `mapstructure:"json_assertions" json:"json_assertions,omitempty" yaml:"json_assertions,omitempty"`
 	MaxLatency      time.Duration     `mapstructure:"max_latency" json:"max_latency" yaml:"max_latency"`
-	Retry           int               `mapstructure:"retry" json:"retry" yaml:"retry"`
-	RetryCounter    int               //Retry state counter
-	LastResult      *Result
+	Retry           int               `mapstructure:"retry" json:"retry" yaml:"retry"`                                     // immediate re-checks after a failure
+	RetryBackoff    time.Duration     `mapstructure:"retry_backoff" json:"retry_backoff" yaml:"retry_backoff"`             // delay before the first re-check
+	RetryMaxBackoff time.Duration     `mapstructure:"retry_max_backoff" json:"retry_max_backoff" yaml:"retry_max_backoff"` // upper bound of a single delay
+	RetryMultiplier float64           `mapstructure:"retry_multiplier" json:"retry_multiplier" yaml:"retry_multiplier"`    // growth factor between delays
+	RetryJitter     float64           `mapstructure:"retry_jitter" json:"retry_jitter" yaml:"retry_jitter"`                // ± fraction of randomness applied to delays
+	RetryMaxTime    time.Duration     `mapstructure:"retry_max_time" json:"retry_max_time" yaml:"retry_max_time"`          // cap on the total time spent retrying
+	TCP             TCPOptions        `mapstructure:"tcp" json:"tcp,omitzero" yaml:"tcp,omitempty"`
+	DNS             DNSOptions        `mapstructure:"dns" json:"dns,omitzero" yaml:"dns,omitempty"`
+	TLS             TLSOptions        `mapstructure:"tls" json:"tls,omitzero" yaml:"tls,omitempty"`
+	Alert           AlertRules        `mapstructure:"alert" json:"alert,omitzero" yaml:"alert,omitempty"` // when results turn into alerts
+	Escalation      string            `mapstructure:"escalation" json:"escalation,omitempty" yaml:"escalation,omitempty"` // name of the escalation policy of its alerts
 }
 
 type Result struct {
+	EndpointID string    `json:"endpoint_id" yaml:"endpoint_id"`
 	URL        string    `json:"url" yaml:"url"`
 	Status     string    `json:"status" yaml:"status"` // "up", "degraded", "down", "unreachable"
 	StatusCode int       `json:"status_code" yaml:"status_code"`
@@ -75,7 +102,24 @@ type Result struct {
 	Elapsed    int       `json:"elapsed_ms" yaml:"elapsed_ms"` // milliseconds
 	Error      string    `json:"error,omitempty" yaml:"error,omitempty"`
 	// Message    string    `json:"message,omitempty" yaml:"message,omitempty"`
-	Messages []string `json:"messages,omitempty" yaml:"messages,omitempty"`
+	Messages []string  `json:"messages,omitempty" yaml:"messages,omitempty"`
+	Answers  []string  `json:"answers,omitempty" yaml:"answers,omitempty"` // DNS answers
+	TLS      *TLSInfo  `json:"tls,omitempty" yaml:"tls,omitempty"`
+	Attempts []Attempt `json:"attempts,omitempty" yaml:"attempts,omitempty"` // set when the check was retried, the last attempt included
+	Flapping bool      `json:"flapping,omitempty" yaml:"flapping,omitempty"` // the endpoint was flapping when this result came in
+	// Maintenance is set for results checked during a maintenance window or silence:
+	// they are stored but never alerted on and left out of uptime
+	Maintenance bool `json:"maintenance,omitempty" yaml:"maintenance,omitempty"`
+}
+
+// Attempt is the outcome of a single try within a check that was retried.
+type Attempt struct {
+	Status     string    `json:"status" yaml:"status"`
+	StatusCode int       `json:"status_code,omitempty" yaml:"status_code,omitempty"`
+	Timestamp  time.Time `json:"timestamp" yaml:"timestamp"`
+	Elapsed    int       `json:"elapsed_ms" yaml:"elapsed_ms"`
+	Error      string    `json:"error,omitempty" yaml:"error,omitempty"`
+	Messages   []string  `json:"messages,omitempty" yaml:"messages,omitempty"`
 }
 
 // Result.Status
@@ -86,9 +130,38 @@ const (
 	StatusDegraded    = "degraded"
 )
 
+var statusSeverity = map[string]int{
+	StatusUp:          0,
+	StatusDegraded:    1,
+	StatusDown:        2,
+	StatusUnreachable: 3,
+}
+
+// StatusSeverity ranks a status from best (up) to worst (unreachable).
+func StatusSeverity(status string) int {
+	return statusSeverity[status]
+}
+
+// WorseStatus returns the more severe of two statuses.
+func WorseStatus(a, b string) string {
+	if statusSeverity[b] > statusSeverity[a] {
+		return b
+	}
+	return a
+}
+
 // Result.Message
 const (
 	UnexpectedStatusCodeMessage = "UnexpectedStatusCode"
 	UnexpectedBodyMessage       = "UnexpectedBody"
+	UnexpectedJSONMessage       = "UnexpectedJSON"
 	UnexpectedLatencyMessage    = "UnexpectedLatency"
+	UnexpectedReplyMessage      = "UnexpectedReply"
+	UnexpectedAnswerMessage     = "UnexpectedAnswer"
+	NXDomainMessage             = "NXDOMAIN"
+	ServFailMessage             = "SERVFAIL"
+	NoAnswerMessage             = "NoAnswer"
+	CertificateExpiringMessage  = "CertificateExpiring"
+	CertificateExpiredMessage   = "CertificateExpired"
+	InvalidCertificateMessage   = "InvalidCertificateChain"
 )
@@ -0,0 +1,33 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// idPattern keeps endpoint IDs safe to use in URLs, file names and log fields.
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ValidateID checks an explicitly configured endpoint ID.
+func ValidateID(id string) error {
+	if len(id) > 64 || !idPattern.MatchString(id) {
+		return fmt.Errorf("invalid id %q: use up to 64 lowercase letters, digits, '.', '_' or '-'", id)
+	}
+	return nil
+}
+
+// DefaultID derives a stable ID for an endpoint without an explicit one:
+// a slug of its name, or a short hash of its type, method and URL when unnamed.
+func DefaultID(ep *Endpoint) string {
+	if slug := strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(ep.Name), "-"), "-"); slug != "" {
+		return strings.TrimRight(slug[:min(len(slug), 64)], "-")
+	}
+
+	sum := sha256.Sum256([]byte(strings.ToUpper(ep.Type) + " " + strings.ToUpper(ep.Method) + " " + ep.URL))
+	return strings.ToLower(ep.Type) + "-" + hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,80 @@
+package common
+
+import (
+	"strings"
+	"time"
+)
+
+// Flap values of a Transition.
+const (
+	FlapStart   = "start"   // the endpoint started flapping
+	FlapOngoing = "ongoing" // a transition while flapping, not worth notifying
+	FlapStop    = "stop"    // the endpoint is stable again
+)
+
+// Transition is a change of an endpoint's status between two consecutive results.
+// From is empty for the first result seen for an endpoint.
+type Transition struct {
+	EndpointID string    `json:"endpoint_id"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	At         time.Time `json:"at"`
+	Result     Result    `json:"result"`
+	// Incident is the incident opened or closed by this transition, if any
+	Incident *Incident `json:"incident,omitempty"`
+	// Flap is set while the endpoint is flapping. A FlapStop transition goes from
+	// the status notified when flapping started to the current one.
+	Flap string `json:"flap,omitempty"`
+}
+
+// Suppressed reports whether the transition happened while flapping and should not be notified.
+func (t *Transition) Suppressed() bool {
+	return t.Flap == FlapOngoing
+}
+
+// Incident is a period during which an endpoint was failing (down or unreachable).
+type Incident struct {
+	ID         int64     `json:"id"`
+	EndpointID string    `json:"endpoint_id"`
+	Status     string    `json:"status"` // status that opened the incident
+	Reason     string    `json:"reason"` // first failure reason
+	StartedAt  time.Time `json:"started_at"`
+	EndedAt    time.Time `json:"ended_at,omitzero"` // zero while the incident is open
+	// AcknowledgedAt is set once someone took the incident, stopping its escalation
+	AcknowledgedAt time.Time `json:"acknowledged_at,omitzero"`
+	AcknowledgedBy string    `json:"acknowledged_by,omitempty"`
+}
+
+// Acknowledged reports whether someone took the incident.
+func (i Incident) Acknowledged() bool {
+	return !i.AcknowledgedAt.IsZero()
+}
+
+// Open reports whether the incident is still ongoing.
+func (i Incident) Open() bool {
+	return i.EndedAt.IsZero()
+}
+
+// Duration returns how long the incident lasted, or has lasted so far as of now.
+func (i Incident) Duration(now time.Time) time.Duration {
+	if !i.Open() {
+		return i.EndedAt.Sub(i.StartedAt)
+	}
+	return now.Sub(i.StartedAt)
+}
+
+// IsFailing reports whether status counts as an outage.
+func IsFailing(status string) bool {
+	return status == StatusDown || status == StatusUnreachable
+}
+
+// FailureReason summarises why a result failed: its error, else its messages, else its status.
+func FailureReason(r Result) string {
+	if r.Error != "" {
+		return r.Error
+	}
+	if len(r.Messages) > 0 {
+		return strings.Join(r.Messages, ", ")
+	}
+	return r.Status
+}
@@ -0,0 +1,162 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JSONAssertion.Op
+const (
+	OpEquals    = "equals"
+	OpNotEquals = "not_equals"
+	OpExists    = "exists"
+	OpNotExists = "not_exists"
+	OpLt        = "lt"
+	OpLte       = "lte"
+	OpGt        = "gt"
+	OpGte       = "gte"
+	OpContains  = "contains"
+	OpMatches   = "matches"
+)
+
+var ValidOps = map[string]bool{
+	OpEquals:    true,
+	OpNotEquals: true,
+	OpExists:    true,
+	OpNotExists: true,
+	OpLt:        true,
+	OpLte:       true,
+	OpGt:        true,
+	OpGte:       true,
+	OpContains:  true,
+	OpMatches:   true,
+}
+
+// JSONAssertion checks a single field of a JSON response body.
+//
+// Path uses dot notation with optional array indexes, e.g. "status",
+// "$.data.items[0].id" or "data.items.0.id".
+type JSONAssertion struct {
+	Path  string `mapstructure:"path" json:"path" yaml:"path"`
+	Op    string `mapstructure:"op" json:"op" yaml:"op"`
+	Value any    `mapstructure:"value" json:"value,omitempty" yaml:"value,omitempty"`
+	// Pattern is Value compiled once at config load when Op is matches.
+	Pattern *regexp.Regexp `mapstructure:"-" json:"-" yaml:"-"`
+}
+
+func (a JSONAssertion) String() string {
+	if a.Op == OpExists || a.Op == OpNotExists {
+		return fmt.Sprintf("%s %s", a.Path, a.Op)
+	}
+	return fmt.Sprintf("%s %s %v", a.Path, a.Op, a.Value)
+}
+
+// ValidateJSONAssertion normalizes and validates a, compiling its pattern if needed.
+// It returns nil if valid, or an error otherwise.
+func ValidateJSONAssertion(a *JSONAssertion) error {
+	if _, err := ParseJSONPath(a.Path); err != nil {
+		return err
+	}
+
+	a.Op = strings.ToLower(a.Op)
+	if a.Op == "" {
+		a.Op = OpEquals
+	}
+	if !ValidOps[a.Op] {
+		return fmt.Errorf("invalid operator: %q", a.Op)
+	}
+
+	switch a.Op {
+	case OpExists, OpNotExists:
+	case OpLt, OpLte, OpGt, OpGte:
+		if _, ok := ToFloat(a.Value); !ok {
+			return fmt.Errorf("operator %q requires a numeric value, got %v", a.Op, a.Value)
+		}
+	case OpMatches:
+		expr, ok := a.Value.(string)
+		if !ok {
+			return fmt.Errorf("operator %q requires a string value, got %v", a.Op, a.Value)
+		}
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", expr, err)
+		}
+		a.Pattern = pattern
+	default:
+		if a.Value == nil {
+			return fmt.Errorf("operator %q requires a value", a.Op)
+		}
+	}
+
+	return nil
+}
+
+// ParseJSONPath splits a path expression into object keys and array indexes.
+// Indexes are returned as ints, keys as strings.
+func ParseJSONPath(path string) ([]any, error) {
+	p := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if p == "" {
+		return nil, fmt.Errorf("invalid path: %q", path)
+	}
+
+	segments := make([]any, 0)
+	for part := range strings.SplitSeq(p, ".") {
+		key, rest, _ := strings.Cut(part, "[")
+		if key != "" {
+			if i, err := strconv.Atoi(key); err == nil {
+				segments = append(segments, i)
+			} else {
+				segments = append(segments, key)
+			}
+		} else if rest == "" {
+			return nil, fmt.Errorf("invalid path: %q: empty segment", path)
+		}
+
+		for rest != "" {
+			index, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("invalid path: %q: missing ]", path)
+			}
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid path: %q: invalid index %q", path, index)
+			}
+			segments = append(segments, i)
+
+			if after != "" && !strings.HasPrefix(after, "[") {
+				return nil, fmt.Errorf("invalid path: %q", path)
+			}
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	return segments, nil
+}
+
+// ToFloat converts numeric values (including numeric strings) to float64.
+func ToFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,126 @@
+package common
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+)
+
+// LabelEndpoint holds the endpoint ID in the labels of every alert, so routes can match it.
+const LabelEndpoint = "endpoint"
+
+// labelPattern is the Prometheus label name syntax.
+var labelPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ValidateLabels checks label names. LabelEndpoint is reserved.
+func ValidateLabels(labels map[string]string) error {
+	for name := range labels {
+		if !labelPattern.MatchString(name) {
+			return fmt.Errorf("invalid label name %q: use letters, digits and '_', not starting with a digit", name)
+		}
+		if name == LabelEndpoint {
+			return fmt.Errorf("label %q is reserved", name)
+		}
+	}
+	return nil
+}
+
+// MergeLabels returns the global labels overridden by the endpoint's own.
+func MergeLabels(globals, own map[string]string) map[string]string {
+	if len(globals) == 0 {
+		return own
+	}
+	merged := maps.Clone(globals)
+	maps.Copy(merged, own)
+	return merged
+}
+
+// Matcher operators
+const (
+	MatchEqual     = "="
+	MatchNotEqual  = "!="
+	MatchRegexp    = "=~"
+	MatchNotRegexp = "!~"
+)
+
+// Matcher tests a single label of an alert or endpoint, e.g. `team="payments"` or `env!~"dev|staging"`.
+// A missing label matches as the empty string.
+type Matcher struct {
+	Name  string
+	Op    string
+	Value string
+	re    *regexp.Regexp // anchored Value of regexp matchers
+}
+
+// ParseMatcher parses a matcher written as name, operator and value.
+// The value may be double-quoted.
+func ParseMatcher(s string) (Matcher, error) {
+	i := strings.IndexAny(s, "=!")
+	if i <= 0 {
+		return Matcher{}, fmt.Errorf("invalid matcher %q: expected name, operator (=, !=, =~, !~) and value", s)
+	}
+	m := Matcher{Name: strings.TrimSpace(s[:i])}
+
+	rest := s[i:]
+	for _, op := range []string{MatchRegexp, MatchNotRegexp, MatchNotEqual, MatchEqual} {
+		if strings.HasPrefix(rest, op) {
+			m.Op = op
+			break
+		}
+	}
+	if m.Op == "" {
+		return Matcher{}, fmt.Errorf("invalid matcher %q: unknown operator", s)
+	}
+	m.Value = strings.TrimSpace(rest[len(m.Op):])
+	if len(m.Value) >= 2 && strings.HasPrefix(m.Value, `"`) && strings.HasSuffix(m.Value, `"`) {
+		m.Value = m.Value[1 : len(m.Value)-1]
+	}
+
+	if m.Op == MatchRegexp || m.Op == MatchNotRegexp {
+		re, err := regexp.Compile("^(?:" + m.Value + ")$")
+		if err != nil {
+			return Matcher{}, fmt.Errorf("invalid matcher %q: %w", s, err)
+		}
+		m.re = re
+	}
+	return m, nil
+}
+
+// Matches reports whether labels satisfy m.
+func (m Matcher) Matches(labels map[string]string) bool {
+	value := labels[m.Name]
+	switch m.Op {
+	case MatchEqual:
+		return value == m.Value
+	case MatchNotEqual:
+		return value != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(value)
+	case MatchNotRegexp:
+		return !m.re.MatchString(value)
+	default:
+		return false
+	}
+}
+
+// ParseMatchers parses every matcher of matchers.
+func ParseMatchers(matchers []string) ([]Matcher, error) {
+	parsed := make([]Matcher, 0, len(matchers))
+	for _, s := range matchers {
+		m, err := ParseMatcher(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, m)
+	}
+	return parsed, nil
+}
+
+// EndpointLabels returns the labels ep is matched on: its own, and its ID as LabelEndpoint.
+func EndpointLabels(ep Endpoint) map[string]string {
+	labels := make(map[string]string, len(ep.Labels)+1)
+	maps.Copy(labels, ep.Labels)
+	labels[LabelEndpoint] = ep.ID
+	return labels
+}
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Scope selects endpoints by ID, by tag or by label. Tags are plain names meant
+// for selecting endpoints; labels are the key/value pairs also attached to alerts
+// and matched by notification routes, so windows and silences can select
+// endpoints the way routes do.
+type Scope struct {
+	Endpoints []string `mapstructure:"endpoints" json:"endpoints,omitempty" yaml:"endpoints,omitempty"` // endpoint IDs
+	Tags      []string `mapstructure:"tags" json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Matchers select the endpoints whose labels match all of them, e.g. `team="payments"`
+	Matchers []string `mapstructure:"matchers" json:"matchers,omitempty" yaml:"matchers,omitempty"`
+
+	matchers []Matcher // Matchers parsed by Compile
+}
+
+// Empty reports whether the scope selects nothing.
+func (s Scope) Empty() bool {
+	return len(s.Endpoints) == 0 && len(s.Tags) == 0 && len(s.Matchers) == 0
+}
+
+// Compile parses the label matchers of the scope. Matches only applies them once compiled.
+func (s *Scope) Compile() error {
+	matchers, err := ParseMatchers(s.Matchers)
+	if err != nil {
+		return err
+	}
+	s.matchers = matchers
+	return nil
+}
+
+// Matches reports whether ep is one of the endpoints, carries one of the tags,
+// or has labels matching all of the matchers.
+func (s Scope) Matches(ep Endpoint) bool {
+	if slices.Contains(s.Endpoints, ep.ID) {
+		return true
+	}
+	for _, tag := range ep.Tags {
+		if slices.Contains(s.Tags, tag) {
+			return true
+		}
+	}
+	if len(s.matchers) == 0 {
+		return false
+	}
+	labels := EndpointLabels(ep)
+	for _, m := range s.matchers {
+		if !m.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// Silence mutes the alerts of the endpoints in its scope between StartsAt and
+// EndsAt. Silences are created at runtime, unlike configured maintenance windows.
+type Silence struct {
+	ID int64 `json:"id"`
+	Scope
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Active reports whether the silence applies at t.
+func (s Silence) Active(t time.Time) bool {
+	return !t.Before(s.StartsAt) && t.Before(s.EndsAt)
+}
+
+// ValidateSilence checks that s selects endpoints and ends after it starts.
+func ValidateSilence(s Silence) error {
+	if s.Empty() {
+		return fmt.Errorf("a silence needs at least one endpoint, tag or matcher")
+	}
+	if err := s.Compile(); err != nil {
+		return err
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("a silence must end after it starts")
+	}
+	return nil
+}
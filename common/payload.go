@@ -0,0 +1,232 @@
+package common
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+	"time"
+)
+
+// Default content types per body kind
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeForm = "application/x-www-form-urlencoded"
+	ContentTypeText = "text/plain; charset=utf-8"
+)
+
+// TemplateFuncs are the functions available in request body templates:
+//
+//	{{ now }}               current time, RFC3339 (or {{ now "2006-01-02" }})
+//	{{ unix }}              current Unix time in seconds
+//	{{ unixMilli }}         current Unix time in milliseconds
+//	{{ uuid }}              random UUID (v4)
+//	{{ randInt 1 100 }}     random integer in [min, max]
+//	{{ env "API_USER" }}    environment variable
+var TemplateFuncs = template.FuncMap{
+	"now": func(layout ...string) string {
+		if len(layout) > 0 {
+			return time.Now().Format(layout[0])
+		}
+		return time.Now().Format(time.RFC3339)
+	},
+	"unix":      func() int64 { return time.Now().Unix() },
+	"unixMilli": func() int64 { return time.Now().UnixMilli() },
+	"uuid":      newUUID,
+	"randInt": func(lo, hi int) (int, error) {
+		if hi < lo {
+			return 0, fmt.Errorf("randInt: max %d is lower than min %d", hi, lo)
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(hi-lo)+1))
+		if err != nil {
+			return 0, err
+		}
+		return lo + int(n.Int64()), nil
+	},
+	"env": os.Getenv,
+}
+
+// remoteTemplateFuncs are the TemplateFuncs available to endpoints defined through
+// the API: env is left out so API clients cannot read the environment of pulse.
+var remoteTemplateFuncs = func() template.FuncMap {
+	funcs := make(template.FuncMap, len(TemplateFuncs))
+	for name, fn := range TemplateFuncs {
+		if name != "env" {
+			funcs[name] = fn
+		}
+	}
+	return funcs
+}()
+
+// Payload is the compiled request body of an endpoint, rendered on every check.
+type Payload struct {
+	ContentType string
+	body        *template.Template
+	form        map[string]*template.Template
+}
+
+// NewPayload compiles the body settings of ep (body, body_file, body_json or form).
+// Relative body_file paths are resolved against baseDir.
+// It returns nil if the endpoint has no body.
+func NewPayload(ep *Endpoint, baseDir string) (*Payload, error) {
+	return newPayload(ep, baseDir, TemplateFuncs)
+}
+
+// ValidateRemotePayload checks the body settings of an endpoint defined through
+// the API rather than the config file. body_file and the env template function
+// are refused: they would let API clients send local files and secrets of the
+// pulse process to any URL.
+func ValidateRemotePayload(ep *Endpoint) error {
+	if ep.BodyFile != "" {
+		return fmt.Errorf("body_file is only allowed in the config file")
+	}
+	if _, err := newPayload(ep, "", remoteTemplateFuncs); err != nil {
+		return fmt.Errorf("%w (env is only available in the config file)", err)
+	}
+	return nil
+}
+
+func newPayload(ep *Endpoint, baseDir string, funcs template.FuncMap) (*Payload, error) {
+	set := 0
+	for _, present := range []bool{ep.Body != "", ep.BodyFile != "", ep.BodyJSON != nil, len(ep.Form) > 0} {
+		if present {
+			set++
+		}
+	}
+	if set == 0 {
+		return nil, nil
+	}
+	if set > 1 {
+		return nil, fmt.Errorf("only one of body, body_file, body_json and form can be set")
+	}
+
+	p := &Payload{ContentType: ep.ContentType}
+	text := ep.Body
+
+	switch {
+	case ep.Body != "":
+		if p.ContentType == "" {
+			p.ContentType = ContentTypeText
+		}
+
+	case ep.BodyFile != "":
+		if p.ContentType == "" {
+			p.ContentType = ContentTypeText
+		}
+		path := ep.BodyFile
+		if !filepath.IsAbs(path) && baseDir != "" {
+			path = filepath.Join(baseDir, path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading body_file: %w", err)
+		}
+		text = string(content)
+
+	case ep.BodyJSON != nil:
+		if p.ContentType == "" {
+			p.ContentType = ContentTypeJSON
+		}
+		raw, ok := ep.BodyJSON.(string)
+		if !ok {
+			// Mappings are turned into JSON text; note that the config loader
+			// lowercases mapping keys, use the string form for case-sensitive keys.
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(ep.BodyJSON); err != nil {
+				return nil, fmt.Errorf("encoding body_json: %w", err)
+			}
+			raw = string(bytes.TrimSpace(buf.Bytes()))
+		}
+		text = raw
+
+	case len(ep.Form) > 0:
+		if p.ContentType == "" {
+			p.ContentType = ContentTypeForm
+		}
+		p.form = make(map[string]*template.Template, len(ep.Form))
+		for k, v := range ep.Form {
+			tmpl, err := parseTemplate("form."+k, v, funcs)
+			if err != nil {
+				return nil, err
+			}
+			p.form[k] = tmpl
+		}
+		// Render once so that template errors surface at config load
+		if _, err := p.Render(); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+
+	tmpl, err := parseTemplate("body", text, funcs)
+	if err != nil {
+		return nil, err
+	}
+	p.body = tmpl
+
+	// Render once so that template errors surface at config load
+	rendered, err := p.Render()
+	if err != nil {
+		return nil, err
+	}
+	if ep.BodyJSON != nil && !json.Valid(rendered) {
+		return nil, fmt.Errorf("body_json does not render to valid JSON")
+	}
+
+	return p, nil
+}
+
+// Render executes the templates and returns the request body.
+func (p *Payload) Render() ([]byte, error) {
+	if p.form != nil {
+		// Sorted keys keep the encoded body stable between checks
+		keys := make([]string, 0, len(p.form))
+		for k := range p.form {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		values := url.Values{}
+		for _, k := range keys {
+			var buf bytes.Buffer
+			if err := p.form[k].Execute(&buf, nil); err != nil {
+				return nil, fmt.Errorf("rendering form field %q: %w", k, err)
+			}
+			values.Set(k, buf.String())
+		}
+		return []byte(values.Encode()), nil
+	}
+
+	var buf bytes.Buffer
+	if err := p.body.Execute(&buf, nil); err != nil {
+		return nil, fmt.Errorf("rendering body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func parseTemplate(name, text string, funcs template.FuncMap) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
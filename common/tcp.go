@@ -0,0 +1,47 @@
+package common
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TCPOptions holds the TCP specific settings of an endpoint.
+// When Send is empty and an expectation is set, the reply is treated as a banner
+// (e.g. SMTP, SSH or Redis greetings sent right after connecting).
+type TCPOptions struct {
+	Send        string `mapstructure:"send" json:"send,omitempty" yaml:"send,omitempty"`                         // payload written after connecting
+	Expect      string `mapstructure:"expect" json:"expect,omitempty" yaml:"expect,omitempty"`                   // substring the reply must contain
+	ExpectRegex string `mapstructure:"expect_regex" json:"expect_regex,omitempty" yaml:"expect_regex,omitempty"` // pattern the reply must match
+	// ExpectPattern is ExpectRegex compiled once at config load.
+	ExpectPattern *regexp.Regexp `mapstructure:"-" json:"-" yaml:"-"`
+}
+
+// HasExpectation reports whether a reply has to be read and asserted.
+func (o TCPOptions) HasExpectation() bool {
+	return o.Expect != "" || o.ExpectRegex != ""
+}
+
+// ParseHostPort extracts a "host:port" address from raw.
+// raw may be a bare address ("localhost:6379") or carry a scheme ("tcp://localhost:6379").
+func ParseHostPort(raw string) (string, error) {
+	addr := raw
+	if _, after, ok := strings.Cut(raw, "://"); ok {
+		addr = after
+	}
+	addr = strings.TrimSuffix(addr, "/")
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %w", raw, err)
+	}
+	if host == "" {
+		return "", fmt.Errorf("invalid address %q: missing host", raw)
+	}
+	if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+		return "", fmt.Errorf("invalid address %q: invalid port %q", raw, port)
+	}
+	return net.JoinHostPort(host, port), nil
+}
@@ -0,0 +1,51 @@
+package common
+
+import (
+	"fmt"
+	"time"
+)
+
+// Default certificate expiry thresholds, in days
+const (
+	DefaultTLSWarnDays     = 30
+	DefaultTLSCriticalDays = 7
+)
+
+// TLSOptions holds the certificate checks applied to HTTPS and TLS endpoints.
+// Certificates expiring within WarnDays mark the endpoint degraded, within
+// CriticalDays (or already expired) down.
+type TLSOptions struct {
+	WarnDays     int    `mapstructure:"warn_days" json:"warn_days,omitempty" yaml:"warn_days,omitempty"`
+	CriticalDays int    `mapstructure:"critical_days" json:"critical_days,omitempty" yaml:"critical_days,omitempty"`
+	ServerName   string `mapstructure:"server_name" json:"server_name,omitempty" yaml:"server_name,omitempty"` // SNI and name to verify, defaults to the host
+}
+
+// ValidateTLSOptions applies the default thresholds and validates o.
+// It returns nil if valid, or an error otherwise.
+func ValidateTLSOptions(o *TLSOptions) error {
+	if o.WarnDays < 0 || o.CriticalDays < 0 {
+		return fmt.Errorf("thresholds must be non-negative")
+	}
+	if o.WarnDays == 0 {
+		o.WarnDays = DefaultTLSWarnDays
+	}
+	if o.CriticalDays == 0 {
+		o.CriticalDays = DefaultTLSCriticalDays
+	}
+	if o.CriticalDays > o.WarnDays {
+		return fmt.Errorf("critical_days (%d) must not exceed warn_days (%d)", o.CriticalDays, o.WarnDays)
+	}
+	return nil
+}
+
+// TLSInfo describes the certificate presented by an endpoint.
+type TLSInfo struct {
+	Subject         string    `json:"subject" yaml:"subject"`
+	Issuer          string    `json:"issuer" yaml:"issuer"`
+	SANs            []string  `json:"sans,omitempty" yaml:"sans,omitempty"`
+	NotBefore       time.Time `json:"not_before" yaml:"not_before"`
+	NotAfter        time.Time `json:"not_after" yaml:"not_after"`
+	DaysUntilExpiry int       `json:"days_until_expiry" yaml:"days_until_expiry"` // negative once expired
+	ChainValid      bool      `json:"chain_valid" yaml:"chain_valid"`
+	ChainError      string    `json:"chain_error,omitempty" yaml:"chain_error,omitempty"`
+}
@@ -6,10 +6,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/mohamedbeat/pulse/api"
 	"github.com/mohamedbeat/pulse/common"
+	"github.com/mohamedbeat/pulse/maintenance"
+	"github.com/mohamedbeat/pulse/notifier"
+	"github.com/mohamedbeat/pulse/store"
 	"github.com/spf13/viper"
 )
 
@@ -18,21 +23,75 @@ type Globals struct {
 	Timeout  time.Duration `mapstructure:"timeout" json:"timeout" yaml:"timeout"`
 	Interval time.Duration `mapstructure:"interval" json:"interval" yaml:"interval"`
 	Type     string        `mapstructure:"type" json:"type" yaml:"type"` // http, tcp, dns...
+	// ShutdownTimeout bounds how long in-flight checks may take to finish on shutdown
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" json:"shutdown_timeout" yaml:"shutdown_timeout"`
+	// MaxConcurrency and MaxPerHost cap in-flight checks globally and per host (0 means unlimited)
+	MaxConcurrency int `mapstructure:"max_concurrency" json:"max_concurrency" yaml:"max_concurrency"`
+	MaxPerHost     int `mapstructure:"max_per_host" json:"max_per_host" yaml:"max_per_host"`
+	// Jitter is the upper bound of the random delay added to every interval
+	Jitter time.Duration `mapstructure:"jitter" json:"jitter" yaml:"jitter"`
+	// Alert holds the alert rules of endpoints that do not set their own
+	Alert common.AlertRules `mapstructure:"alert" json:"alert" yaml:"alert"`
+	// Labels are added to every endpoint, which can override them
+	Labels map[string]string `mapstructure:"labels" json:"labels,omitempty" yaml:"labels,omitempty"`
 }
 
+// DefaultShutdownTimeout is used when globals.shutdown_timeout is not set.
+const DefaultShutdownTimeout = 5 * time.Second
+
 type Config struct {
 	Globals   Globals
 	Endpoints []common.Endpoint `mapstructure:"endpoints"`
+	// Retention controls how long raw results and their rollups are stored
+	Retention store.RetentionPolicy `mapstructure:"retention"`
+	// Notifications configures how alerts are delivered
+	Notifications Notifications `mapstructure:"notifications"`
+	// Maintenance windows, during which results are flagged and never alerted on
+	Maintenance []maintenance.Window `mapstructure:"maintenance"`
+	// API configures the embedded HTTP server
+	API api.Config `mapstructure:"api"`
+}
+
+// Notifications holds the dispatcher settings and the enabled notifiers.
+type Notifications struct {
+	notifier.DispatcherOptions `mapstructure:",squash"`
+	// Console logs every alert
+	Console  bool                     `mapstructure:"console" json:"console" yaml:"console"`
+	Webhooks []notifier.WebhookConfig `mapstructure:"webhooks" json:"webhooks,omitempty" yaml:"webhooks,omitempty"`
+	Emails   []notifier.EmailConfig   `mapstructure:"emails" json:"emails,omitempty" yaml:"emails,omitempty"`
+	Slack    []notifier.SlackConfig   `mapstructure:"slack" json:"slack,omitempty" yaml:"slack,omitempty"`
+	Discord  []notifier.DiscordConfig `mapstructure:"discord" json:"discord,omitempty" yaml:"discord,omitempty"`
+	Teams    []notifier.TeamsConfig   `mapstructure:"teams" json:"teams,omitempty" yaml:"teams,omitempty"`
+	// PagerDuty and Opsgenie page on failures and resolve on recovery, one alert per endpoint
+	PagerDuty []notifier.PagerDutyConfig `mapstructure:"pagerduty" json:"pagerduty,omitempty" yaml:"pagerduty,omitempty"`
+	Opsgenie  []notifier.OpsgenieConfig  `mapstructure:"opsgenie" json:"opsgenie,omitempty" yaml:"opsgenie,omitempty"`
+	// Route picks the notifiers of an alert from its labels, every notifier gets it when unset
+	Route *notifier.Route `mapstructure:"route" json:"route,omitempty" yaml:"route,omitempty"`
+	// Escalations are the policies endpoints refer to by name
+	Escalations []notifier.EscalationPolicy `mapstructure:"escalations" json:"escalations,omitempty" yaml:"escalations,omitempty"`
 }
 type Env struct {
-	Dbuser string
-	Dbpass string
-	Dbname string
-	Dbport int
-	Dbhost string
+	Dbdriver string // sqlite (default)
+	Dbuser   string
+	Dbpass   string
+	Dbname   string
+	Dbport   int
+	Dbhost   string
 }
 
 func (e *Env) validateEnvs() error {
+	if e.Dbdriver == "" {
+		e.Dbdriver = store.DriverSQLite
+	}
+
+	// SQLite only needs the database file path
+	if e.Dbdriver == store.DriverSQLite {
+		if e.Dbname == "" {
+			return errors.New("db name must not be empty (path to the sqlite database file)")
+		}
+		return nil
+	}
+
 	if e.Dbuser == "" {
 		return errors.New("db user must not be empty")
 	}
@@ -188,6 +247,24 @@ func validateGlobals(cfg *Config) error {
 	if cfg.Globals.Timeout < 0 {
 		return fmt.Errorf("invalid provided timeout in globals: must be non-negative")
 	}
+	if cfg.Globals.ShutdownTimeout < 0 {
+		return fmt.Errorf("invalid provided shutdown_timeout in globals: must be non-negative")
+	}
+	if cfg.Globals.MaxConcurrency < 0 {
+		return fmt.Errorf("invalid provided max_concurrency in globals: must be non-negative")
+	}
+	if cfg.Globals.MaxPerHost < 0 {
+		return fmt.Errorf("invalid provided max_per_host in globals: must be non-negative")
+	}
+	if cfg.Globals.Jitter < 0 {
+		return fmt.Errorf("invalid provided jitter in globals: must be non-negative")
+	}
+	if err := common.ValidateLabels(cfg.Globals.Labels); err != nil {
+		return fmt.Errorf("invalid provided labels in globals: %w", err)
+	}
+	if cfg.Globals.ShutdownTimeout == 0 {
+		cfg.Globals.ShutdownTimeout = DefaultShutdownTimeout
+	}
 
 	return nil
 }
@@ -195,68 +272,224 @@ func validateGlobals(cfg *Config) error {
 // applyDefaultsToEndpoints applies global defaults to endpoints that don't have values set.
 func applyDefaultsToEndpoints(cfg *Config) {
 	for i := range cfg.Endpoints {
-		ep := &cfg.Endpoints[i]
-
-		if ep.Type == "" {
-			ep.Type = cfg.Globals.Type
-		}
-		if ep.Method == "" {
-			ep.Method = cfg.Globals.Method
-		}
-		if ep.Interval <= 0 {
-			ep.Interval = cfg.Globals.Interval
-		}
-		if ep.Timeout <= 0 {
-			ep.Timeout = cfg.Globals.Timeout
-		}
-		// Initialize Headers map if nil (Viper may leave it nil if not present in config)
-		if ep.Headers == nil {
-			ep.Headers = make(map[string]string)
-		}
-		fmt.Println("ep.Retry ", ep.Retry)
+		applyDefaultsToEndpoint(cfg.Globals, &cfg.Endpoints[i])
+	}
+}
 
-		// setting endpoint Retry counter state
-		ep.RetryCounter = ep.Retry
+// applyDefaultsToEndpoint applies global defaults to the values ep doesn't set.
+func applyDefaultsToEndpoint(globals Globals, ep *common.Endpoint) {
+	if ep.Type == "" {
+		ep.Type = globals.Type
+	}
+	if ep.Method == "" {
+		ep.Method = globals.Method
+	}
+	if ep.Interval <= 0 {
+		ep.Interval = globals.Interval
 	}
+	if ep.Timeout <= 0 {
+		ep.Timeout = globals.Timeout
+	}
+	// Initialize Headers map if nil (Viper may leave it nil if not present in config)
+	if ep.Headers == nil {
+		ep.Headers = make(map[string]string)
+	}
+	// Retry policy defaults
+	if ep.RetryBackoff <= 0 {
+		ep.RetryBackoff = DefaultRetryBackoff
+	}
+	if ep.RetryMaxBackoff <= 0 {
+		ep.RetryMaxBackoff = DefaultRetryMaxBackoff
+	}
+	if ep.RetryMultiplier <= 0 {
+		ep.RetryMultiplier = DefaultRetryMultiplier
+	}
+	// By default retries must settle before the next scheduled check
+	if ep.RetryMaxTime <= 0 {
+		ep.RetryMaxTime = ep.Interval
+	}
+	if ep.Alert == (common.AlertRules{}) {
+		ep.Alert = globals.Alert
+	}
+	ep.Labels = common.MergeLabels(globals.Labels, ep.Labels)
 }
 
 // validateEndpoints validates all endpoint configurations.
 func validateEndpoints(cfg *Config) error {
+	ids := make(map[string]int, len(cfg.Endpoints))
 	for i := range cfg.Endpoints {
 		ep := &cfg.Endpoints[i]
+		if err := validateEndpoint(ep, fmt.Sprintf("endpoint %d", i)); err != nil {
+			return err
+		}
+		// IDs must be unique since results and history are keyed by them
+		if j, ok := ids[ep.ID]; ok {
+			return fmt.Errorf("invalid provided id for endpoint %d: %q is already used by endpoint %d, set a unique id or name", i, ep.ID, j)
+		}
+		ids[ep.ID] = i
+	}
+
+	return nil
+}
 
-		// Validate endpoint type
-		if err := common.ValidateType(ep); err != nil {
-			return fmt.Errorf("invalid provided type for endpoint %d: %w", i, err)
+// validateEndpoint validates an endpoint with its defaults applied, and compiles its
+// patterns and request body. name identifies it in errors, e.g. "endpoint 2".
+func validateEndpoint(ep *common.Endpoint, name string) error {
+	// Validate endpoint ID
+	if ep.ID != "" {
+		if err := common.ValidateID(ep.ID); err != nil {
+			return fmt.Errorf("invalid provided id for %s: %w", name, err)
 		}
+	} else {
+		ep.ID = common.DefaultID(ep)
+	}
+
+	// Validate endpoint type
+	if err := common.ValidateType(ep); err != nil {
+		return fmt.Errorf("invalid provided type for %s: %w", name, err)
+	}
 
-		// Validate HTTP-specific fields
-		if ep.Type == common.HTTPType {
-			if err := common.ValidateMethod(ep.Method); err != nil {
-				return fmt.Errorf("invalid provided method for endpoint %d: %w", i, err)
+	// Validate HTTP-specific fields
+	if ep.Type == common.HTTPType {
+		if err := common.ValidateMethod(ep.Method); err != nil {
+			return fmt.Errorf("invalid provided method for %s: %w", name, err)
+		}
+		// validate URL
+		if ep.URL == "" {
+			return fmt.Errorf("invalid provided URL for %s: URL is required", name)
+		}
+		// compile body regex once instead of on every check
+		if ep.BodyRegex != "" {
+			pattern, err := regexp.Compile(ep.BodyRegex)
+			if err != nil {
+				return fmt.Errorf("invalid provided body_regex for %s: %w", name, err)
+			}
+			ep.BodyPattern = pattern
+		}
+		// compile request body templates
+		payload, err := common.NewPayload(ep, filepath.Dir(viper.ConfigFileUsed()))
+		if err != nil {
+			return fmt.Errorf("invalid provided request body for %s: %w", name, err)
+		}
+		ep.Payload = payload
+		for j := range ep.JSONAssertions {
+			if err := common.ValidateJSONAssertion(&ep.JSONAssertions[j]); err != nil {
+				return fmt.Errorf("invalid provided json assertion %d for %s: %w", j, name, err)
 			}
-			// validate URL
-			if ep.URL == "" {
-				return fmt.Errorf("invalid provided URL for endpoint %d: URL is required", i)
+		}
+	}
+
+	// Validate TCP-specific fields
+	if ep.Type == common.TCPType {
+		if _, err := common.ParseHostPort(ep.URL); err != nil {
+			return fmt.Errorf("invalid provided address for %s: %w", name, err)
+		}
+		if ep.TCP.ExpectRegex != "" {
+			pattern, err := regexp.Compile(ep.TCP.ExpectRegex)
+			if err != nil {
+				return fmt.Errorf("invalid provided tcp.expect_regex for %s: %w", name, err)
 			}
+			ep.TCP.ExpectPattern = pattern
 		}
+	}
 
-		// Validate interval
-		if ep.Interval == 0 {
-			return fmt.Errorf("invalid provided interval for endpoint %d: must be greater than 0", i)
+	// Validate DNS-specific fields
+	if ep.Type == common.DNSType {
+		if ep.URL == "" {
+			return fmt.Errorf("invalid provided URL for %s: name to resolve is required", name)
+		}
+		if err := common.ValidateDNSOptions(&ep.DNS); err != nil {
+			return fmt.Errorf("invalid provided dns options for %s: %w", name, err)
 		}
+	}
 
-		// Validate timeout
-		if ep.Timeout == 0 {
-			return fmt.Errorf("invalid provided timeout for endpoint %d: must be greater than 0", i)
+	// Validate TLS-specific fields
+	if ep.Type == common.TLSType {
+		if _, err := common.ParseHostPort(ep.URL); err != nil {
+			return fmt.Errorf("invalid provided address for %s: %w", name, err)
 		}
+	}
+	if ep.Type == common.TLSType || strings.HasPrefix(strings.ToLower(ep.URL), "https://") {
+		if err := common.ValidateTLSOptions(&ep.TLS); err != nil {
+			return fmt.Errorf("invalid provided tls options for %s: %w", name, err)
+		}
+	}
+
+	// Validate interval
+	if ep.Interval == 0 {
+		return fmt.Errorf("invalid provided interval for %s: must be greater than 0", name)
+	}
+
+	// Validate timeout
+	if ep.Timeout == 0 {
+		return fmt.Errorf("invalid provided timeout for %s: must be greater than 0", name)
+	}
+
+	// Validate retry
+	if ep.Retry < 0 {
+		return fmt.Errorf("invalid provided retry for %s: must be greater than 0", name)
+	}
+	if ep.RetryMultiplier < 1 {
+		return fmt.Errorf("invalid provided retry_multiplier for %s: must be at least 1", name)
+	}
+	if ep.RetryJitter < 0 || ep.RetryJitter > 1 {
+		return fmt.Errorf("invalid provided retry_jitter for %s: must be between 0 and 1", name)
+	}
+
+	// Validate alert rules
+	if err := common.ValidateAlertRules(&ep.Alert); err != nil {
+		return fmt.Errorf("invalid provided alert rules for %s: %w", name, err)
+	}
+
+	// Validate labels
+	if err := common.ValidateLabels(ep.Labels); err != nil {
+		return fmt.Errorf("invalid provided labels for %s: %w", name, err)
+	}
+
+	return nil
+}
 
-		// Validate retry
-		if ep.Retry < 0 {
-			return fmt.Errorf("invalid provided retry for endpoint %d: must be greater than 0", i)
+// validateMaintenance validates the maintenance windows and the endpoints they name.
+func validateMaintenance(cfg *Config) error {
+	ids := make(map[string]bool, len(cfg.Endpoints))
+	for _, ep := range cfg.Endpoints {
+		ids[ep.ID] = true
+	}
+
+	for i := range cfg.Maintenance {
+		w := &cfg.Maintenance[i]
+		if err := maintenance.ValidateWindow(w); err != nil {
+			return fmt.Errorf("invalid maintenance window %d: %w", i, err)
+		}
+		for _, id := range w.Endpoints {
+			if !ids[id] {
+				return fmt.Errorf("invalid maintenance window %d: unknown endpoint %q", i, id)
+			}
+		}
+	}
+	return nil
+}
+
+// validateEscalations validates the escalation policies and the endpoints using them.
+// Notifier names are checked once the notifiers are built.
+func validateEscalations(cfg *Config) error {
+	policies := make(map[string]bool, len(cfg.Notifications.Escalations))
+	for i := range cfg.Notifications.Escalations {
+		p := &cfg.Notifications.Escalations[i]
+		if err := notifier.ValidateEscalationPolicy(p); err != nil {
+			return fmt.Errorf("invalid escalation policy %d: %w", i, err)
 		}
+		if policies[p.Name] {
+			return fmt.Errorf("invalid escalation policy %d: duplicate name %q", i, p.Name)
+		}
+		policies[p.Name] = true
 	}
 
+	for i, ep := range cfg.Endpoints {
+		if ep.Escalation != "" && !policies[ep.Escalation] {
+			return fmt.Errorf("invalid provided escalation for endpoint %d: unknown policy %q", i, ep.Escalation)
+		}
+	}
 	return nil
 }
 
@@ -287,6 +520,32 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, err
 	}
 
+	// Validate maintenance windows
+	if err := validateMaintenance(cfg); err != nil {
+		return nil, err
+	}
+
+	// Validate retention
+	if err := store.ValidateRetention(&cfg.Retention); err != nil {
+		return nil, fmt.Errorf("invalid retention: %w", err)
+	}
+
+	// Validate notifications
+	if err := notifier.ValidateDispatcherOptions(&cfg.Notifications.DispatcherOptions); err != nil {
+		return nil, fmt.Errorf("invalid notifications: %w", err)
+	}
+	if err := validateEscalations(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Notifications.Route != nil {
+		if err := notifier.ValidateRoute(cfg.Notifications.Route); err != nil {
+			return nil, fmt.Errorf("invalid notifications: %w", err)
+		}
+	}
+	if err := api.ValidateConfig(&cfg.API); err != nil {
+		return nil, fmt.Errorf("invalid api: %w", err)
+	}
+
 	return cfg, nil
 }
 
@@ -295,6 +554,7 @@ func LoadEnv() (Env, error) {
 	viper.AutomaticEnv()
 	viper.SetConfigFile(".env")
 	viper.ReadInConfig() // This loads the .env file
+	env.Dbdriver = viper.GetString("DB_DRIVER")
 	env.Dbhost = viper.GetString("DB_HOST")
 	env.Dbname = viper.GetString("DB_NAME")
 	env.Dbuser = viper.GetString("DB_USER")
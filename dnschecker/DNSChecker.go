@@ -0,0 +1,386 @@
+package dnschecker
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// udpBufferSize is the EDNS0 payload size advertised to resolvers.
+const udpBufferSize = 4096
+
+var recordTypes = map[string]dnsmessage.Type{
+	common.RecordA:     dnsmessage.TypeA,
+	common.RecordAAAA:  dnsmessage.TypeAAAA,
+	common.RecordCNAME: dnsmessage.TypeCNAME,
+	common.RecordMX:    dnsmessage.TypeMX,
+	common.RecordTXT:   dnsmessage.TypeTXT,
+	common.RecordSRV:   dnsmessage.TypeSRV,
+}
+
+// Dialer defines the interface for opening connections to resolvers
+type Dialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+type DNSChecker struct {
+	dialer   Dialer
+	resolver string // used when the endpoint does not set one
+}
+
+// NewDNSChecker creates a new checker querying the system resolver by default
+func NewDNSChecker() *DNSChecker {
+	return &DNSChecker{
+		dialer:   &net.Dialer{},
+		resolver: systemResolver("/etc/resolv.conf"),
+	}
+}
+
+// NewDNSCheckerWithDialer allows injection of a custom dialer (for testing)
+func NewDNSCheckerWithDialer(dialer Dialer, resolver string) *DNSChecker {
+	return &DNSChecker{
+		dialer:   dialer,
+		resolver: resolver,
+	}
+}
+
+func (c *DNSChecker) Check(ctx context.Context, endpoint common.Endpoint) common.Result {
+	result := common.Result{
+		URL:      endpoint.URL,
+		Messages: make([]string, 0),
+	}
+
+	opts, err := c.options(endpoint.DNS)
+	if err != nil {
+		result.Status = common.StatusUnreachable
+		result.Error = err.Error()
+		result.Timestamp = time.Now()
+		return result
+	}
+
+	query, err := buildQuery(endpoint.URL, recordTypes[opts.Record])
+	if err != nil {
+		result.Status = common.StatusUnreachable
+		result.Error = err.Error()
+		result.Timestamp = time.Now()
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, endpoint.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := c.exchange(ctx, opts.Resolver, query)
+	elapsed := time.Since(start)
+
+	result.Elapsed = int(elapsed.Milliseconds())
+	result.Timestamp = time.Now()
+
+	if err != nil {
+		result.Status = common.StatusUnreachable
+		result.Error = err.Error()
+		return result
+	}
+
+	switch resp.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		result.Status = common.StatusDown
+		result.Error = fmt.Sprintf("%s: no such domain", endpoint.URL)
+		result.Messages = append(result.Messages, common.NXDomainMessage)
+		return result
+	case dnsmessage.RCodeServerFailure:
+		result.Status = common.StatusDown
+		result.Error = fmt.Sprintf("%s: resolver %s failed to answer", endpoint.URL, opts.Resolver)
+		result.Messages = append(result.Messages, common.ServFailMessage)
+		return result
+	default:
+		result.Status = common.StatusDown
+		result.Error = fmt.Sprintf("%s: unexpected response code %s", endpoint.URL, resp.RCode)
+		result.Messages = append(result.Messages, strings.TrimPrefix(resp.RCode.String(), "RCode"))
+		return result
+	}
+
+	result.Answers = answers(resp, opts.Record)
+	if len(result.Answers) == 0 {
+		result.Status = common.StatusDown
+		result.Error = fmt.Sprintf("%s: no %s records", endpoint.URL, opts.Record)
+		result.Messages = append(result.Messages, common.NoAnswerMessage)
+		return result
+	}
+
+	result.Status = common.StatusUp
+
+	if len(opts.Expect) > 0 && !matches(opts, result.Answers) {
+		result.Status = common.StatusDegraded
+		result.Messages = append(result.Messages, common.UnexpectedAnswerMessage)
+	}
+
+	if endpoint.MaxLatency > 0 && elapsed > endpoint.MaxLatency {
+		if result.Status == common.StatusUp {
+			result.Status = common.StatusDegraded
+		}
+		result.Messages = append(result.Messages, common.UnexpectedLatencyMessage)
+	}
+
+	return result
+}
+
+// options fills in the defaults for endpoints that did not go through config validation.
+func (c *DNSChecker) options(opts common.DNSOptions) (common.DNSOptions, error) {
+	opts.Record = strings.ToUpper(opts.Record)
+	if opts.Record == "" {
+		opts.Record = common.RecordA
+	}
+	if _, ok := recordTypes[opts.Record]; !ok {
+		return opts, fmt.Errorf("invalid record type: %q", opts.Record)
+	}
+
+	if opts.Match == "" {
+		opts.Match = common.MatchContains
+	}
+
+	if opts.Resolver == "" {
+		opts.Resolver = c.resolver
+	}
+	resolver, err := common.NormalizeResolver(opts.Resolver)
+	if err != nil {
+		return opts, err
+	}
+	opts.Resolver = resolver
+
+	return opts, nil
+}
+
+// buildQuery packs a recursive query for name, advertising EDNS0 support.
+func buildQuery(name string, qtype dnsmessage.Type) (*dnsmessage.Message, error) {
+	name = strings.TrimPrefix(name, "dns://")
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	qname, err := dnsmessage.NewName(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name %q: %w", name, err)
+	}
+
+	var opt dnsmessage.ResourceHeader
+	if err := opt.SetEDNS0(udpBufferSize, dnsmessage.RCodeSuccess, false); err != nil {
+		return nil, err
+	}
+
+	return &dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:               uint16(rand.UintN(1 << 16)),
+			RecursionDesired: true,
+		},
+		Questions: []dnsmessage.Question{{
+			Name:  qname,
+			Type:  qtype,
+			Class: dnsmessage.ClassINET,
+		}},
+		Additionals: []dnsmessage.Resource{{
+			Header: opt,
+			Body:   &dnsmessage.OPTResource{},
+		}},
+	}, nil
+}
+
+// exchange sends query over UDP and retries over TCP when the answer was truncated.
+func (c *DNSChecker) exchange(ctx context.Context, server string, query *dnsmessage.Message) (*dnsmessage.Message, error) {
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.roundTrip(ctx, "udp", server, query.ID, packed)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Truncated {
+		return c.roundTrip(ctx, "tcp", server, query.ID, packed)
+	}
+	return resp, nil
+}
+
+func (c *DNSChecker) roundTrip(ctx context.Context, network, server string, id uint16, packed []byte) (*dnsmessage.Message, error) {
+	conn, err := c.dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if network == "tcp" {
+		msg := make([]byte, 2+len(packed))
+		binary.BigEndian.PutUint16(msg, uint16(len(packed)))
+		copy(msg[2:], packed)
+		if _, err := conn.Write(msg); err != nil {
+			return nil, err
+		}
+
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return nil, err
+		}
+		return unpack(buf, id)
+	}
+
+	if _, err := conn.Write(packed); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := unpack(buf[:n], id)
+		// Ignore stray datagrams (e.g. late answers to a previous query) and keep waiting
+		if errors.Is(err, errIDMismatch) {
+			continue
+		}
+		return resp, err
+	}
+}
+
+var errIDMismatch = errors.New("response id mismatch")
+
+func unpack(buf []byte, id uint16) (*dnsmessage.Message, error) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(buf); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if msg.ID != id || !msg.Response {
+		return nil, errIDMismatch
+	}
+	return &msg, nil
+}
+
+// answers returns the textual form of the records of the queried type.
+func answers(msg *dnsmessage.Message, record string) []string {
+	qtype := recordTypes[record]
+	out := make([]string, 0, len(msg.Answers))
+
+	for _, rr := range msg.Answers {
+		if rr.Header.Type != qtype {
+			continue
+		}
+		switch body := rr.Body.(type) {
+		case *dnsmessage.AResource:
+			out = append(out, netip.AddrFrom4(body.A).String())
+		case *dnsmessage.AAAAResource:
+			out = append(out, netip.AddrFrom16(body.AAAA).String())
+		case *dnsmessage.CNAMEResource:
+			out = append(out, normalizeName(body.CNAME.String()))
+		case *dnsmessage.MXResource:
+			out = append(out, fmt.Sprintf("%d %s", body.Pref, normalizeName(body.MX.String())))
+		case *dnsmessage.TXTResource:
+			out = append(out, strings.Join(body.TXT, ""))
+		case *dnsmessage.SRVResource:
+			out = append(out, fmt.Sprintf("%d %d %d %s", body.Priority, body.Weight, body.Port, normalizeName(body.Target.String())))
+		}
+	}
+	return out
+}
+
+// matches evaluates the expected answers according to the match mode.
+func matches(opts common.DNSOptions, answers []string) bool {
+	if opts.Match == common.MatchRegex {
+		patterns := opts.ExpectPatterns
+		if patterns == nil {
+			for _, expr := range opts.Expect {
+				pattern, err := regexp.Compile(expr)
+				if err != nil {
+					return false
+				}
+				patterns = append(patterns, pattern)
+			}
+		}
+		for _, pattern := range patterns {
+			if !slices.ContainsFunc(answers, pattern.MatchString) {
+				return false
+			}
+		}
+		return true
+	}
+
+	got := make(map[string]bool, len(answers))
+	for _, a := range answers {
+		got[normalize(opts.Record, a)] = true
+	}
+	want := make(map[string]bool, len(opts.Expect))
+	for _, e := range opts.Expect {
+		want[normalize(opts.Record, e)] = true
+	}
+
+	for e := range want {
+		if !got[e] {
+			return false
+		}
+	}
+	if opts.Match == common.MatchExact {
+		return len(got) == len(want)
+	}
+	return true
+}
+
+// normalize brings an answer to a canonical form so that equivalent values compare equal.
+func normalize(record, value string) string {
+	value = strings.TrimSpace(value)
+	switch record {
+	case common.RecordA, common.RecordAAAA:
+		if addr, err := netip.ParseAddr(value); err == nil {
+			return addr.String()
+		}
+	case common.RecordCNAME, common.RecordMX, common.RecordSRV:
+		return normalizeName(value)
+	}
+	return value
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// systemResolver returns the first nameserver of the given resolv.conf.
+func systemResolver(path string) string {
+	fallback := net.JoinHostPort("127.0.0.1", "53")
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fallback
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			if resolver, err := common.NormalizeResolver(fields[1]); err == nil {
+				return resolver
+			}
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,257 @@
+package dnschecker
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func newServer(t *testing.T) *MockDNSServer {
+	t.Helper()
+
+	server, err := NewMockDNSServer()
+	if err != nil {
+		t.Fatalf("start mock dns server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	server.AddRecord("example.test", &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}})
+	server.AddRecord("example.test", &dnsmessage.AResource{A: [4]byte{10, 0, 0, 2}})
+	server.AddRecord("example.test", &dnsmessage.AAAAResource{AAAA: [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}})
+	server.AddRecord("example.test", &dnsmessage.MXResource{Pref: 10, MX: dnsmessage.MustNewName("mail.example.test.")})
+	server.AddRecord("example.test", &dnsmessage.TXTResource{TXT: []string{"v=spf1 ", "-all"}})
+	server.AddRecord("www.example.test", &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("example.test.")})
+	server.AddRecord("_sip._tcp.example.test", &dnsmessage.SRVResource{Priority: 10, Weight: 5, Port: 5060, Target: dnsmessage.MustNewName("sip.example.test.")})
+
+	return server
+}
+
+func TestDNSChecker_Check_RecordTypes(t *testing.T) {
+	server := newServer(t)
+
+	tests := []struct {
+		name   string
+		record string
+		expect []string
+	}{
+		{"example.test", common.RecordA, []string{"10.0.0.1", "10.0.0.2"}},
+		{"example.test", common.RecordAAAA, []string{"2001:db8::1"}},
+		{"www.example.test", common.RecordCNAME, []string{"example.test"}},
+		{"example.test", common.RecordMX, []string{"10 mail.example.test"}},
+		{"example.test", common.RecordTXT, []string{"v=spf1 -all"}},
+		{"_sip._tcp.example.test", common.RecordSRV, []string{"10 5 5060 sip.example.test"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.record, func(t *testing.T) {
+			checker := NewDNSChecker()
+			result := checker.Check(context.Background(), common.Endpoint{
+				URL:     tc.name,
+				Timeout: time.Second,
+				DNS: common.DNSOptions{
+					Record:   tc.record,
+					Resolver: server.Addr,
+					Expect:   tc.expect,
+					Match:    common.MatchExact,
+				},
+			})
+
+			if result.Status != common.StatusUp {
+				t.Errorf("Expected status Up, got %s (error: %s, messages: %v)", result.Status, result.Error, result.Messages)
+			}
+			if !slices.Equal(result.Answers, tc.expect) {
+				t.Errorf("Expected answers %v, got %v", tc.expect, result.Answers)
+			}
+		})
+	}
+}
+
+func TestDNSChecker_Check_MatchModes(t *testing.T) {
+	server := newServer(t)
+
+	tests := []struct {
+		name           string
+		match          string
+		expect         []string
+		expectedStatus string
+	}{
+		{"exact match", common.MatchExact, []string{"10.0.0.2", "10.0.0.1"}, common.StatusUp},
+		{"exact missing answer", common.MatchExact, []string{"10.0.0.1"}, common.StatusDegraded},
+		{"contains match", common.MatchContains, []string{"10.0.0.1"}, common.StatusUp},
+		{"contains mismatch", common.MatchContains, []string{"10.0.0.3"}, common.StatusDegraded},
+		{"regex match", common.MatchRegex, []string{`^10\.0\.0\.\d+$`}, common.StatusUp},
+		{"regex mismatch", common.MatchRegex, []string{`^192\.168\.`}, common.StatusDegraded},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := common.DNSOptions{
+				Resolver: server.Addr,
+				Expect:   tc.expect,
+				Match:    tc.match,
+			}
+			if err := common.ValidateDNSOptions(&opts); err != nil {
+				t.Fatalf("validate options: %v", err)
+			}
+
+			checker := NewDNSChecker()
+			result := checker.Check(context.Background(), common.Endpoint{
+				URL:     "example.test",
+				Timeout: time.Second,
+				DNS:     opts,
+			})
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %s, got %s (error: %s)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			found := slices.Contains(result.Messages, common.UnexpectedAnswerMessage)
+			if found != (tc.expectedStatus == common.StatusDegraded) {
+				t.Errorf("Unexpected messages: %v", result.Messages)
+			}
+		})
+	}
+}
+
+func TestDNSChecker_Check_ResponseCodes(t *testing.T) {
+	server := newServer(t)
+	server.SetRCode("broken.example.test", dnsmessage.RCodeServerFailure)
+	server.SetRCode("refused.example.test", dnsmessage.RCodeRefused)
+
+	tests := []struct {
+		name    string
+		message string
+	}{
+		{"missing.example.test", common.NXDomainMessage},
+		{"broken.example.test", common.ServFailMessage},
+		{"refused.example.test", "Refused"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.message, func(t *testing.T) {
+			checker := NewDNSChecker()
+			result := checker.Check(context.Background(), common.Endpoint{
+				URL:     tc.name,
+				Timeout: time.Second,
+				DNS:     common.DNSOptions{Resolver: server.Addr},
+			})
+
+			if result.Status != common.StatusDown {
+				t.Errorf("Expected status Down, got %s", result.Status)
+			}
+			if !slices.Contains(result.Messages, tc.message) {
+				t.Errorf("Expected message %q, got %v", tc.message, result.Messages)
+			}
+		})
+	}
+}
+
+func TestDNSChecker_Check_NoAnswer(t *testing.T) {
+	server := newServer(t)
+
+	checker := NewDNSChecker()
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     "www.example.test",
+		Timeout: time.Second,
+		DNS:     common.DNSOptions{Record: common.RecordMX, Resolver: server.Addr},
+	})
+
+	if result.Status != common.StatusDown {
+		t.Errorf("Expected status Down, got %s", result.Status)
+	}
+	if !slices.Contains(result.Messages, common.NoAnswerMessage) {
+		t.Errorf("Expected message %q, got %v", common.NoAnswerMessage, result.Messages)
+	}
+}
+
+func TestDNSChecker_Check_TruncatedFallsBackToTCP(t *testing.T) {
+	server := newServer(t)
+	server.SetTruncate(true)
+
+	checker := NewDNSChecker()
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     "example.test",
+		Timeout: time.Second,
+		DNS:     common.DNSOptions{Resolver: server.Addr, Expect: []string{"10.0.0.1"}},
+	})
+
+	if result.Status != common.StatusUp {
+		t.Errorf("Expected status Up, got %s (error: %s)", result.Status, result.Error)
+	}
+}
+
+func TestDNSChecker_Check_MaxLatency(t *testing.T) {
+	server := newServer(t)
+	server.SetDelay(100 * time.Millisecond)
+
+	checker := NewDNSChecker()
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:        "example.test",
+		Timeout:    time.Second,
+		MaxLatency: 20 * time.Millisecond,
+		DNS:        common.DNSOptions{Resolver: server.Addr},
+	})
+
+	if result.Status != common.StatusDegraded {
+		t.Errorf("Expected status Degraded due to latency, got %s", result.Status)
+	}
+	if !slices.Contains(result.Messages, common.UnexpectedLatencyMessage) {
+		t.Errorf("Expected message about unexpected latency")
+	}
+}
+
+func TestDNSChecker_Check_Timeout(t *testing.T) {
+	server := newServer(t)
+	server.SetDelay(500 * time.Millisecond)
+
+	checker := NewDNSChecker()
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     "example.test",
+		Timeout: 50 * time.Millisecond,
+		DNS:     common.DNSOptions{Resolver: server.Addr},
+	})
+
+	if result.Status != common.StatusUnreachable {
+		t.Errorf("Expected status Unreachable, got %s", result.Status)
+	}
+	if !strings.Contains(result.Error, "timeout") {
+		t.Errorf("Expected timeout error, got: %s", result.Error)
+	}
+}
+
+func TestDNSChecker_Check_DefaultResolver(t *testing.T) {
+	server := newServer(t)
+
+	checker := NewDNSCheckerWithDialer(&net.Dialer{}, server.Addr)
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     "example.test",
+		Timeout: time.Second,
+	})
+
+	if result.Status != common.StatusUp {
+		t.Errorf("Expected status Up, got %s (error: %s)", result.Status, result.Error)
+	}
+}
+
+func TestSystemResolver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resolv.conf")
+	content := "# generated\nsearch example.test\nnameserver 192.0.2.53\nnameserver 192.0.2.54\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := systemResolver(path); got != "192.0.2.53:53" {
+		t.Errorf("Expected 192.0.2.53:53, got %s", got)
+	}
+	if got := systemResolver(filepath.Join(t.TempDir(), "missing")); got != "127.0.0.1:53" {
+		t.Errorf("Expected fallback 127.0.0.1:53, got %s", got)
+	}
+}
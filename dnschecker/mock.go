@@ -0,0 +1,217 @@
+package dnschecker
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// MockDNSServer is an in-process DNS server listening on loopback (UDP and TCP) for testing
+type MockDNSServer struct {
+	Addr string
+
+	mu       sync.Mutex
+	records  map[string][]dnsmessage.Resource
+	rcodes   map[string]dnsmessage.RCode
+	delay    time.Duration
+	truncate bool
+
+	udp net.PacketConn
+	tcp net.Listener
+}
+
+// NewMockDNSServer starts a server on a random loopback port
+func NewMockDNSServer() (*MockDNSServer, error) {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	tcp, err := net.Listen("tcp", udp.LocalAddr().String())
+	if err != nil {
+		udp.Close()
+		return nil, err
+	}
+
+	s := &MockDNSServer{
+		Addr:    udp.LocalAddr().String(),
+		records: make(map[string][]dnsmessage.Resource),
+		rcodes:  make(map[string]dnsmessage.RCode),
+		udp:     udp,
+		tcp:     tcp,
+	}
+	go s.serveUDP()
+	go s.serveTCP()
+	return s, nil
+}
+
+// Close stops the server
+func (s *MockDNSServer) Close() {
+	s.udp.Close()
+	s.tcp.Close()
+}
+
+// AddRecord registers an answer for name; the record type is taken from body
+func (s *MockDNSServer) AddRecord(name string, body dnsmessage.ResourceBody) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fqdn := canonical(name)
+	s.records[fqdn] = append(s.records[fqdn], dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{
+			Name:  dnsmessage.MustNewName(fqdn),
+			Type:  bodyType(body),
+			Class: dnsmessage.ClassINET,
+			TTL:   60,
+		},
+		Body: body,
+	})
+}
+
+// SetRCode makes the server answer every query for name with rcode
+func (s *MockDNSServer) SetRCode(name string, rcode dnsmessage.RCode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rcodes[canonical(name)] = rcode
+}
+
+// SetDelay delays every answer by d
+func (s *MockDNSServer) SetDelay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = d
+}
+
+// SetTruncate forces UDP answers to be truncated so clients retry over TCP
+func (s *MockDNSServer) SetTruncate(truncate bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.truncate = truncate
+}
+
+func (s *MockDNSServer) serveUDP() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		resp := s.answer(buf[:n], true)
+		if resp != nil {
+			s.udp.WriteTo(resp, addr)
+		}
+	}
+}
+
+func (s *MockDNSServer) serveTCP() {
+	for {
+		conn, err := s.tcp.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+
+			var length [2]byte
+			if _, err := io.ReadFull(conn, length[:]); err != nil {
+				return
+			}
+			buf := make([]byte, binary.BigEndian.Uint16(length[:]))
+			if _, err := io.ReadFull(conn, buf); err != nil {
+				return
+			}
+
+			resp := s.answer(buf, false)
+			if resp == nil {
+				return
+			}
+			out := make([]byte, 2+len(resp))
+			binary.BigEndian.PutUint16(out, uint16(len(resp)))
+			copy(out[2:], resp)
+			conn.Write(out)
+		}()
+	}
+}
+
+func (s *MockDNSServer) answer(query []byte, udp bool) []byte {
+	var req dnsmessage.Message
+	if err := req.Unpack(query); err != nil || len(req.Questions) == 0 {
+		return nil
+	}
+	q := req.Questions[0]
+	name := strings.ToLower(q.Name.String())
+
+	s.mu.Lock()
+	delay := s.delay
+	rcode, hasRCode := s.rcodes[name]
+	records, known := s.records[name]
+	truncate := s.truncate && udp
+	s.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
+	resp := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 req.ID,
+			Response:           true,
+			RecursionDesired:   req.RecursionDesired,
+			RecursionAvailable: true,
+			RCode:              dnsmessage.RCodeSuccess,
+		},
+		Questions: req.Questions,
+	}
+
+	switch {
+	case hasRCode:
+		resp.RCode = rcode
+	case !known:
+		resp.RCode = dnsmessage.RCodeNameError
+	case truncate:
+		resp.Truncated = true
+	default:
+		for _, rr := range records {
+			if rr.Header.Type == q.Type {
+				resp.Answers = append(resp.Answers, rr)
+			}
+		}
+	}
+
+	packed, err := resp.Pack()
+	if err != nil {
+		return nil
+	}
+	return packed
+}
+
+func bodyType(body dnsmessage.ResourceBody) dnsmessage.Type {
+	switch body.(type) {
+	case *dnsmessage.AResource:
+		return dnsmessage.TypeA
+	case *dnsmessage.AAAAResource:
+		return dnsmessage.TypeAAAA
+	case *dnsmessage.CNAMEResource:
+		return dnsmessage.TypeCNAME
+	case *dnsmessage.MXResource:
+		return dnsmessage.TypeMX
+	case *dnsmessage.TXTResource:
+		return dnsmessage.TypeTXT
+	case *dnsmessage.SRVResource:
+		return dnsmessage.TypeSRV
+	default:
+		return dnsmessage.TypeALL
+	}
+}
+
+func canonical(name string) string {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mohamedbeat/pulse/api"
+	"github.com/mohamedbeat/pulse/common"
+	"github.com/mohamedbeat/pulse/notifier"
+	"github.com/mohamedbeat/pulse/state"
+	"github.com/mohamedbeat/pulse/store"
+	"github.com/spf13/viper"
+)
+
+// endpointManager adds, changes, pauses and deletes endpoints on behalf of the
+// API. Definitions are validated like the config file, only the workers of the
+// endpoint changed are started or stopped, and every change is stored so it
+// outlives restarts (see applyEndpointChanges).
+type endpointManager struct {
+	config    *Config
+	scheduler *Scheduler
+	store     store.Store
+	tracker   *state.Tracker
+	escalator *notifier.Escalator
+
+	mu      sync.Mutex                      // serialises changes
+	changes map[string]store.EndpointChange // stored change of every endpoint changed so far
+}
+
+func newEndpointManager(cfg *Config, scheduler *Scheduler, st store.Store, tracker *state.Tracker, escalator *notifier.Escalator, changes []store.EndpointChange) *endpointManager {
+	m := &endpointManager{
+		config:    cfg,
+		scheduler: scheduler,
+		store:     st,
+		tracker:   tracker,
+		escalator: escalator,
+		changes:   make(map[string]store.EndpointChange, len(changes)),
+	}
+	for _, change := range changes {
+		m.changes[change.ID] = change
+	}
+	return m
+}
+
+// Add starts monitoring the endpoint defined by definition.
+func (m *endpointManager) Add(ctx context.Context, definition []byte) (common.Endpoint, error) {
+	ep, definition, err := decodeEndpoint(m.config, definition, "")
+	if err != nil {
+		return ep, fmt.Errorf("%w: %w", api.ErrInvalidEndpoint, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.scheduler.Endpoint(ep.ID); ok {
+		return ep, fmt.Errorf("%q: %w", ep.ID, api.ErrEndpointExists)
+	}
+	change := store.EndpointChange{ID: ep.ID, Definition: definition, UpdatedAt: time.Now()}
+	if err := m.save(ctx, change, &ep); err != nil {
+		return ep, err
+	}
+
+	m.tracker.SetRules(ep.ID, ep.Alert)
+	if err := m.scheduler.Add(ep); err != nil {
+		return ep, err
+	}
+	Info("endpoint_added", "endpoint", ep.ID, "url", ep.URL)
+	return ep, nil
+}
+
+// Update replaces the definition of an endpoint. It stays paused if it was.
+func (m *endpointManager) Update(ctx context.Context, id string, definition []byte) (common.Endpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.scheduler.Endpoint(id); !ok {
+		return common.Endpoint{}, fmt.Errorf("%w %q", api.ErrUnknownEndpoint, id)
+	}
+
+	ep, definition, err := decodeEndpoint(m.config, definition, id)
+	if err != nil {
+		return ep, fmt.Errorf("%w: %w", api.ErrInvalidEndpoint, err)
+	}
+	change := m.changes[id]
+	change.ID, change.Definition, change.UpdatedAt = id, definition, time.Now()
+	if err := m.save(ctx, change, &ep); err != nil {
+		return ep, err
+	}
+
+	m.tracker.SetRules(ep.ID, ep.Alert)
+	if err := m.scheduler.Update(ep); err != nil {
+		return ep, err
+	}
+	Info("endpoint_updated", "endpoint", ep.ID, "url", ep.URL)
+	return ep, nil
+}
+
+// Delete stops monitoring an endpoint. Its open incident is closed and its
+// alerts are no longer escalated; its history is kept.
+func (m *endpointManager) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.scheduler.Endpoint(id); !ok {
+		return fmt.Errorf("%w %q", api.ErrUnknownEndpoint, id)
+	}
+
+	change := store.EndpointChange{ID: id, Deleted: true, UpdatedAt: time.Now()}
+	if err := m.save(ctx, change, nil); err != nil {
+		return err
+	}
+
+	if err := m.scheduler.Remove(id); err != nil {
+		return err
+	}
+	m.escalator.Forget(id)
+	incident, err := m.tracker.Forget(ctx, id, change.UpdatedAt)
+	if err != nil {
+		Error("incident_update_failed", "endpoint", id, "error", err.Error())
+	}
+	if incident != nil {
+		Info("incident_closed", "endpoint", id, "incident", incident.ID, "reason", "endpoint deleted")
+	}
+	Info("endpoint_deleted", "endpoint", id)
+	return nil
+}
+
+// Pause stops the checks of an endpoint until Resume, across restarts too.
+func (m *endpointManager) Pause(ctx context.Context, id string) error {
+	return m.setPaused(ctx, id, true)
+}
+
+// Resume restarts the checks of a paused endpoint.
+func (m *endpointManager) Resume(ctx context.Context, id string) error {
+	return m.setPaused(ctx, id, false)
+}
+
+func (m *endpointManager) setPaused(ctx context.Context, id string, paused bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.scheduler.Endpoint(id); !ok {
+		return fmt.Errorf("%w %q", api.ErrUnknownEndpoint, id)
+	}
+	if m.scheduler.Paused(id) == paused {
+		return nil
+	}
+
+	change := m.changes[id]
+	change.ID, change.Paused, change.UpdatedAt = id, paused, time.Now()
+	if err := m.save(ctx, change, nil); err != nil {
+		return err
+	}
+
+	if paused {
+		Info("endpoint_paused", "endpoint", id)
+		return m.scheduler.Pause(id)
+	}
+	Info("endpoint_resumed", "endpoint", id)
+	return m.scheduler.Resume(id)
+}
+
+// save stores change, and the new definition of the endpoint if there is one.
+// m.mu must be held.
+func (m *endpointManager) save(ctx context.Context, change store.EndpointChange, ep *common.Endpoint) error {
+	if ep != nil {
+		if err := m.store.SaveEndpoints(ctx, []common.Endpoint{*ep}); err != nil {
+			return err
+		}
+	}
+	if err := m.store.SaveEndpointChange(ctx, change); err != nil {
+		return err
+	}
+	m.changes[change.ID] = change
+	return nil
+}
+
+// decodeEndpoint decodes and validates an endpoint definition given to the API,
+// a JSON object with the keys of an endpoint in the config file. The globals of
+// cfg apply as they do to configured endpoints. id, when set, is the ID of the
+// endpoint the definition replaces: it may be left out but not changed.
+// The definition is returned compacted, as it is stored.
+func decodeEndpoint(cfg *Config, definition []byte, id string) (common.Endpoint, []byte, error) {
+	var ep common.Endpoint
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, definition); err != nil {
+		return ep, nil, fmt.Errorf("decoding endpoint: %w", err)
+	}
+
+	v := viper.New()
+	v.SetConfigType("json")
+	if err := v.ReadConfig(bytes.NewReader(compact.Bytes())); err != nil {
+		return ep, nil, fmt.Errorf("decoding endpoint: %w", err)
+	}
+	if err := v.Unmarshal(&ep); err != nil {
+		return ep, nil, fmt.Errorf("decoding endpoint: %w", err)
+	}
+	if id != "" {
+		if ep.ID != "" && ep.ID != id {
+			return ep, nil, fmt.Errorf("invalid provided id for endpoint %q: ids cannot be changed, got %q", id, ep.ID)
+		}
+		ep.ID = id
+	}
+
+	name := "endpoint"
+	if ep.ID != "" {
+		name = fmt.Sprintf("endpoint %q", ep.ID)
+	}
+	// Checked before the globals apply: only what the client sent is untrusted
+	if err := common.ValidateRemotePayload(&ep); err != nil {
+		return ep, nil, fmt.Errorf("invalid provided body for %s: %w", name, err)
+	}
+	applyDefaultsToEndpoint(cfg.Globals, &ep)
+	if err := validateEndpoint(&ep, name); err != nil {
+		return ep, nil, err
+	}
+	if ep.Escalation != "" && !slices.ContainsFunc(cfg.Notifications.Escalations, func(p notifier.EscalationPolicy) bool {
+		return p.Name == ep.Escalation
+	}) {
+		return ep, nil, fmt.Errorf("invalid provided escalation for %s: unknown policy %q", name, ep.Escalation)
+	}
+	return ep, compact.Bytes(), nil
+}
+
+// applyEndpointChanges overlays the changes made through the API on the configured
+// endpoints. It returns the endpoints to monitor, added ones last, and the IDs of
+// the paused ones. An endpoint deleted through the API stays deleted even if it is
+// still in the config file, until it is added again through the API.
+func applyEndpointChanges(cfg *Config, changes []store.EndpointChange) ([]common.Endpoint, []string, error) {
+	endpoints := slices.Clone(cfg.Endpoints)
+	var paused []string
+	for _, change := range changes {
+		i := slices.IndexFunc(endpoints, func(ep common.Endpoint) bool { return ep.ID == change.ID })
+		switch {
+		case change.Deleted:
+			if i >= 0 {
+				endpoints = slices.Delete(endpoints, i, i+1)
+			}
+			continue
+		case change.Definition != nil:
+			ep, _, err := decodeEndpoint(cfg, change.Definition, change.ID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid endpoint %q changed through the api: %w", change.ID, err)
+			}
+			if i >= 0 {
+				endpoints[i] = ep
+			} else {
+				endpoints = append(endpoints, ep)
+			}
+		case i < 0:
+			// Paused, then removed from the config file
+			continue
+		}
+		if change.Paused {
+			paused = append(paused, change.ID)
+		}
+	}
+	return endpoints, paused, nil
+}
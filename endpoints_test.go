@@ -0,0 +1,134 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+	"github.com/mohamedbeat/pulse/notifier"
+	"github.com/mohamedbeat/pulse/store"
+)
+
+func testConfig(endpoints ...common.Endpoint) *Config {
+	cfg := &Config{
+		Globals: Globals{
+			Method:   "GET",
+			Type:     common.HTTPType,
+			Interval: time.Minute,
+			Timeout:  5 * time.Second,
+			Labels:   map[string]string{"env": "prod"},
+		},
+		Endpoints: endpoints,
+	}
+	cfg.Notifications.Escalations = []notifier.EscalationPolicy{{Name: "critical"}}
+	return cfg
+}
+
+func TestDecodeEndpoint(t *testing.T) {
+	cfg := testConfig()
+
+	ep, definition, err := decodeEndpoint(cfg, []byte(`{
+		"name": "Web",
+		"url": "http://web.local",
+		"interval": "30s",
+		"escalation": "critical"
+	}`), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ep.ID != "web" || ep.Interval != 30*time.Second || ep.Timeout != 5*time.Second || ep.Method != "GET" {
+		t.Errorf("Expected the definition with the globals applied, got %+v", ep)
+	}
+	if ep.Labels["env"] != "prod" {
+		t.Errorf("Expected the global labels, got %v", ep.Labels)
+	}
+	if want := `{"name":"Web","url":"http://web.local","interval":"30s","escalation":"critical"}`; string(definition) != want {
+		t.Errorf("Expected the compacted definition %s, got %s", want, definition)
+	}
+}
+
+func TestDecodeEndpoint_ID(t *testing.T) {
+	cfg := testConfig()
+
+	// The ID of the endpoint replaced is kept when left out
+	ep, _, err := decodeEndpoint(cfg, []byte(`{"url":"http://web.local/health"}`), "web")
+	if err != nil || ep.ID != "web" {
+		t.Errorf("Expected the id to be kept, got %q (%v)", ep.ID, err)
+	}
+	if _, _, err := decodeEndpoint(cfg, []byte(`{"id":"web","url":"http://web.local"}`), "web"); err != nil {
+		t.Errorf("Expected the same id to be accepted, got %v", err)
+	}
+	if _, _, err := decodeEndpoint(cfg, []byte(`{"id":"other","url":"http://web.local"}`), "web"); err == nil || !strings.Contains(err.Error(), "cannot be changed") {
+		t.Errorf("Expected changing the id to fail, got %v", err)
+	}
+}
+
+func TestDecodeEndpoint_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		want       string
+	}{
+		{"not json", `{"url":`, "decoding endpoint"},
+		{"no url", `{"name":"web"}`, "URL is required"},
+		{"bad id", `{"id":"Not An ID","url":"http://web.local"}`, "invalid provided id"},
+		{"unknown escalation", `{"id":"web","url":"http://web.local","escalation":"nope"}`, `unknown policy "nope"`},
+		{"body file", `{"url":"http://web.local","body_file":"/etc/shadow"}`, "body_file is only allowed in the config file"},
+		{"env in body", `{"url":"http://web.local","body":"{{ env \"SMTP_PASSWORD\" }}"}`, `function "env" not defined`},
+		{"env in form", `{"url":"http://web.local","form":{"token":"{{ env \"SMTP_PASSWORD\" }}"}}`, `function "env" not defined`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := decodeEndpoint(testConfig(), []byte(tc.definition), "")
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestApplyEndpointChanges(t *testing.T) {
+	cfg := testConfig(
+		common.Endpoint{ID: "api", URL: "http://api.local"},
+		common.Endpoint{ID: "db", URL: "http://db.local"},
+		common.Endpoint{ID: "cache", URL: "http://cache.local"},
+	)
+	changes := []store.EndpointChange{
+		{ID: "db", Deleted: true},
+		{ID: "api", Definition: []byte(`{"url":"http://api.local/v2"}`), Paused: true},
+		{ID: "web", Definition: []byte(`{"url":"http://web.local"}`)},
+		{ID: "cache", Paused: true},
+		{ID: "gone", Paused: true}, // paused, then removed from the config file
+	}
+
+	endpoints, paused, err := applyEndpointChanges(cfg, changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, len(endpoints))
+	for i, ep := range endpoints {
+		ids[i] = ep.ID
+	}
+	if want := []string{"api", "cache", "web"}; !slices.Equal(ids, want) {
+		t.Errorf("Expected endpoints %v, got %v", want, ids)
+	}
+	if endpoints[0].URL != "http://api.local/v2" || endpoints[2].Interval != time.Minute {
+		t.Errorf("Expected the stored definitions with the globals applied, got %+v", endpoints)
+	}
+	if want := []string{"api", "cache"}; !slices.Equal(paused, want) {
+		t.Errorf("Expected paused %v, got %v", want, paused)
+	}
+	if len(cfg.Endpoints) != 3 || cfg.Endpoints[0].URL != "http://api.local" {
+		t.Errorf("Expected the configured endpoints to be left alone, got %+v", cfg.Endpoints)
+	}
+}
+
+func TestApplyEndpointChanges_Invalid(t *testing.T) {
+	changes := []store.EndpointChange{{ID: "web", Definition: []byte(`{"url":"http://web.local","escalation":"nope"}`)}}
+	if _, _, err := applyEndpointChanges(testConfig(), changes); err == nil || !strings.Contains(err.Error(), `"web" changed through the api`) {
+		t.Errorf("Expected an invalid stored definition to fail, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mohamedbeat/pulse/notifier"
+	"github.com/mohamedbeat/pulse/store"
+)
+
+// escalationTick is how often escalations are checked and acknowledgements made
+// by other processes (the CLI) are picked up.
+const escalationTick = 10 * time.Second
+
+// runEscalations escalates and repeats the alerts of unacknowledged incidents
+// until ctx is cancelled.
+func runEscalations(ctx context.Context, st store.Store, e *notifier.Escalator) {
+	ticker := time.NewTicker(escalationTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		open, err := st.OpenIncidents(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				Error("acknowledgements_refresh_failed", "error", err.Error())
+			}
+		}
+		for _, incident := range open {
+			if incident.Acknowledged() && e.Acknowledge(incident.ID) {
+				Info("incident_acknowledged",
+					"endpoint", incident.EndpointID,
+					"incident", incident.ID,
+					"by", incident.AcknowledgedBy,
+				)
+			}
+		}
+		e.Tick(time.Now())
+	}
+}
+
+// runIncidentCommand implements "pulse incident list|ack" and returns the exit code.
+func runIncidentCommand(envs Env, args []string) int {
+	usage := func() int {
+		fmt.Fprintln(os.Stderr, "usage: pulse incident list")
+		fmt.Fprintln(os.Stderr, "       pulse incident ack ID [-by NAME]")
+		return 2
+	}
+	if len(args) == 0 {
+		return usage()
+	}
+
+	st, err := store.Open(envs.Dbdriver, envs.Dbname)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	switch args[0] {
+	case "list":
+		open, err := st.OpenIncidents(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tENDPOINT\tSTATUS\tSTARTED\tACKNOWLEDGED BY\tREASON")
+		for _, i := range open {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", i.ID, i.EndpointID, i.Status,
+				i.StartedAt.Format(time.RFC3339), i.AcknowledgedBy, i.Reason)
+		}
+		w.Flush()
+		return 0
+
+	case "ack":
+		if len(args) < 2 {
+			return usage()
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return usage()
+		}
+		fs := flag.NewFlagSet("incident ack", flag.ContinueOnError)
+		by := fs.String("by", os.Getenv("USER"), "who is handling the incident")
+		if err := fs.Parse(args[2:]); err != nil {
+			return 2
+		}
+
+		if err := st.AcknowledgeIncident(ctx, id, *by, time.Now()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("incident %d acknowledged\n", id)
+		return 0
+
+	default:
+		return usage()
+	}
+}
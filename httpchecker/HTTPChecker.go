@@ -1,13 +1,22 @@
 package httpchecker
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"github.com/mohamedbeat/pulse/common"
+	"io"
 	"net/http"
+	"regexp"
+	"strings"
 	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+	"github.com/mohamedbeat/pulse/tlschecker"
 )
 
+// maxBodySize bounds how much of the response body is read for assertions.
+const maxBodySize = 1 << 20 // 1 MiB
+
 // HTTPClient defines the interface for HTTP operations
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
@@ -58,13 +67,21 @@ func (h *HTTPChecker) Check(ctx context.Context, endpoint common.Endpoint) commo
 	ctx, cancel := context.WithTimeout(ctx, endpoint.Timeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, endpoint.Method, endpoint.URL, nil)
-
 	result := common.Result{
 		URL:      endpoint.URL,
 		Messages: make([]string, 0),
 	}
 
+	reqBody, contentType, err := requestBody(endpoint)
+	if err != nil {
+		result.Status = common.StatusUnreachable
+		result.Error = err.Error()
+		result.Timestamp = time.Now()
+
+		return result
+	}
+
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, endpoint.URL, reqBody)
 	if err != nil {
 		result.Status = common.StatusUnreachable
 		result.Error = err.Error()
@@ -73,6 +90,10 @@ func (h *HTTPChecker) Check(ctx context.Context, endpoint common.Endpoint) commo
 		return result
 	}
 
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
 	// Add custom headers
 	if len(endpoint.Headers) > 0 {
 		// Debug("Setting headers", "endpoint", endpoint.URL, "headers", endpoint.Headers)
@@ -91,6 +112,13 @@ func (h *HTTPChecker) Check(ctx context.Context, endpoint common.Endpoint) commo
 	if err != nil {
 		result.Status = common.StatusUnreachable
 		result.Error = err.Error()
+
+		// The server answered but its certificate was rejected: report why.
+		if info := tlschecker.FromVerificationError(err, result.Timestamp); info != nil {
+			result.Status = common.StatusDown
+			result.TLS = info
+			tlschecker.Evaluate(&result, endpoint.TLS)
+		}
 		return result
 	}
 	defer resp.Body.Close()
@@ -105,6 +133,12 @@ func (h *HTTPChecker) Check(ctx context.Context, endpoint common.Endpoint) commo
 	}
 	result.StatusCode = resp.StatusCode
 
+	// Check certificate expiry for HTTPS endpoints
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		result.TLS = tlschecker.Inspect(resp.TLS.PeerCertificates[0], nil, result.Timestamp)
+		tlschecker.Evaluate(&result, endpoint.TLS)
+	}
+
 	// Check if resp.StatusCode must match
 	if endpoint.MustMatchStatus && resp.StatusCode != endpoint.ExpectedStatus {
 		fmt.Println("unexpected status detected")
@@ -123,5 +157,71 @@ func (h *HTTPChecker) Check(ctx context.Context, endpoint common.Endpoint) commo
 		}
 		result.Messages = append(result.Messages, common.UnexpectedLatencyMessage)
 	}
+
+	// Check response body
+	messages := len(result.Messages)
+	if endpoint.BodyContains != "" || endpoint.BodyRegex != "" || len(endpoint.JSONAssertions) > 0 {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+		if err != nil {
+			result.Error = fmt.Sprintf("reading body: %v", err)
+		}
+
+		if endpoint.BodyContains != "" || endpoint.BodyRegex != "" {
+			if err != nil || !bodyMatches(endpoint, body) {
+				result.Messages = append(result.Messages, common.UnexpectedBodyMessage)
+			}
+		}
+
+		if len(endpoint.JSONAssertions) > 0 {
+			result.Messages = append(result.Messages, evaluateJSON(endpoint.JSONAssertions, body)...)
+		}
+
+		// A 2xx serving the wrong content (e.g. an error page) is not healthy.
+		if len(result.Messages) > messages && result.Status == common.StatusUp {
+			result.Status = common.StatusDegraded
+		}
+	}
 	return result
 }
+
+// requestBody renders the request body of endpoint, if any.
+func requestBody(endpoint common.Endpoint) (io.Reader, string, error) {
+	payload := endpoint.Payload
+	if payload == nil {
+		// Endpoint did not go through config validation, compile on the fly
+		var err error
+		if payload, err = common.NewPayload(&endpoint, ""); err != nil {
+			return nil, "", err
+		}
+		if payload == nil {
+			return nil, "", nil
+		}
+	}
+
+	rendered, err := payload.Render()
+	if err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(rendered), payload.ContentType, nil
+}
+
+// bodyMatches evaluates BodyContains and BodyRegex against body.
+func bodyMatches(endpoint common.Endpoint, body []byte) bool {
+	if endpoint.BodyContains != "" && !strings.Contains(string(body), endpoint.BodyContains) {
+		return false
+	}
+	if endpoint.BodyRegex != "" {
+		pattern := endpoint.BodyPattern
+		if pattern == nil {
+			// Endpoint did not go through config validation, compile on the fly
+			var err error
+			if pattern, err = regexp.Compile(endpoint.BodyRegex); err != nil {
+				return false
+			}
+		}
+		if !pattern.Match(body) {
+			return false
+		}
+	}
+	return true
+}
@@ -2,16 +2,23 @@ package httpchecker
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/mohamedbeat/pulse/common"
+	"github.com/mohamedbeat/pulse/tlschecker"
 )
 
 func TestHTTPChecker_Check_Success(t *testing.T) {
@@ -333,3 +340,461 @@ func TestHTTPChecker_Check_StatusClassification(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPChecker_Check_Body(t *testing.T) {
+	tests := []struct {
+		name           string
+		statusCode     int
+		body           string
+		bodyContains   string
+		bodyRegex      string
+		expectedStatus string
+		expectMessage  bool
+	}{
+		{"contains match", 200, `{"status":"ok","uptime":"10m"}`, `"status":"ok"`, "", common.StatusUp, false},
+		{"contains mismatch", 200, `<html>Something went wrong</html>`, `"status":"ok"`, "", common.StatusDegraded, true},
+		{"regex match", 200, `{"id":42}`, "", `"id":[0-9]+`, common.StatusUp, false},
+		{"regex mismatch", 200, `{"id":"abc"}`, "", `"id":[0-9]+`, common.StatusDegraded, true},
+		{"both match", 200, `{"status":"ok","id":42}`, `"status":"ok"`, `"id":[0-9]+`, common.StatusUp, false},
+		{"regex fails while contains matches", 200, `{"status":"ok"}`, `"status":"ok"`, `"id":[0-9]+`, common.StatusDegraded, true},
+		{"down stays down", 503, `maintenance`, `"status":"ok"`, "", common.StatusDown, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					return createMockResponse(tc.statusCode, tc.body, 0), nil
+				},
+			}
+
+			checker := NewHTTPCheckerWithClient(mockClient)
+			endpoint := common.Endpoint{
+				URL:          "http://example.com",
+				Method:       "GET",
+				Timeout:      5 * time.Second,
+				BodyContains: tc.bodyContains,
+				BodyRegex:    tc.bodyRegex,
+			}
+
+			result := checker.Check(context.Background(), endpoint)
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %s, got %s", tc.expectedStatus, result.Status)
+			}
+
+			found := slices.Contains(result.Messages, common.UnexpectedBodyMessage)
+			if found != tc.expectMessage {
+				t.Errorf("Expected UnexpectedBodyMessage=%v, got messages %v", tc.expectMessage, result.Messages)
+			}
+		})
+	}
+}
+
+func TestHTTPChecker_Check_BodyPattern(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return createMockResponse(http.StatusOK, `{"status":"ok"}`, 0), nil
+		},
+	}
+
+	checker := NewHTTPCheckerWithClient(mockClient)
+	endpoint := common.Endpoint{
+		URL:       "http://example.com",
+		Method:    "GET",
+		Timeout:   5 * time.Second,
+		BodyRegex: `"status":"ok"`,
+		// The precompiled pattern takes precedence over BodyRegex
+		BodyPattern: regexp.MustCompile(`"status":"down"`),
+	}
+
+	result := checker.Check(context.Background(), endpoint)
+
+	if result.Status != common.StatusDegraded {
+		t.Errorf("Expected status Degraded, got %s", result.Status)
+	}
+}
+
+func TestHTTPChecker_Check_BodyLimit(t *testing.T) {
+	// The marker sits past the read limit and must not be seen
+	body := strings.Repeat("a", maxBodySize) + "marker"
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return createMockResponse(http.StatusOK, body, 0), nil
+		},
+	}
+
+	checker := NewHTTPCheckerWithClient(mockClient)
+	endpoint := common.Endpoint{
+		URL:          "http://example.com",
+		Method:       "GET",
+		Timeout:      5 * time.Second,
+		BodyContains: "marker",
+	}
+
+	result := checker.Check(context.Background(), endpoint)
+
+	if result.Status != common.StatusDegraded {
+		t.Errorf("Expected status Degraded, got %s", result.Status)
+	}
+}
+
+func TestHTTPChecker_Check_JSONAssertions(t *testing.T) {
+	body := `{"status":"ok","uptime":"10m","checks":{"db":{"latency_ms":12,"healthy":true}},"replicas":[{"id":"a"},{"id":"b"}],"tags":["eu","prod"]}`
+
+	tests := []struct {
+		name      string
+		assertion common.JSONAssertion
+		pass      bool
+	}{
+		{"equals string", common.JSONAssertion{Path: "status", Op: common.OpEquals, Value: "ok"}, true},
+		{"equals string mismatch", common.JSONAssertion{Path: "status", Op: common.OpEquals, Value: "down"}, false},
+		{"default op is equals", common.JSONAssertion{Path: "$.status", Value: "ok"}, true},
+		{"not equals", common.JSONAssertion{Path: "status", Op: common.OpNotEquals, Value: "down"}, true},
+		{"equals number", common.JSONAssertion{Path: "checks.db.latency_ms", Op: common.OpEquals, Value: 12}, true},
+		{"equals bool", common.JSONAssertion{Path: "checks.db.healthy", Op: common.OpEquals, Value: true}, true},
+		{"exists", common.JSONAssertion{Path: "uptime", Op: common.OpExists}, true},
+		{"exists missing", common.JSONAssertion{Path: "version", Op: common.OpExists}, false},
+		{"not exists", common.JSONAssertion{Path: "error", Op: common.OpNotExists}, true},
+		{"lt", common.JSONAssertion{Path: "checks.db.latency_ms", Op: common.OpLt, Value: 50}, true},
+		{"lt fails", common.JSONAssertion{Path: "checks.db.latency_ms", Op: common.OpLt, Value: 10}, false},
+		{"gte", common.JSONAssertion{Path: "checks.db.latency_ms", Op: common.OpGte, Value: 12}, true},
+		{"gt on string fails", common.JSONAssertion{Path: "status", Op: common.OpGt, Value: 1}, false},
+		{"array index brackets", common.JSONAssertion{Path: "replicas[1].id", Op: common.OpEquals, Value: "b"}, true},
+		{"array index dots", common.JSONAssertion{Path: "replicas.0.id", Op: common.OpEquals, Value: "a"}, true},
+		{"array index out of range", common.JSONAssertion{Path: "replicas[5].id", Op: common.OpExists}, false},
+		{"contains in array", common.JSONAssertion{Path: "tags", Op: common.OpContains, Value: "prod"}, true},
+		{"contains in string", common.JSONAssertion{Path: "uptime", Op: common.OpContains, Value: "m"}, true},
+		{"matches", common.JSONAssertion{Path: "uptime", Op: common.OpMatches, Value: `^[0-9]+m$`}, true},
+		{"matches fails", common.JSONAssertion{Path: "uptime", Op: common.OpMatches, Value: `^[0-9]+h$`}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					return createMockResponse(http.StatusOK, body, 0), nil
+				},
+			}
+
+			checker := NewHTTPCheckerWithClient(mockClient)
+			endpoint := common.Endpoint{
+				URL:            "http://example.com",
+				Method:         "GET",
+				Timeout:        5 * time.Second,
+				JSONAssertions: []common.JSONAssertion{tc.assertion},
+			}
+
+			result := checker.Check(context.Background(), endpoint)
+
+			if tc.pass {
+				if result.Status != common.StatusUp || len(result.Messages) != 0 {
+					t.Errorf("Expected assertion to pass, got status %s, messages %v", result.Status, result.Messages)
+				}
+				return
+			}
+
+			if result.Status != common.StatusDegraded {
+				t.Errorf("Expected status Degraded, got %s", result.Status)
+			}
+			if len(result.Messages) != 1 || !strings.HasPrefix(result.Messages[0], common.UnexpectedJSONMessage) {
+				t.Errorf("Expected one %s message, got %v", common.UnexpectedJSONMessage, result.Messages)
+			}
+		})
+	}
+}
+
+func TestHTTPChecker_Check_JSONAssertions_MessagePerFailure(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return createMockResponse(http.StatusOK, `{"status":"degraded","uptime":"10m"}`, 0), nil
+		},
+	}
+
+	checker := NewHTTPCheckerWithClient(mockClient)
+	endpoint := common.Endpoint{
+		URL:     "http://example.com",
+		Method:  "GET",
+		Timeout: 5 * time.Second,
+		JSONAssertions: []common.JSONAssertion{
+			{Path: "status", Op: common.OpEquals, Value: "ok"},
+			{Path: "uptime", Op: common.OpExists},
+			{Path: "version", Op: common.OpExists},
+		},
+	}
+
+	result := checker.Check(context.Background(), endpoint)
+
+	if len(result.Messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %v", result.Messages)
+	}
+	if !strings.Contains(result.Messages[0], "status") || !strings.Contains(result.Messages[0], `"degraded"`) {
+		t.Errorf("Expected message to name the field and actual value, got %q", result.Messages[0])
+	}
+	if !strings.Contains(result.Messages[1], "version") {
+		t.Errorf("Expected message to name the missing field, got %q", result.Messages[1])
+	}
+}
+
+func TestHTTPChecker_Check_JSONAssertions_InvalidBody(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return createMockResponse(http.StatusOK, `<html>OK</html>`, 0), nil
+		},
+	}
+
+	checker := NewHTTPCheckerWithClient(mockClient)
+	endpoint := common.Endpoint{
+		URL:            "http://example.com",
+		Method:         "GET",
+		Timeout:        5 * time.Second,
+		JSONAssertions: []common.JSONAssertion{{Path: "status", Value: "ok"}},
+	}
+
+	result := checker.Check(context.Background(), endpoint)
+
+	if result.Status != common.StatusDegraded {
+		t.Errorf("Expected status Degraded, got %s", result.Status)
+	}
+	if len(result.Messages) != 1 || !strings.Contains(result.Messages[0], "invalid JSON") {
+		t.Errorf("Expected invalid JSON message, got %v", result.Messages)
+	}
+}
+
+func TestHTTPChecker_Check_TLS(t *testing.T) {
+	ca, err := tlschecker.NewMockCA()
+	if err != nil {
+		t.Fatalf("create CA: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		validFor       time.Duration
+		expectedStatus string
+		message        string
+	}{
+		{"valid", 90 * 24 * time.Hour, common.StatusUp, ""},
+		{"expiring", 10 * 24 * time.Hour, common.StatusDegraded, common.CertificateExpiringMessage},
+		{"expired", -24 * time.Hour, common.StatusDown, common.CertificateExpiredMessage},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cert, err := ca.Issue(time.Now().Add(tc.validFor))
+			if err != nil {
+				t.Fatalf("issue certificate: %v", err)
+			}
+
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+			server.StartTLS()
+			defer server.Close()
+
+			client := &http.Client{
+				Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: ca.Pool}},
+			}
+			checker := NewHTTPCheckerWithClient(client)
+			endpoint := common.Endpoint{
+				URL:     server.URL,
+				Method:  "GET",
+				Timeout: 5 * time.Second,
+			}
+
+			result := checker.Check(context.Background(), endpoint)
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %s, got %s (messages: %v, error: %s)", tc.expectedStatus, result.Status, result.Messages, result.Error)
+			}
+			if tc.message != "" && !slices.Contains(result.Messages, tc.message) {
+				t.Errorf("Expected message %q, got %v", tc.message, result.Messages)
+			}
+			if result.TLS == nil {
+				t.Fatalf("Expected TLS details")
+			}
+			if result.TLS.ChainValid != (tc.validFor > 0) {
+				t.Errorf("Expected ChainValid=%v, got %v", tc.validFor > 0, result.TLS.ChainValid)
+			}
+		})
+	}
+}
+
+func TestHTTPChecker_Check_RequestBody(t *testing.T) {
+	dir := t.TempDir()
+	bodyFile := filepath.Join(dir, "payload.xml")
+	if err := os.WriteFile(bodyFile, []byte(`<ping id="{{ randInt 1 1 }}"/>`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		endpoint    common.Endpoint
+		contentType string
+		check       func(t *testing.T, body string)
+	}{
+		{
+			name:        "inline body",
+			endpoint:    common.Endpoint{Body: "hello", ContentType: "text/plain"},
+			contentType: "text/plain",
+			check: func(t *testing.T, body string) {
+				if body != "hello" {
+					t.Errorf("Expected body hello, got %q", body)
+				}
+			},
+		},
+		{
+			name:        "inline body without content type",
+			endpoint:    common.Endpoint{Body: "ping {{ randInt 7 7 }}"},
+			contentType: common.ContentTypeText,
+			check: func(t *testing.T, body string) {
+				if body != "ping 7" {
+					t.Errorf("Expected rendered body, got %q", body)
+				}
+			},
+		},
+		{
+			name:        "body file",
+			endpoint:    common.Endpoint{BodyFile: bodyFile, ContentType: "application/xml"},
+			contentType: "application/xml",
+			check: func(t *testing.T, body string) {
+				if body != `<ping id="1"/>` {
+					t.Errorf("Expected rendered file body, got %q", body)
+				}
+			},
+		},
+		{
+			name:        "json text",
+			endpoint:    common.Endpoint{BodyJSON: `{"requestId":"{{ uuid }}","at":{{ unix }}}`},
+			contentType: common.ContentTypeJSON,
+			check: func(t *testing.T, body string) {
+				var payload struct {
+					RequestID string `json:"requestId"`
+					At        int64  `json:"at"`
+				}
+				if err := json.Unmarshal([]byte(body), &payload); err != nil {
+					t.Fatalf("Expected valid JSON, got %q: %v", body, err)
+				}
+				if len(payload.RequestID) != 36 {
+					t.Errorf("Expected a UUID, got %q", payload.RequestID)
+				}
+				if time.Since(time.Unix(payload.At, 0)) > time.Minute {
+					t.Errorf("Expected current timestamp, got %d", payload.At)
+				}
+			},
+		},
+		{
+			name:        "json mapping",
+			endpoint:    common.Endpoint{BodyJSON: map[string]any{"name": "pulse", "count": 2}},
+			contentType: common.ContentTypeJSON,
+			check: func(t *testing.T, body string) {
+				if body != `{"count":2,"name":"pulse"}` {
+					t.Errorf("Expected encoded mapping, got %q", body)
+				}
+			},
+		},
+		{
+			name:        "form",
+			endpoint:    common.Endpoint{Form: map[string]string{"user": "pulse", "day": `{{ now "2006-01-02" }}`}},
+			contentType: common.ContentTypeForm,
+			check: func(t *testing.T, body string) {
+				want := "day=" + time.Now().Format("2006-01-02") + "&user=pulse"
+				if body != want {
+					t.Errorf("Expected form %q, got %q", want, body)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var capturedBody, capturedContentType string
+			mockClient := &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					b, _ := io.ReadAll(req.Body)
+					capturedBody = string(b)
+					capturedContentType = req.Header.Get("Content-Type")
+					return createMockResponse(http.StatusCreated, "", 0), nil
+				},
+			}
+
+			endpoint := tc.endpoint
+			endpoint.URL = "http://example.com"
+			endpoint.Method = "POST"
+			endpoint.Timeout = 5 * time.Second
+
+			checker := NewHTTPCheckerWithClient(mockClient)
+			result := checker.Check(context.Background(), endpoint)
+
+			if result.Status != common.StatusUp {
+				t.Fatalf("Expected status Up, got %s (error: %s)", result.Status, result.Error)
+			}
+			if capturedContentType != tc.contentType {
+				t.Errorf("Expected Content-Type %q, got %q", tc.contentType, capturedContentType)
+			}
+			tc.check(t, capturedBody)
+		})
+	}
+}
+
+func TestHTTPChecker_Check_RequestBodyRenderedPerCheck(t *testing.T) {
+	var bodies []string
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			b, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(b))
+			return createMockResponse(http.StatusOK, "", 0), nil
+		},
+	}
+
+	endpoint := common.Endpoint{
+		URL:     "http://example.com",
+		Method:  "PUT",
+		Timeout: 5 * time.Second,
+		Body:    "{{ uuid }}",
+	}
+	payload, err := common.NewPayload(&endpoint, "")
+	if err != nil {
+		t.Fatalf("compile payload: %v", err)
+	}
+	endpoint.Payload = payload
+
+	checker := NewHTTPCheckerWithClient(mockClient)
+	checker.Check(context.Background(), endpoint)
+	checker.Check(context.Background(), endpoint)
+
+	if len(bodies) != 2 || bodies[0] == bodies[1] {
+		t.Errorf("Expected a fresh UUID per check, got %v", bodies)
+	}
+}
+
+func TestHTTPChecker_Check_RequestBodyInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint common.Endpoint
+	}{
+		{"unknown function", common.Endpoint{Body: "{{ nope }}"}},
+		{"several bodies", common.Endpoint{Body: "a", Form: map[string]string{"b": "c"}}},
+		{"invalid json", common.Endpoint{BodyJSON: `{"a":`}},
+		{"missing file", common.Endpoint{BodyFile: "/does/not/exist"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := common.NewPayload(&tc.endpoint, ""); err == nil {
+				t.Errorf("Expected an error")
+			}
+
+			endpoint := tc.endpoint
+			endpoint.URL = "http://example.com"
+			endpoint.Method = "POST"
+			endpoint.Timeout = 5 * time.Second
+
+			checker := NewHTTPCheckerWithClient(&MockHTTPClient{})
+			result := checker.Check(context.Background(), endpoint)
+			if result.Status != common.StatusUnreachable || result.Error == "" {
+				t.Errorf("Expected Unreachable with error, got %s (%q)", result.Status, result.Error)
+			}
+		})
+	}
+}
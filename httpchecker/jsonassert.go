@@ -0,0 +1,203 @@
+package httpchecker
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// evaluateJSON runs every assertion against body and returns one message per failure.
+func evaluateJSON(assertions []common.JSONAssertion, body []byte) []string {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return []string{fmt.Sprintf("%s: invalid JSON body: %v", common.UnexpectedJSONMessage, err)}
+	}
+
+	failures := make([]string, 0)
+	for _, a := range assertions {
+		if err := evaluateAssertion(a, doc); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s: %v", common.UnexpectedJSONMessage, a, err))
+		}
+	}
+	return failures
+}
+
+// evaluateAssertion returns nil when the assertion holds, or an error describing why it failed.
+func evaluateAssertion(a common.JSONAssertion, doc any) error {
+	segments, err := common.ParseJSONPath(a.Path)
+	if err != nil {
+		return err
+	}
+	actual, found := lookup(doc, segments)
+
+	op := strings.ToLower(a.Op)
+	if op == "" {
+		op = common.OpEquals
+	}
+
+	switch op {
+	case common.OpExists:
+		if !found {
+			return fmt.Errorf("not found")
+		}
+		return nil
+	case common.OpNotExists:
+		if found {
+			return fmt.Errorf("got %s", describe(actual))
+		}
+		return nil
+	}
+
+	if !found {
+		return fmt.Errorf("not found")
+	}
+
+	switch op {
+	case common.OpEquals:
+		if !equal(actual, a.Value) {
+			return fmt.Errorf("got %s", describe(actual))
+		}
+	case common.OpNotEquals:
+		if equal(actual, a.Value) {
+			return fmt.Errorf("got %s", describe(actual))
+		}
+	case common.OpLt, common.OpLte, common.OpGt, common.OpGte:
+		got, ok := actual.(float64)
+		if !ok {
+			return fmt.Errorf("got non-numeric %s", describe(actual))
+		}
+		want, ok := common.ToFloat(a.Value)
+		if !ok {
+			return fmt.Errorf("non-numeric value %v", a.Value)
+		}
+		if !compare(op, got, want) {
+			return fmt.Errorf("got %s", describe(actual))
+		}
+	case common.OpContains:
+		if !contains(actual, a.Value) {
+			return fmt.Errorf("got %s", describe(actual))
+		}
+	case common.OpMatches:
+		pattern := a.Pattern
+		if pattern == nil {
+			// Assertion did not go through config validation, compile on the fly
+			if pattern, err = regexp.Compile(fmt.Sprint(a.Value)); err != nil {
+				return err
+			}
+		}
+		s, ok := actual.(string)
+		if !ok {
+			s = describe(actual)
+		}
+		if !pattern.MatchString(s) {
+			return fmt.Errorf("got %s", describe(actual))
+		}
+	default:
+		return fmt.Errorf("invalid operator: %q", a.Op)
+	}
+	return nil
+}
+
+// lookup walks doc following the path segments.
+func lookup(doc any, segments []any) (any, bool) {
+	current := doc
+	for _, seg := range segments {
+		switch node := current.(type) {
+		case map[string]any:
+			key, ok := seg.(string)
+			if !ok {
+				// Numeric segment on an object, e.g. {"0": ...}
+				key = fmt.Sprint(seg)
+			}
+			if current, ok = node[key]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, ok := seg.(int)
+			if !ok || i >= len(node) {
+				return nil, false
+			}
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// equal compares a decoded JSON value with a configured value.
+// Numbers compare by value regardless of their Go type.
+func equal(actual, expected any) bool {
+	if got, ok := actual.(float64); ok {
+		want, ok := common.ToFloat(expected)
+		return ok && got == want
+	}
+	if expected == nil {
+		return actual == nil
+	}
+	if s, ok := actual.(string); ok {
+		return s == fmt.Sprint(expected)
+	}
+	if b, ok := actual.(bool); ok {
+		want, ok := expected.(bool)
+		if !ok {
+			return fmt.Sprint(expected) == fmt.Sprint(b)
+		}
+		return b == want
+	}
+	// Objects and arrays: compare their JSON forms
+	want, err := json.Marshal(expected)
+	if err != nil {
+		return false
+	}
+	var normalized any
+	if err := json.Unmarshal(want, &normalized); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(actual, normalized)
+}
+
+// contains reports whether a string contains, or an array holds, the expected value.
+func contains(actual, expected any) bool {
+	switch v := actual.(type) {
+	case string:
+		return strings.Contains(v, fmt.Sprint(expected))
+	case []any:
+		for _, item := range v {
+			if equal(item, expected) {
+				return true
+			}
+		}
+	case map[string]any:
+		_, ok := v[fmt.Sprint(expected)]
+		return ok
+	}
+	return false
+}
+
+func compare(op string, got, want float64) bool {
+	switch op {
+	case common.OpLt:
+		return got < want
+	case common.OpLte:
+		return got <= want
+	case common.OpGt:
+		return got > want
+	case common.OpGte:
+		return got >= want
+	}
+	return false
+}
+
+// describe renders a decoded JSON value for messages.
+func describe(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
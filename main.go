@@ -1,24 +1,42 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/mohamedbeat/pulse/api"
 	"github.com/mohamedbeat/pulse/common"
+	"github.com/mohamedbeat/pulse/dnschecker"
 	"github.com/mohamedbeat/pulse/httpchecker"
+	"github.com/mohamedbeat/pulse/maintenance"
+	"github.com/mohamedbeat/pulse/notifier"
+	"github.com/mohamedbeat/pulse/state"
+	"github.com/mohamedbeat/pulse/store"
+	"github.com/mohamedbeat/pulse/tcpchecker"
+	"github.com/mohamedbeat/pulse/tlschecker"
 )
 
 func main() {
-	Info("Initializing ...")
-
 	envs, err := LoadEnv()
 	if err != nil {
 		panic(err)
 	}
-	fmt.Println(envs)
+
+	// Subcommands run and exit before the monitor logs anything
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "silence":
+			os.Exit(runSilenceCommand(envs, os.Args[2:]))
+		case "incident":
+			os.Exit(runIncidentCommand(envs, os.Args[2:]))
+		}
+	}
+
+	Info("Initializing ...")
 
 	configPath := ParseFlags()
 	Info("configFile path", "path", configPath)
@@ -28,23 +46,154 @@ func main() {
 		panic(err)
 	}
 
+	st, err := store.Open(envs.Dbdriver, envs.Dbname)
+	if err != nil {
+		panic(err)
+	}
+	defer st.Close()
+
+	// Endpoints added, changed, paused or deleted through the API override the config file
+	changes, err := st.EndpointChanges(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	var paused []string
+	config.Endpoints, paused, err = applyEndpointChanges(config, changes)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := st.SaveEndpoints(context.Background(), config.Endpoints); err != nil {
+		panic(err)
+	}
+
+	// Pick up where the previous run left off so restarts don't open duplicate incidents
+	tracker := state.NewTracker(st)
+	for _, ep := range config.Endpoints {
+		tracker.SetRules(ep.ID, ep.Alert)
+	}
+	// Results checked during maintenance never changed the tracked status, so they don't seed it either
+	latest, err := st.LatestObserved(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	openIncidents, err := st.OpenIncidents(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	tracker.Restore(latest, openIncidents)
+
+	// Results of endpoints in a maintenance window or silenced are flagged and never alerted on
+	windows := maintenance.NewManager(config.Maintenance)
+	silences, err := st.Silences(context.Background(), time.Now())
+	if err != nil {
+		panic(err)
+	}
+	windows.SetSilences(silences)
+
 	Debug("Globals", "Globals", config.Globals)
 	Debug("Config", "config", config)
 
 	httpChecker := httpchecker.NewHTTPChecker()
+	tcpChecker := tcpchecker.NewTCPChecker()
+	dnsChecker := dnschecker.NewDNSChecker()
+	tlsChecker := tlschecker.NewTLSChecker()
 
 	// Buffer size: at least 10, or 2x the number of endpoints (whichever is larger)
 	// This handles bursts when multiple endpoints complete checks simultaneously
 	bufferSize := len(config.Endpoints) * 2
 	bufferSize = max(bufferSize, 10)
 
-	scheduler := Scheduler{
-		endpoints: config.Endpoints,
-		checkers: map[string]Checker{
-			common.HTTPType: httpChecker,
+	scheduler := NewScheduler(config.Endpoints, map[string]Checker{
+		common.HTTPType: httpChecker,
+		common.TCPType:  tcpChecker,
+		common.DNSType:  dnsChecker,
+		common.TLSType:  tlsChecker,
+	}, bufferSize, SchedulerOptions{
+		MaxConcurrency: config.Globals.MaxConcurrency,
+		MaxPerHost:     config.Globals.MaxPerHost,
+		Jitter:         config.Globals.Jitter,
+		InMaintenance:  windows.Active,
+	})
+	for _, id := range paused {
+		if err := scheduler.Pause(id); err != nil {
+			panic(err)
+		}
+	}
+
+	// Deliver alerts in the background so a slow notifier never holds up results
+	dispatchOpts := config.Notifications.DispatcherOptions
+	dispatchOpts.OnError = func(name string, alert notifier.Alert, err error) {
+		Error("notification_failed",
+			"notifier", name,
+			"endpoint", alert.EndpointID,
+			"new_status", alert.NewStatus,
+			"error", err.Error(),
+		)
+		recordAlert(st, name, alert, err)
+	}
+	dispatchOpts.OnSent = func(name string, alert notifier.Alert) {
+		recordAlert(st, name, alert, nil)
+	}
+	notifiers, err := buildNotifiers(config.Notifications)
+	if err != nil {
+		panic(err)
+	}
+	dispatcher := notifier.NewDispatcher(notifiers, dispatchOpts)
+
+	names := make([]string, 0, len(notifiers))
+	for _, n := range notifiers {
+		names = append(names, n.Name())
+	}
+	// Alerts go to the notifiers of the routes their labels match
+	router, err := notifier.NewRouter(dispatcher, config.Notifications.Route, names)
+	if err != nil {
+		panic(err)
+	}
+
+	// Escalation policies repeat and escalate alerts until their incident is acknowledged
+	escalator, err := notifier.NewEscalator(router, config.Notifications.Escalations, names)
+	if err != nil {
+		panic(err)
+	}
+	escalator.Paused = func(id string, at time.Time) bool {
+		ep, _ := scheduler.Endpoint(id)
+		return scheduler.Paused(id) || windows.Active(ep, at)
+	}
+	for _, incident := range openIncidents {
+		ep, _ := scheduler.Endpoint(incident.EndpointID)
+		escalator.Resume(notifier.Alert{
+			EndpointID:   ep.ID,
+			EndpointName: ep.Name,
+			URL:          ep.URL,
+			Labels:       ep.Labels,
+			NewStatus:    incident.Status,
+			Result:       latest[ep.ID],
+			IncidentID:   incident.ID,
+			StartedAt:    incident.StartedAt,
+			Time:         incident.StartedAt,
+		}, ep.Escalation, incident.Acknowledged(), time.Now())
+	}
+
+	// Serve the REST API while checks run; endpoints changed through it are picked up right away
+	server := api.NewServer(config.API, api.Options{
+		Store:         st,
+		Endpoints:     scheduler.Endpoints,
+		Tracker:       tracker,
+		InMaintenance: windows.Active,
+		Paused:        scheduler.Paused,
+		Manager:       newEndpointManager(config, scheduler, st, tracker, escalator, changes),
+		Acknowledged: func(incidentID int64, by string) {
+			if escalator.Acknowledge(incidentID) {
+				Info("incident_acknowledged", "incident", incidentID, "by", by)
+			}
 		},
-		results: make(chan common.Result, bufferSize),
-		stop:    make(chan struct{}),
+	})
+	if config.API.Listen != "" {
+		if err := server.Start(); err != nil {
+			panic(err)
+		}
+		Info("api_listening", "addr", server.Addr())
 	}
 
 	// Setup graceful shutdown
@@ -52,29 +201,66 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	// A goroutine that will close the results channel when scheduler stops
 	// This will break the for-loop below
+	abandoned := make(chan struct{})
 	go func() {
 		<-quit // Wait for shutdown signal
 		Info("Shutdown signal received")
 
-		// Stop the scheduler (this closes s.stop channel)
-		scheduler.Stop()
-
-		// Wait for all endpoint goroutines to finish
-		time.Sleep(1 * time.Second)
+		// Cancel in-flight checks, wait for every worker to exit and close the results channel.
+		// Workers still running after the deadline are abandoned, the rest of the shutdown
+		// (notifications, API, store) still runs.
+		if err := scheduler.Shutdown(config.Globals.ShutdownTimeout); err != nil {
+			Error("Shutdown failed", "error", err.Error())
+			close(abandoned)
+		}
+	}()
 
-		// Close the results channel to break the for-loop
-		close(scheduler.results)
+	// Roll up and prune the stored history in the background
+	maintenanceCtx, stopMaintenance := context.WithCancel(context.Background())
+	maintenanceDone := make(chan struct{})
+	go func() {
+		defer close(maintenanceDone)
+		runMaintenance(maintenanceCtx, st, config.Retention)
+	}()
+	silencesDone := make(chan struct{})
+	go func() {
+		defer close(silencesDone)
+		syncSilences(maintenanceCtx, st, windows)
+	}()
+	escalationsDone := make(chan struct{})
+	go func() {
+		defer close(escalationsDone)
+		runEscalations(maintenanceCtx, st, escalator)
+	}()
+	// Grouped alerts are sent once their group is due, and all at once on shutdown
+	groupsDone := make(chan struct{})
+	go func() {
+		defer close(groupsDone)
+		router.Run(maintenanceCtx)
 	}()
 
 	//Starting scheduler
-	go scheduler.Start()
+	scheduler.Start()
 
 	//Getting scheduler results
-	for result := range scheduler.results {
+	for {
+		result, ok := nextResult(scheduler.Results(), abandoned)
+		if !ok {
+			break
+		}
+
+		// A check that completed just before its endpoint was deleted
+		ep, ok := scheduler.Endpoint(result.EndpointID)
+		if !ok {
+			Debug("result_dropped", "endpoint", result.EndpointID, "reason", "endpoint deleted")
+			continue
+		}
+
 		fmt.Println("messages", result.Messages)
 		switch result.Status {
 		case common.StatusDown, common.StatusUnreachable:
 			Error("Error",
+				"endpoint", result.EndpointID,
 				"url", result.URL,
 				"status", result.Status,
 				"status_code", result.StatusCode,
@@ -82,9 +268,11 @@ func main() {
 				"error", result.Error,
 				"messages", result.Messages,
 				"elapsed", result.Elapsed,
+				"attempts", max(len(result.Attempts), 1),
 			)
 		case common.StatusDegraded:
 			Warn("Warning",
+				"endpoint", result.EndpointID,
 				"url", result.URL,
 				"status", result.Status,
 				"status_code", result.StatusCode,
@@ -92,9 +280,11 @@ func main() {
 				"error", result.Error,
 				"messages", result.Messages,
 				"elapsed", result.Elapsed,
+				"attempts", max(len(result.Attempts), 1),
 			)
 		default:
 			Info("saving_result",
+				"endpoint", result.EndpointID,
 				"url", result.URL,
 				"status", result.Status,
 				"status_code", result.StatusCode,
@@ -102,22 +292,94 @@ func main() {
 				"error", result.Error,
 				"messages", result.Messages,
 				"elapsed", result.Elapsed,
+				"attempts", max(len(result.Attempts), 1),
 			)
 		}
 
-		// Info("Shutdown complete")
+		// Maintenance results leave the tracked status alone, so nothing is alerted
+		// during the window and the first result after it is compared to the last one before
+		var transition *common.Transition
+		if !result.Maintenance {
+			transition, err = tracker.Observe(context.Background(), result)
+			if err != nil {
+				Error("incident_update_failed",
+					"endpoint", result.EndpointID,
+					"error", err.Error(),
+				)
+			}
+			result.Flapping = tracker.Flapping(result.EndpointID)
+		}
+
+		if err := st.SaveResult(context.Background(), result); err != nil {
+			Error("save_result_failed",
+				"endpoint", result.EndpointID,
+				"url", result.URL,
+				"error", err.Error(),
+			)
+		}
+
+		if transition != nil {
+			logTransition(transition)
+			// Transitions of a flapping endpoint are summed up by its flapping alerts
+			if !transition.Suppressed() {
+				escalator.Dispatch(notifier.NewAlert(transition, ep), ep.Escalation)
+			}
+		}
+	}
+
+	stopMaintenance()
+	<-maintenanceDone
+	<-silencesDone
+	<-escalationsDone
+	<-groupsDone
+
+	if err := server.Shutdown(config.Globals.ShutdownTimeout); err != nil {
+		Error("api_shutdown_failed", "error", err.Error())
+	}
+
+	if err := dispatcher.Close(config.Globals.ShutdownTimeout); err != nil {
+		Error("notifications_abandoned", "error", err.Error(), "stats", dispatcher.Stats())
+	}
 
-		// store.Save(result)
+	Info("Shutdown complete")
+}
 
-		// oldStatus := alertState[result.EndpointID]
-		// newStatus := result.Status
+// nextResult waits for the next result. It returns false once results is closed,
+// or once the scheduler was abandoned for missing its shutdown deadline.
+func nextResult(results <-chan common.Result, abandoned <-chan struct{}) (common.Result, bool) {
+	select {
+	case res, ok := <-results:
+		return res, ok
+	case <-abandoned:
+		return common.Result{}, false
+	}
+}
+
+// logTransition reports a status change, with the incident it opened or closed.
+func logTransition(t *common.Transition) {
+	args := []any{
+		"endpoint", t.EndpointID,
+		"url", t.Result.URL,
+		"from", t.From,
+		"to", t.To,
+		"at", t.At,
+	}
+	if t.Incident != nil {
+		args = append(args, "incident", t.Incident.ID, "reason", t.Incident.Reason)
+		if !t.Incident.Open() {
+			args = append(args, "duration", t.Incident.Duration(t.At).String())
+		}
+	}
+	if t.Flap != "" {
+		args = append(args, "flap", t.Flap)
+	}
 
-		// if oldStatus != newStatus {
-		// alertState[result.EndpointID] = newStatus
-		// alert := Alert{...}
-		// for _, n := range m.notifiers {
-		//     go n.Notify(context.Background(), alert) // async!
-		// }
-		// }
+	switch {
+	case common.IsFailing(t.To):
+		Error("status_changed", args...)
+	case t.To == common.StatusDegraded:
+		Warn("status_changed", args...)
+	default:
+		Info("status_changed", args...)
 	}
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/mohamedbeat/pulse/store"
+)
+
+// runMaintenance rolls up and prunes the stored history every policy.Interval
+// until ctx is cancelled. The first run happens right away.
+func runMaintenance(ctx context.Context, st store.Store, policy store.RetentionPolicy) {
+	ticker := time.NewTicker(policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		stats, err := st.Maintain(ctx, policy, start)
+		if err != nil && ctx.Err() == nil {
+			Error("maintenance_failed", "error", err.Error())
+		} else if err == nil {
+			Debug("maintenance_done",
+				"aggregated", stats.Aggregated,
+				"deleted_raw", stats.DeletedRaw,
+				"deleted_aggregates", stats.DeletedAggregates,
+				"took", time.Since(start).String(),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package maintenance
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// Manager holds the configured windows and the current silences.
+// It is safe for concurrent use.
+type Manager struct {
+	windows []Window
+
+	mu       sync.RWMutex
+	silences []common.Silence
+}
+
+// NewManager returns a Manager for windows, which must have been validated.
+func NewManager(windows []Window) *Manager {
+	return &Manager{windows: windows}
+}
+
+// SetSilences replaces the known silences. Silences whose matchers do not
+// parse, which ValidateSilence rejects, are left out.
+func (m *Manager) SetSilences(silences []common.Silence) {
+	compiled := make([]common.Silence, 0, len(silences))
+	for _, s := range silences {
+		if s.Compile() == nil {
+			compiled = append(compiled, s)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.silences = compiled
+}
+
+// AddSilence adds or replaces (by ID) a silence.
+func (m *Manager) AddSilence(s common.Silence) {
+	if s.Compile() != nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.silences = slices.DeleteFunc(m.silences, func(old common.Silence) bool { return old.ID == s.ID })
+	m.silences = append(m.silences, s)
+}
+
+// Active reports whether ep is under maintenance at t.
+func (m *Manager) Active(ep common.Endpoint, t time.Time) bool {
+	for i := range m.windows {
+		if m.windows[i].Matches(ep) && m.windows[i].Active(t) {
+			return true
+		}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.silences {
+		if s.Matches(ep) && s.Active(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// Sunday 2026-01-04 02:30 UTC
+	at := time.Date(2026, 1, 4, 2, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr    string
+		matches bool
+	}{
+		{"30 2 * * 0", true},
+		{"30 2 * * sun", true},
+		{"30 2 * * 7", true},
+		{"30 2 * * mon-fri", false},
+		{"*/15 * * * *", true},
+		{"*/20 * * * *", false},
+		{"0,30 1-3 * jan *", true},
+		{"30 2 4 * *", true},
+		{"30 2 5 * mon", false},
+		{"30 2 5 * sun", true}, // either day field matches
+		{"@daily", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := ParseSchedule(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Matches(at); got != tt.matches {
+				t.Errorf("Expected %v, got %v", tt.matches, got)
+			}
+		})
+	}
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "* * * * funday"} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("Expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestSchedule_Last(t *testing.T) {
+	s, err := ParseSchedule("0 2 * * sun")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sunday := time.Date(2026, 1, 4, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		at     time.Time
+		within time.Duration
+		want   time.Time
+		ok     bool
+	}{
+		{"at the start", sunday, time.Hour, sunday, true},
+		{"within the hour", sunday.Add(59 * time.Minute), time.Hour, sunday, true},
+		{"too far back", sunday.Add(2 * time.Hour), time.Hour, time.Time{}, false},
+		{"a week later", sunday.Add(7*24*time.Hour + time.Minute), 7 * 24 * time.Hour, sunday.Add(7 * 24 * time.Hour), true},
+		{"before the first", sunday.Add(-time.Minute), 24 * time.Hour, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := s.Last(tt.at, tt.within)
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Errorf("Expected %v (%v), got %v (%v)", tt.want, tt.ok, got, ok)
+			}
+		})
+	}
+}
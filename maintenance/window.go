@@ -0,0 +1,85 @@
+// Package maintenance decides whether an endpoint is under maintenance, either
+// during a configured window or while an ad-hoc silence is active.
+package maintenance
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// maxRecurringDuration bounds recurring windows so looking back for their start stays cheap.
+const maxRecurringDuration = 7 * 24 * time.Hour
+
+// Window is a maintenance window of the endpoints in its scope. It is either
+// one-off, from Start to End, or recurring: it opens every time Schedule fires
+// and lasts Duration.
+type Window struct {
+	Name         string `mapstructure:"name" json:"name" yaml:"name"`
+	common.Scope `mapstructure:",squash"`
+	Start        string        `mapstructure:"start" json:"start,omitempty" yaml:"start,omitempty"` // RFC 3339
+	End          string        `mapstructure:"end" json:"end,omitempty" yaml:"end,omitempty"`
+	Schedule     string        `mapstructure:"schedule" json:"schedule,omitempty" yaml:"schedule,omitempty"` // cron expression
+	Duration     time.Duration `mapstructure:"duration" json:"duration,omitempty" yaml:"duration,omitempty"`
+	Timezone     string        `mapstructure:"timezone" json:"timezone,omitempty" yaml:"timezone,omitempty"` // of Schedule, UTC by default
+
+	start, end time.Time
+	schedule   *Schedule
+	location   *time.Location
+}
+
+// ValidateWindow parses w and checks it is either one-off or recurring.
+func ValidateWindow(w *Window) error {
+	if w.Empty() {
+		return fmt.Errorf("needs at least one endpoint, tag or matcher")
+	}
+	if err := w.Compile(); err != nil {
+		return err
+	}
+
+	oneOff := w.Start != "" || w.End != ""
+	recurring := w.Schedule != ""
+	switch {
+	case oneOff && recurring:
+		return fmt.Errorf("set either start and end or a schedule, not both")
+	case oneOff:
+		var err error
+		if w.start, err = time.Parse(time.RFC3339, w.Start); err != nil {
+			return fmt.Errorf("invalid start: %w", err)
+		}
+		if w.end, err = time.Parse(time.RFC3339, w.End); err != nil {
+			return fmt.Errorf("invalid end: %w", err)
+		}
+		if !w.end.After(w.start) {
+			return fmt.Errorf("end must be after start")
+		}
+	case recurring:
+		var err error
+		if w.schedule, err = ParseSchedule(w.Schedule); err != nil {
+			return err
+		}
+		if w.Duration <= 0 || w.Duration > maxRecurringDuration {
+			return fmt.Errorf("duration must be between 1m and %s", maxRecurringDuration)
+		}
+		if w.Timezone == "" {
+			w.Timezone = "UTC"
+		}
+		if w.location, err = time.LoadLocation(w.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone: %w", err)
+		}
+	default:
+		return fmt.Errorf("needs start and end, or a schedule and a duration")
+	}
+	return nil
+}
+
+// Active reports whether the window is open at t. w must have been validated.
+func (w *Window) Active(t time.Time) bool {
+	if w.schedule == nil {
+		return !t.Before(w.start) && t.Before(w.end)
+	}
+	local := t.In(w.location)
+	last, ok := w.schedule.Last(local, w.Duration)
+	return ok && local.Before(last.Add(w.Duration))
+}
@@ -0,0 +1,134 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+func TestWindow_Active(t *testing.T) {
+	tests := []struct {
+		name   string
+		window Window
+		at     time.Time
+		active bool
+	}{
+		{
+			name:   "one-off inside",
+			window: Window{Start: "2026-03-01T10:00:00Z", End: "2026-03-01T12:00:00Z"},
+			at:     time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC),
+			active: true,
+		},
+		{
+			name:   "one-off end is exclusive",
+			window: Window{Start: "2026-03-01T10:00:00Z", End: "2026-03-01T12:00:00Z"},
+			at:     time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+			active: false,
+		},
+		{
+			name:   "every Sunday 02:00-03:00 UTC",
+			window: Window{Schedule: "0 2 * * sun", Duration: time.Hour},
+			at:     time.Date(2026, 1, 11, 2, 45, 0, 0, time.UTC),
+			active: true,
+		},
+		{
+			name:   "recurring window closed",
+			window: Window{Schedule: "0 2 * * sun", Duration: time.Hour},
+			at:     time.Date(2026, 1, 11, 3, 0, 0, 0, time.UTC),
+			active: false,
+		},
+		{
+			name:   "recurring across midnight",
+			window: Window{Schedule: "30 23 * * *", Duration: time.Hour},
+			at:     time.Date(2026, 1, 12, 0, 15, 0, 0, time.UTC),
+			active: true,
+		},
+		{
+			name:   "recurring in a timezone",
+			window: Window{Schedule: "0 2 * * *", Duration: time.Hour, Timezone: "Europe/Paris"},
+			at:     time.Date(2026, 1, 12, 1, 30, 0, 0, time.UTC), // 02:30 in Paris
+			active: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := tt.window
+			w.Endpoints = []string{"api"}
+			if err := ValidateWindow(&w); err != nil {
+				t.Fatal(err)
+			}
+			if got := w.Active(tt.at); got != tt.active {
+				t.Errorf("Expected active %v, got %v", tt.active, got)
+			}
+		})
+	}
+}
+
+func TestValidateWindow_Invalid(t *testing.T) {
+	scope := common.Scope{Tags: []string{"db"}}
+	tests := []struct {
+		name   string
+		window Window
+	}{
+		{"no scope", Window{Schedule: "@daily", Duration: time.Hour}},
+		{"no timing", Window{Scope: scope}},
+		{"both", Window{Scope: scope, Start: "2026-03-01T10:00:00Z", End: "2026-03-01T12:00:00Z", Schedule: "@daily", Duration: time.Hour}},
+		{"end before start", Window{Scope: scope, Start: "2026-03-01T12:00:00Z", End: "2026-03-01T10:00:00Z"}},
+		{"bad time", Window{Scope: scope, Start: "tomorrow", End: "2026-03-01T10:00:00Z"}},
+		{"no duration", Window{Scope: scope, Schedule: "@daily"}},
+		{"bad matcher", Window{Scope: common.Scope{Matchers: []string{"team"}}, Schedule: "@daily", Duration: time.Hour}},
+		{"bad timezone", Window{Scope: scope, Schedule: "@daily", Duration: time.Hour, Timezone: "Mars/Olympus"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateWindow(&tt.window); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+func TestManager_Active(t *testing.T) {
+	db := common.Endpoint{ID: "db", Tags: []string{"database"}}
+	api := common.Endpoint{ID: "api"}
+	now := time.Date(2026, 1, 11, 2, 30, 0, 0, time.UTC)
+
+	window := Window{Scope: common.Scope{Tags: []string{"database"}}, Schedule: "0 2 * * sun", Duration: time.Hour}
+	if err := ValidateWindow(&window); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager([]Window{window})
+
+	if !m.Active(db, now) {
+		t.Errorf("Expected the tagged endpoint to be under maintenance")
+	}
+	if m.Active(api, now) {
+		t.Errorf("Expected other endpoints to be unaffected")
+	}
+
+	m.AddSilence(common.Silence{ID: 1, Scope: common.Scope{Endpoints: []string{"api"}}, StartsAt: now, EndsAt: now.Add(time.Hour)})
+	if !m.Active(api, now) || m.Active(api, now.Add(time.Hour)) {
+		t.Errorf("Expected the silence to cover api for an hour")
+	}
+
+	// Label matchers select endpoints the way routes do
+	payments := common.Endpoint{ID: "billing", Labels: map[string]string{"team": "payments", "env": "prod"}}
+	m.AddSilence(common.Silence{ID: 2, Scope: common.Scope{Matchers: []string{`team="payments"`, "env=~prod|staging"}}, StartsAt: now, EndsAt: now.Add(time.Hour)})
+	if !m.Active(payments, now) {
+		t.Errorf("Expected the endpoint matching every matcher to be silenced")
+	}
+	payments.Labels["env"] = "dev"
+	if m.Active(payments, now) {
+		t.Errorf("Expected an endpoint failing a matcher not to be silenced")
+	}
+	if m.Active(db, now.Add(time.Hour)) {
+		t.Errorf("Expected endpoints without the labels not to be silenced")
+	}
+
+	// Expiring a silence replaces it
+	m.AddSilence(common.Silence{ID: 1, Scope: common.Scope{Endpoints: []string{"api"}}, StartsAt: now, EndsAt: now})
+	if m.Active(api, now) {
+		t.Errorf("Expected the expired silence to no longer apply")
+	}
+}
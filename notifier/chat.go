@@ -0,0 +1,106 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// Status colours shared by the chat notifiers
+var statusColors = map[string]int{
+	common.StatusUp:          0x2EB67D, // green
+	common.StatusDegraded:    0xECB22E, // amber
+	common.StatusDown:        0xE01E5A, // red
+	common.StatusUnreachable: 0x8B0000, // dark red
+}
+
+// statusColor returns the colour of a status as 0xRRGGBB (grey when unknown).
+func statusColor(status string) int {
+	if c, ok := statusColors[status]; ok {
+		return c
+	}
+	return 0x999999
+}
+
+// hexColor formats a colour as "#RRGGBB".
+func hexColor(c int) string {
+	return fmt.Sprintf("#%06X", c)
+}
+
+// field is a labelled value shown in chat messages.
+type field struct {
+	Name  string
+	Value string
+}
+
+// alertFields lists the details shown for an alert: status code, latency,
+// messages or error, and the incident duration on recovery; or the alerts of a group.
+func alertFields(a Alert) []field {
+	fields := []field{
+		{"Status", strings.ToUpper(a.NewStatus)},
+	}
+	// Groups list their alerts instead
+	if len(a.Group) > 0 {
+		titles := make([]string, len(a.Group))
+		for i, alert := range a.Group {
+			titles[i] = alert.Title()
+		}
+		return append(fields, field{"Alerts", strings.Join(titles, "\n")})
+	}
+	if a.Result.StatusCode != 0 {
+		fields = append(fields, field{"Status code", fmt.Sprint(a.Result.StatusCode)})
+	}
+	fields = append(fields, field{"Latency", fmt.Sprintf("%dms", a.Result.Elapsed)})
+	if a.Duration > 0 {
+		fields = append(fields, field{"Down for", a.Duration.String()})
+	}
+	if len(a.Result.Messages) > 0 {
+		fields = append(fields, field{"Messages", strings.Join(a.Result.Messages, ", ")})
+	}
+	if a.Result.Error != "" {
+		fields = append(fields, field{"Error", a.Result.Error})
+	}
+	return fields
+}
+
+// threads remembers the message an incident was first reported in, so the
+// recovery can be posted as a reply.
+type threads struct {
+	mu  sync.Mutex
+	ids map[int64]string
+}
+
+// get returns the thread of the alert's incident. Recoveries forget it, since
+// nothing will be posted to that incident again.
+func (t *threads) get(a Alert) (string, bool) {
+	if a.IncidentID == 0 {
+		return "", false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	id, ok := t.ids[a.IncidentID]
+	if ok && a.Recovered() {
+		delete(t.ids, a.IncidentID)
+	}
+	return id, ok
+}
+
+// set records the thread of an incident opened by the alert.
+func (t *threads) set(a Alert, id string) {
+	if a.IncidentID == 0 || a.Recovered() || id == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.ids == nil {
+		t.ids = make(map[int64]string)
+	}
+	// Keep the first message as the thread, reminders are posted inside it
+	if _, ok := t.ids[a.IncidentID]; !ok {
+		t.ids[a.IncidentID] = id
+	}
+}
@@ -0,0 +1,144 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DiscordConfig configures a Discord webhook. With Forum set the webhook must
+// belong to a forum channel: every incident gets its own post and the recovery
+// is added to it.
+type DiscordConfig struct {
+	Name       string `mapstructure:"name" json:"name" yaml:"name"`
+	WebhookURL string `mapstructure:"webhook_url" json:"-" yaml:"-"`
+	Username   string `mapstructure:"username" json:"username,omitempty" yaml:"username,omitempty"`
+	Forum      bool   `mapstructure:"forum" json:"forum" yaml:"forum"`
+}
+
+// DiscordNotifier posts embeds through a Discord webhook.
+type DiscordNotifier struct {
+	cfg     DiscordConfig
+	url     *url.URL
+	client  *http.Client
+	threads threads
+}
+
+// NewDiscordNotifier validates cfg.
+func NewDiscordNotifier(cfg DiscordConfig) (*DiscordNotifier, error) {
+	return NewDiscordNotifierWithClient(cfg, &http.Client{})
+}
+
+// NewDiscordNotifierWithClient is NewDiscordNotifier with a custom HTTP client.
+func NewDiscordNotifierWithClient(cfg DiscordConfig, client *http.Client) (*DiscordNotifier, error) {
+	u, err := url.Parse(cfg.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("discord %q: invalid webhook_url", cfg.Name)
+	}
+	if cfg.Username == "" {
+		cfg.Username = "Pulse"
+	}
+	return &DiscordNotifier{cfg: cfg, url: u, client: client}, nil
+}
+
+func (d *DiscordNotifier) Name() string {
+	return d.cfg.Name
+}
+
+type discordMessage struct {
+	Username   string         `json:"username"`
+	Content    string         `json:"content,omitempty"`
+	ThreadName string         `json:"thread_name,omitempty"`
+	Embeds     []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title     string         `json:"title"`
+	URL       string         `json:"url,omitempty"`
+	Color     int            `json:"color"`
+	Fields    []discordField `json:"fields"`
+	Footer    *discordFooter `json:"footer,omitempty"`
+	Timestamp string         `json:"timestamp"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+func (d *DiscordNotifier) Notify(ctx context.Context, alert Alert) error {
+	embed := discordEmbed{
+		Title:     truncate(alert.Title(), 256),
+		Color:     statusColor(alert.NewStatus),
+		Timestamp: alert.Time.UTC().Format(time.RFC3339),
+	}
+	// Discord rejects empty footers, which group alerts would have
+	if alert.EndpointID != "" {
+		embed.Footer = &discordFooter{Text: alert.EndpointID}
+	}
+	if u, err := url.Parse(alert.URL); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		embed.URL = alert.URL
+	}
+	for _, f := range alertFields(alert) {
+		embed.Fields = append(embed.Fields, discordField{
+			Name:   f.Name,
+			Value:  truncate(f.Value, 1024),
+			Inline: f.Name != "Messages" && f.Name != "Error",
+		})
+	}
+	msg := discordMessage{Username: d.cfg.Username, Embeds: []discordEmbed{embed}}
+
+	// wait=true makes Discord return the created message, needed to follow up in its thread
+	target := *d.url
+	query := target.Query()
+	query.Set("wait", "true")
+	if d.cfg.Forum {
+		if thread, ok := d.threads.get(alert); ok {
+			query.Set("thread_id", thread)
+		} else {
+			msg.ThreadName = truncate(alert.Title(), 100)
+		}
+	}
+	target.RawQuery = query.Encode()
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp struct {
+		ID        string `json:"id"`
+		ChannelID string `json:"channel_id"`
+	}
+	if err := send(d.client, req, &resp); err != nil {
+		return err
+	}
+	// A forum post is a thread whose ID is the channel the message landed in
+	if d.cfg.Forum && msg.ThreadName != "" {
+		d.threads.set(alert, resp.ChannelID)
+	}
+	return nil
+}
+
+// truncate shortens s to at most n runes, platforms reject longer fields.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,88 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestDiscordNotifier_Embed(t *testing.T) {
+	srv, requests := newCaptureServer(t, http.StatusOK)
+
+	d, err := NewDiscordNotifier(DiscordConfig{Name: "discord", WebhookURL: srv.URL + "/api/webhooks/1/token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The capture server answers with a non JSON body
+	d.Notify(context.Background(), downAlert("api"))
+
+	var msg discordMessage
+	body := (<-requests).body
+	if err := json.Unmarshal(body, &msg); err != nil {
+		t.Fatalf("Expected JSON, got %s", body)
+	}
+	if len(msg.Embeds) != 1 || msg.ThreadName != "" {
+		t.Fatalf("Unexpected message: %s", body)
+	}
+	embed := msg.Embeds[0]
+	if embed.Title != "[DOWN] api (was up)" || embed.Color != 0xE01E5A || embed.URL != "http://localhost" {
+		t.Errorf("Unexpected embed: %+v", embed)
+	}
+	if embed.Fields[1].Name != "Status code" || embed.Fields[1].Value != "503" {
+		t.Errorf("Expected the status code field, got %+v", embed.Fields)
+	}
+}
+
+func TestDiscordNotifier_ForumThreadsRecovery(t *testing.T) {
+	type post struct {
+		query url.Values
+		msg   discordMessage
+	}
+	var posts []post
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg discordMessage
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &msg)
+		posts = append(posts, post{query: r.URL.Query(), msg: msg})
+		w.Write([]byte(`{"id": "111", "channel_id": "999"}`))
+	}))
+	defer srv.Close()
+
+	d, err := NewDiscordNotifier(DiscordConfig{Name: "discord", WebhookURL: srv.URL, Forum: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	down, up := incidentAlerts()
+	if err := d.Notify(context.Background(), down); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Notify(context.Background(), up); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(posts) != 2 {
+		t.Fatalf("Expected 2 posts, got %d", len(posts))
+	}
+	if posts[0].msg.ThreadName == "" || posts[0].query.Get("wait") != "true" {
+		t.Errorf("Expected the alert to open a forum post, got %+v", posts[0])
+	}
+	if posts[1].query.Get("thread_id") != "999" || posts[1].msg.ThreadName != "" {
+		t.Errorf("Expected the recovery in the alert's post, got %+v", posts[1])
+	}
+	if posts[1].msg.Embeds[0].Color != 0x2EB67D {
+		t.Errorf("Expected green for recovery, got %x", posts[1].msg.Embeds[0].Color)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("héllo wörld", 5); got != "héll…" {
+		t.Errorf("Expected rune aware truncation, got %q", got)
+	}
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("Expected short strings untouched, got %q", got)
+	}
+}
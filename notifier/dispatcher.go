@@ -0,0 +1,297 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Dispatcher defaults
+const (
+	DefaultQueueSize  = 100
+	DefaultTimeout    = 10 * time.Second
+	DefaultRetries    = 3
+	DefaultBackoff    = time.Second
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// ErrQueueFull is reported when an alert is dropped because a notifier is too far behind.
+var ErrQueueFull = errors.New("notifier queue full, alert dropped")
+
+// DispatcherOptions tunes delivery. Zero values fall back to the defaults above,
+// except for an explicit retries: 0, which disables re-deliveries.
+type DispatcherOptions struct {
+	QueueSize  int           `mapstructure:"queue_size" json:"queue_size" yaml:"queue_size"` // pending alerts per notifier
+	Timeout    time.Duration `mapstructure:"timeout" json:"timeout" yaml:"timeout"`          // per delivery attempt
+	Retries    *int          `mapstructure:"retries" json:"retries" yaml:"retries"`          // re-deliveries after a failure
+	Backoff    time.Duration `mapstructure:"backoff" json:"backoff" yaml:"backoff"`          // first delay, doubled every retry
+	MaxBackoff time.Duration `mapstructure:"max_backoff" json:"max_backoff" yaml:"max_backoff"`
+	// OnError is called when an alert is dropped (ErrQueueFull) or every attempt failed
+	OnError func(notifier string, alert Alert, err error) `mapstructure:"-" json:"-" yaml:"-"`
+	// OnSent is called once an alert was delivered
+	OnSent func(notifier string, alert Alert) `mapstructure:"-" json:"-" yaml:"-"`
+}
+
+// ValidateDispatcherOptions applies defaults to opts and validates it.
+func ValidateDispatcherOptions(opts *DispatcherOptions) error {
+	if opts.QueueSize < 0 || opts.Timeout < 0 || (opts.Retries != nil && *opts.Retries < 0) || opts.Backoff < 0 || opts.MaxBackoff < 0 {
+		return fmt.Errorf("notification settings must be non-negative")
+	}
+	if opts.QueueSize == 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Retries == nil {
+		retries := DefaultRetries
+		opts.Retries = &retries
+	}
+	if opts.Backoff == 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	if opts.MaxBackoff == 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	if opts.MaxBackoff < opts.Backoff {
+		return fmt.Errorf("max_backoff (%s) must not be lower than backoff (%s)", opts.MaxBackoff, opts.Backoff)
+	}
+	return nil
+}
+
+// Stats are the delivery counters of a single notifier.
+type Stats struct {
+	Sent    uint64 `json:"sent"`    // alerts delivered
+	Failed  uint64 `json:"failed"`  // alerts given up on after every retry
+	Retried uint64 `json:"retried"` // failed delivery attempts that were retried
+	Dropped uint64 `json:"dropped"` // alerts discarded because the queue was full
+}
+
+// Dispatcher fans alerts out to notifiers without ever blocking the caller.
+// Every notifier has its own bounded queue and worker, so alerts reach a notifier
+// in order and a slow one cannot hold back the others.
+type Dispatcher struct {
+	opts    DispatcherOptions
+	workers []*worker
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+type worker struct {
+	notifier Notifier
+	queue    chan Alert
+
+	sent    atomic.Uint64
+	failed  atomic.Uint64
+	retried atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// NewDispatcher starts a worker for every notifier. opts must have been validated.
+func NewDispatcher(notifiers []Notifier, opts DispatcherOptions) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		opts:   opts,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	for _, n := range notifiers {
+		w := &worker{
+			notifier: n,
+			queue:    make(chan Alert, opts.QueueSize),
+		}
+		d.workers = append(d.workers, w)
+
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.run(w)
+		}()
+	}
+	return d
+}
+
+// Dispatch queues alert for every notifier. It never blocks: when a notifier's
+// queue is full the alert is dropped for that notifier and counted.
+func (d *Dispatcher) Dispatch(alert Alert) {
+	d.dispatch(alert, nil)
+}
+
+// DispatchTo is Dispatch limited to the named notifiers.
+func (d *Dispatcher) DispatchTo(alert Alert, notifiers []string) {
+	d.dispatch(alert, notifiers)
+}
+
+// dispatch queues alert for the named notifiers, or every notifier if names is nil.
+func (d *Dispatcher) dispatch(alert Alert, names []string) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+
+	for _, w := range d.workers {
+		if names != nil && !slices.Contains(names, w.notifier.Name()) {
+			continue
+		}
+		select {
+		case w.queue <- alert:
+		default:
+			w.dropped.Add(1)
+			d.report(w, alert, ErrQueueFull)
+		}
+	}
+}
+
+// Stats returns the delivery counters of every notifier, keyed by name.
+func (d *Dispatcher) Stats() map[string]Stats {
+	stats := make(map[string]Stats, len(d.workers))
+	for _, w := range d.workers {
+		stats[w.notifier.Name()] = Stats{
+			Sent:    w.sent.Load(),
+			Failed:  w.failed.Load(),
+			Retried: w.retried.Load(),
+			Dropped: w.dropped.Load(),
+		}
+	}
+	return stats
+}
+
+// Close stops accepting alerts and waits up to timeout for queued ones to be
+// delivered. Deliveries still running after timeout are cancelled.
+func (d *Dispatcher) Close(timeout time.Duration) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	for _, w := range d.workers {
+		close(w.queue)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-time.After(timeout):
+		d.cancel()
+		<-done
+		return fmt.Errorf("notifications still pending after %s were abandoned", timeout)
+	}
+}
+
+func (d *Dispatcher) run(w *worker) {
+	if b, ok := w.notifier.(Batcher); ok && b.BatchWindow() > 0 {
+		d.runBatched(w, b)
+		return
+	}
+
+	for alert := range w.queue {
+		d.deliver(w, []Alert{alert}, func(ctx context.Context) error {
+			err := w.notifier.Notify(ctx, alert)
+			// Retries of a group alert only send what was not delivered
+			var partial *PartialError
+			if errors.As(err, &partial) && len(alert.Group) > 0 {
+				alert.Group = partial.Failed
+			}
+			return err
+		})
+	}
+}
+
+// runBatched waits for an alert, collects every alert queued within the batch
+// window and delivers them in one call.
+func (d *Dispatcher) runBatched(w *worker, b Batcher) {
+	for first := range w.queue {
+		batch := []Alert{first}
+		timer := time.NewTimer(b.BatchWindow())
+
+	collect:
+		for {
+			select {
+			case alert, ok := <-w.queue:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, alert)
+			case <-timer.C:
+				break collect
+			case <-d.ctx.Done():
+				break collect
+			}
+		}
+		timer.Stop()
+
+		d.deliver(w, batch, func(ctx context.Context) error {
+			return b.NotifyBatch(ctx, batch)
+		})
+	}
+}
+
+// deliver calls notify, retrying with exponential backoff, and records the outcome.
+func (d *Dispatcher) deliver(w *worker, alerts []Alert, notify func(ctx context.Context) error) {
+	n := uint64(len(alerts))
+	if d.ctx.Err() != nil {
+		w.failed.Add(n)
+		return
+	}
+
+	backoff := d.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(d.ctx, d.opts.Timeout)
+		err := notify(ctx)
+		cancel()
+		if err == nil {
+			w.sent.Add(n)
+			if d.opts.OnSent != nil {
+				for _, alert := range alerts {
+					d.opts.OnSent(w.notifier.Name(), alert)
+				}
+			}
+			return
+		}
+
+		if attempt >= *d.opts.Retries {
+			err = fmt.Errorf("after %d attempts: %w", attempt+1, err)
+		} else {
+			w.retried.Add(1)
+			select {
+			case <-time.After(backoff):
+				backoff = min(backoff*2, d.opts.MaxBackoff)
+				continue
+			case <-d.ctx.Done():
+				err = fmt.Errorf("cancelled while retrying: %w", err)
+			}
+		}
+
+		w.failed.Add(n)
+		for _, alert := range alerts {
+			d.report(w, alert, err)
+		}
+		return
+	}
+}
+
+func (d *Dispatcher) report(w *worker, alert Alert, err error) {
+	if d.opts.OnError != nil {
+		d.opts.OnError(w.notifier.Name(), alert, err)
+	}
+}
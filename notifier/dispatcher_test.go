@@ -0,0 +1,227 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeNotifier struct {
+	name  string
+	fails int // number of calls failing before success
+	delay time.Duration
+
+	mu     sync.Mutex
+	calls  int
+	alerts []Alert
+}
+
+func (f *fakeNotifier) Name() string { return f.name }
+
+func (f *fakeNotifier) Notify(ctx context.Context, alert Alert) error {
+	f.mu.Lock()
+	f.calls++
+	call := f.calls
+	f.mu.Unlock()
+
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if call <= f.fails {
+		return errors.New("boom")
+	}
+
+	f.mu.Lock()
+	f.alerts = append(f.alerts, alert)
+	f.mu.Unlock()
+	return nil
+}
+
+func testOptions() DispatcherOptions {
+	retries := 2
+	opts := DispatcherOptions{
+		Timeout:    time.Second,
+		Retries:    &retries,
+		Backoff:    time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+	}
+	ValidateDispatcherOptions(&opts)
+	return opts
+}
+
+func TestDispatcher_DeliversInOrder(t *testing.T) {
+	n := &fakeNotifier{name: "fake"}
+	d := NewDispatcher([]Notifier{n}, testOptions())
+
+	for i := range 10 {
+		d.Dispatch(Alert{EndpointID: "a", IncidentID: int64(i)})
+	}
+	if err := d.Close(time.Second); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if len(n.alerts) != 10 {
+		t.Fatalf("Expected 10 alerts delivered, got %d", len(n.alerts))
+	}
+	for i, alert := range n.alerts {
+		if alert.IncidentID != int64(i) {
+			t.Errorf("Expected alert %d in order, got %d", i, alert.IncidentID)
+		}
+	}
+	if stats := d.Stats()["fake"]; stats.Sent != 10 || stats.Failed != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestDispatcher_DispatchTo(t *testing.T) {
+	a, b := &fakeNotifier{name: "a"}, &fakeNotifier{name: "b"}
+	d := NewDispatcher([]Notifier{a, b}, testOptions())
+
+	d.DispatchTo(Alert{EndpointID: "x"}, []string{"b"})
+	d.Dispatch(Alert{EndpointID: "y"})
+	if err := d.Close(time.Second); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if len(a.alerts) != 1 || a.alerts[0].EndpointID != "y" {
+		t.Errorf("Expected a to only get the alert sent to everyone, got %+v", a.alerts)
+	}
+	if len(b.alerts) != 2 {
+		t.Errorf("Expected b to get both alerts, got %+v", b.alerts)
+	}
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	flaky := &fakeNotifier{name: "flaky", fails: 2}
+	broken := &fakeNotifier{name: "broken", fails: 100}
+
+	var reported, sent atomic.Int32
+	opts := testOptions()
+	opts.OnError = func(name string, _ Alert, err error) {
+		if name == "broken" && err != nil {
+			reported.Add(1)
+		}
+	}
+	opts.OnSent = func(name string, _ Alert) {
+		if name == "flaky" {
+			sent.Add(1)
+		}
+	}
+
+	d := NewDispatcher([]Notifier{flaky, broken}, opts)
+	d.Dispatch(Alert{EndpointID: "a"})
+	d.Close(time.Second)
+
+	stats := d.Stats()
+	if s := stats["flaky"]; s.Sent != 1 || s.Retried != 2 || s.Failed != 0 {
+		t.Errorf("Expected flaky notifier to succeed on the third attempt, got %+v", s)
+	}
+	if s := stats["broken"]; s.Sent != 0 || s.Retried != 2 || s.Failed != 1 {
+		t.Errorf("Expected broken notifier to give up after 3 attempts, got %+v", s)
+	}
+	if broken.calls != 3 {
+		t.Errorf("Expected 3 calls to the broken notifier, got %d", broken.calls)
+	}
+	if reported.Load() != 1 {
+		t.Errorf("Expected the failure to be reported once, got %d", reported.Load())
+	}
+	if sent.Load() != 1 {
+		t.Errorf("Expected the delivery to be reported once, got %d", sent.Load())
+	}
+}
+
+func TestDispatcher_Timeout(t *testing.T) {
+	slow := &fakeNotifier{name: "slow", delay: time.Hour}
+	opts := testOptions()
+	opts.Timeout = 10 * time.Millisecond
+	opts.Retries = new(int)
+
+	d := NewDispatcher([]Notifier{slow}, opts)
+	d.Dispatch(Alert{})
+	d.Close(time.Second)
+
+	if s := d.Stats()["slow"]; s.Failed != 1 {
+		t.Errorf("Expected the slow delivery to time out, got %+v", s)
+	}
+}
+
+func TestDispatcher_NeverBlocks(t *testing.T) {
+	slow := &fakeNotifier{name: "slow", delay: time.Hour}
+	fast := &fakeNotifier{name: "fast"}
+	opts := testOptions()
+	opts.QueueSize = 2
+
+	d := NewDispatcher([]Notifier{slow, fast}, opts)
+
+	start := time.Now()
+	for range 10 {
+		d.Dispatch(Alert{})
+		time.Sleep(time.Millisecond) // let the fast worker keep up
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected Dispatch not to block, took %s", elapsed)
+	}
+
+	// Let the fast worker drain before closing
+	time.Sleep(50 * time.Millisecond)
+	if err := d.Close(50 * time.Millisecond); err == nil {
+		t.Errorf("Expected close to report abandoned deliveries")
+	}
+
+	stats := d.Stats()
+	// One alert in flight plus a full queue, the rest dropped
+	if s := stats["slow"]; s.Dropped != 7 {
+		t.Errorf("Expected 7 alerts dropped for the slow notifier, got %+v", s)
+	}
+	if s := stats["fast"]; s.Sent != 10 || s.Dropped != 0 {
+		t.Errorf("Expected the fast notifier to be unaffected, got %+v", s)
+	}
+}
+
+func TestDispatcher_DispatchAfterClose(t *testing.T) {
+	n := &fakeNotifier{name: "fake"}
+	d := NewDispatcher([]Notifier{n}, testOptions())
+	d.Close(time.Second)
+
+	d.Dispatch(Alert{}) // must not panic
+	if err := d.Close(time.Second); err != nil {
+		t.Errorf("Expected a second close to be a no-op, got %v", err)
+	}
+}
+
+func TestValidateDispatcherOptions(t *testing.T) {
+	var opts DispatcherOptions
+	if err := ValidateDispatcherOptions(&opts); err != nil {
+		t.Fatalf("Expected defaults to be valid, got %v", err)
+	}
+	if opts.QueueSize != DefaultQueueSize || opts.Timeout != DefaultTimeout {
+		t.Errorf("Expected defaults to be applied, got %+v", opts)
+	}
+	if opts.Retries == nil || *opts.Retries != DefaultRetries {
+		t.Errorf("Expected %d retries by default, got %v", DefaultRetries, opts.Retries)
+	}
+	// An explicit 0 disables retries
+	none := DispatcherOptions{Retries: new(int)}
+	if err := ValidateDispatcherOptions(&none); err != nil || *none.Retries != 0 {
+		t.Errorf("Expected retries: 0 to be kept, got %d (%v)", *none.Retries, err)
+	}
+
+	negative := -1
+	invalid := []DispatcherOptions{
+		{QueueSize: -1},
+		{Retries: &negative},
+		{Backoff: time.Minute, MaxBackoff: time.Second},
+	}
+	for _, opts := range invalid {
+		if err := ValidateDispatcherOptions(&opts); err == nil {
+			t.Errorf("Expected %+v to be invalid", opts)
+		}
+	}
+}
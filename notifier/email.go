@@ -0,0 +1,434 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// Email TLS modes
+const (
+	EmailTLSStartTLS = "starttls" // upgrade a plain connection, fail if the server can't
+	EmailTLSImplicit = "tls"      // TLS from the first byte (SMTPS, usually port 465)
+	EmailTLSNone     = "none"     // plain text, only sensible for a local relay
+)
+
+// EmailConfig configures an SMTP notifier.
+type EmailConfig struct {
+	Name     string `mapstructure:"name" json:"name" yaml:"name"`
+	Host     string `mapstructure:"host" json:"host" yaml:"host"`
+	Port     int    `mapstructure:"port" json:"port" yaml:"port"` // 587 for starttls, 465 for tls, 25 for none by default
+	TLS      string `mapstructure:"tls" json:"tls" yaml:"tls"`    // starttls (default), tls or none
+	Username string `mapstructure:"username" json:"username,omitempty" yaml:"username,omitempty"`
+	// Password is expanded from the environment ($VAR or ${VAR})
+	Password string       `mapstructure:"password" json:"-" yaml:"-"`
+	From     string       `mapstructure:"from" json:"from" yaml:"from"`
+	To       []string     `mapstructure:"to" json:"to" yaml:"to"` // recipients when no route matches
+	Routes   []EmailRoute `mapstructure:"routes" json:"routes,omitempty" yaml:"routes,omitempty"`
+	// Subject and Text are text/templates, HTML an html/template, all rendered with EmailData
+	Subject string `mapstructure:"subject" json:"subject,omitempty" yaml:"subject,omitempty"`
+	Text    string `mapstructure:"text" json:"text,omitempty" yaml:"text,omitempty"`
+	HTML    string `mapstructure:"html" json:"html,omitempty" yaml:"html,omitempty"`
+	// BatchWindow groups alerts arriving within this long of the first into one summary email
+	BatchWindow time.Duration `mapstructure:"batch_window" json:"batch_window" yaml:"batch_window"`
+}
+
+// EmailRoute sends alerts of some endpoints to their own recipients.
+type EmailRoute struct {
+	Endpoints []string `mapstructure:"endpoints" json:"endpoints" yaml:"endpoints"` // endpoint IDs
+	To        []string `mapstructure:"to" json:"to" yaml:"to"`
+}
+
+// EmailData is what email templates are rendered with: one alert, or all the
+// alerts of a batch going to the same recipients.
+type EmailData struct {
+	Alerts []Alert
+}
+
+// Alert returns the first alert, handy for single alert emails.
+func (d EmailData) Alert() Alert {
+	return d.Alerts[0]
+}
+
+// Failing counts the alerts reporting an outage.
+func (d EmailData) Failing() int {
+	n := 0
+	for _, a := range d.Alerts {
+		if common.IsFailing(a.NewStatus) {
+			n++
+		}
+	}
+	return n
+}
+
+// Recovered counts the alerts closing an outage.
+func (d EmailData) Recovered() int {
+	n := 0
+	for _, a := range d.Alerts {
+		if a.Recovered() {
+			n++
+		}
+	}
+	return n
+}
+
+const defaultEmailSubject = `{{ if eq (len .Alerts) 1 }}[pulse] {{ .Alert.Title }}` +
+	`{{ else }}[pulse] {{ len .Alerts }} alerts: {{ .Failing }} failing, {{ .Recovered }} recovered{{ end }}`
+
+const defaultEmailText = `{{ range .Alerts -}}
+{{ .Title }}
+  URL:         {{ .URL }}
+  Status code: {{ .Result.StatusCode }}
+  Latency:     {{ .Result.Elapsed }}ms
+{{- if .Result.Error }}
+  Error:       {{ .Result.Error }}
+{{- end }}
+{{- if .Result.Messages }}
+  Messages:    {{ join .Result.Messages ", " }}
+{{- end }}
+{{- if .Duration }}
+  Down for:    {{ .Duration }}
+{{- end }}
+  At:          {{ .Time.Format "2006-01-02 15:04:05 MST" }}
+
+{{ end -}}
+`
+
+const defaultEmailHTML = `<html><body style="font-family: sans-serif">
+<table cellpadding="6" style="border-collapse: collapse">
+<tr><th align="left">Endpoint</th><th align="left">Status</th><th align="left">Code</th><th align="left">Latency</th><th align="left">Details</th></tr>
+{{ range .Alerts -}}
+<tr style="border-top: 1px solid #ddd">
+<td><b>{{ if .EndpointName }}{{ .EndpointName }}{{ else }}{{ .EndpointID }}{{ end }}</b><br><small>{{ .URL }}</small></td>
+<td>{{ upper .NewStatus }}{{ if .OldStatus }} <small>(was {{ .OldStatus }})</small>{{ end }}</td>
+<td>{{ .Result.StatusCode }}</td>
+<td>{{ .Result.Elapsed }}ms</td>
+<td>{{ .Result.Error }}{{ range .Result.Messages }}<br>{{ . }}{{ end }}{{ if .Duration }}<br>Down for {{ .Duration }}{{ end }}</td>
+</tr>
+{{ end -}}
+</table>
+</body></html>
+`
+
+// EmailNotifier sends alerts over SMTP as multipart text and HTML emails.
+type EmailNotifier struct {
+	cfg       EmailConfig
+	password  string
+	tlsConfig *tls.Config
+	subject   *template.Template
+	text      *template.Template
+	html      *htmltemplate.Template
+}
+
+// NewEmailNotifier validates cfg and compiles its templates.
+func NewEmailNotifier(cfg EmailConfig) (*EmailNotifier, error) {
+	return NewEmailNotifierWithTLSConfig(cfg, nil)
+}
+
+// NewEmailNotifierWithTLSConfig is NewEmailNotifier with a custom TLS config (e.g. private roots).
+func NewEmailNotifierWithTLSConfig(cfg EmailConfig, tlsConfig *tls.Config) (*EmailNotifier, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("email %q: host is required", cfg.Name)
+	}
+	cfg.TLS = strings.ToLower(cfg.TLS)
+	if cfg.TLS == "" {
+		cfg.TLS = EmailTLSStartTLS
+	}
+	if cfg.Port == 0 {
+		switch cfg.TLS {
+		case EmailTLSImplicit:
+			cfg.Port = 465
+		case EmailTLSNone:
+			cfg.Port = 25
+		default:
+			cfg.Port = 587
+		}
+	}
+	switch cfg.TLS {
+	case EmailTLSStartTLS, EmailTLSImplicit, EmailTLSNone:
+	default:
+		return nil, fmt.Errorf("email %q: invalid tls mode %q (use starttls, tls or none)", cfg.Name, cfg.TLS)
+	}
+	if cfg.BatchWindow < 0 {
+		return nil, fmt.Errorf("email %q: batch_window must be non-negative", cfg.Name)
+	}
+
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("email %q: invalid from address %q: %w", cfg.Name, cfg.From, err)
+	}
+	if len(cfg.To) == 0 && len(cfg.Routes) == 0 {
+		return nil, fmt.Errorf("email %q: at least one recipient is required", cfg.Name)
+	}
+	recipients := slices.Clone(cfg.To)
+	for i, route := range cfg.Routes {
+		if len(route.To) == 0 {
+			return nil, fmt.Errorf("email %q: route %d has no recipients", cfg.Name, i)
+		}
+		recipients = append(recipients, route.To...)
+	}
+	for _, addr := range recipients {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return nil, fmt.Errorf("email %q: invalid recipient %q: %w", cfg.Name, addr, err)
+		}
+	}
+
+	if cfg.Subject == "" {
+		cfg.Subject = defaultEmailSubject
+	}
+	if cfg.Text == "" {
+		cfg.Text = defaultEmailText
+	}
+	if cfg.HTML == "" {
+		cfg.HTML = defaultEmailHTML
+	}
+
+	n := &EmailNotifier{
+		cfg:       cfg,
+		password:  os.ExpandEnv(cfg.Password),
+		tlsConfig: tlsConfig,
+	}
+	var err error
+	if n.subject, err = parseTemplate("subject", cfg.Subject); err != nil {
+		return nil, fmt.Errorf("email %q: invalid subject template: %w", cfg.Name, err)
+	}
+	if n.text, err = parseTemplate("text", cfg.Text); err != nil {
+		return nil, fmt.Errorf("email %q: invalid text template: %w", cfg.Name, err)
+	}
+	n.html, err = htmltemplate.New("html").Funcs(htmltemplate.FuncMap(TemplateFuncs)).Option("missingkey=error").Parse(cfg.HTML)
+	if err != nil {
+		return nil, fmt.Errorf("email %q: invalid html template: %w", cfg.Name, err)
+	}
+	// Render a sample so template errors surface at startup, not during an outage
+	if _, err := n.message(cfg.To, EmailData{Alerts: []Alert{sampleAlert()}}); err != nil {
+		return nil, fmt.Errorf("email %q: %w", cfg.Name, err)
+	}
+
+	return n, nil
+}
+
+func (n *EmailNotifier) Name() string {
+	return n.cfg.Name
+}
+
+func (n *EmailNotifier) BatchWindow() time.Duration {
+	return n.cfg.BatchWindow
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, alert Alert) error {
+	return n.NotifyBatch(ctx, []Alert{alert})
+}
+
+// NotifyBatch sends one email per distinct recipient list. Group alerts are
+// listed alert by alert.
+func (n *EmailNotifier) NotifyBatch(ctx context.Context, alerts []Alert) error {
+	alerts = ungroup(alerts)
+	type group struct {
+		to     []string
+		alerts []Alert
+	}
+	var groups []*group
+	byRecipients := make(map[string]*group)
+	for _, alert := range alerts {
+		to := n.recipients(alert.EndpointID)
+		key := strings.Join(to, ",")
+		g, ok := byRecipients[key]
+		if !ok {
+			g = &group{to: to}
+			byRecipients[key] = g
+			groups = append(groups, g)
+		}
+		g.alerts = append(g.alerts, alert)
+	}
+
+	for _, g := range groups {
+		msg, err := n.message(g.to, EmailData{Alerts: g.alerts})
+		if err != nil {
+			return err
+		}
+		if err := n.send(ctx, g.to, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recipients returns the sorted recipients of an endpoint: those of every
+// matching route, or the default ones when no route matches.
+func (n *EmailNotifier) recipients(endpointID string) []string {
+	var to []string
+	for _, route := range n.cfg.Routes {
+		if slices.Contains(route.Endpoints, endpointID) {
+			to = append(to, route.To...)
+		}
+	}
+	if len(to) == 0 {
+		to = slices.Clone(n.cfg.To)
+	}
+	slices.Sort(to)
+	return slices.Compact(to)
+}
+
+// message builds a multipart/alternative email.
+func (n *EmailNotifier) message(to []string, data EmailData) ([]byte, error) {
+	var subject, text, html bytes.Buffer
+	if err := n.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("rendering subject: %w", err)
+	}
+	if err := n.text.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("rendering text body: %w", err)
+	}
+	if err := n.html.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("rendering html body: %w", err)
+	}
+
+	var msg bytes.Buffer
+	body := multipart.NewWriter(&msg)
+
+	headers := [][2]string{
+		{"From", n.cfg.From},
+		{"To", strings.Join(to, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject.String()))},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID(n.cfg.From)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + body.Boundary()},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&msg, "%s: %s\r\n", h[0], h[1])
+	}
+	msg.WriteString("\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		w, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write(part.content); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
+
+// send delivers msg over SMTP, bounded by ctx.
+func (n *EmailNotifier) send(ctx context.Context, to []string, msg []byte) error {
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	tlsConfig := n.tlsConfig.Clone()
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = n.cfg.Host
+	}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if n.cfg.TLS == EmailTLSImplicit {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("smtp: connecting to %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	// net/smtp has no context support: abort blocked reads and writes when ctx ends
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	defer client.Close()
+
+	if n.cfg.TLS == EmailTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp: %s does not support STARTTLS", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("smtp: starttls: %w", err)
+		}
+	}
+	if n.cfg.Username != "" {
+		auth := smtp.PlainAuth("", n.cfg.Username, n.password, n.cfg.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp: auth: %w", err)
+		}
+	}
+
+	from, _ := mail.ParseAddress(n.cfg.From)
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("smtp: mail from: %w", err)
+	}
+	for _, rcpt := range to {
+		addr, _ := mail.ParseAddress(rcpt)
+		if err := client.Rcpt(addr.Address); err != nil {
+			return fmt.Errorf("smtp: rcpt to %s: %w", addr.Address, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp: data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("smtp: writing message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	return client.Quit()
+}
+
+func messageID(from string) string {
+	domain := "pulse.local"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(addr.Address, "@"); at >= 0 {
+			domain = addr.Address[at+1:]
+		}
+	}
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
@@ -0,0 +1,303 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+	"github.com/mohamedbeat/pulse/tlschecker"
+)
+
+func newTestCA(t *testing.T) (*tlschecker.MockCA, *tls.Config) {
+	t.Helper()
+
+	ca, err := tlschecker.NewMockCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := ca.Issue(time.Now().Add(24 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ca, &tls.Config{Certificates: []tls.Certificate{cert}}
+}
+
+func emailConfig(t *testing.T, srv *MockSMTPServer, mode string) EmailConfig {
+	t.Helper()
+
+	host, port, _ := net.SplitHostPort(srv.Addr)
+	p, _ := strconv.Atoi(port)
+	return EmailConfig{
+		Name: "mail",
+		Host: host,
+		Port: p,
+		TLS:  mode,
+		From: "Pulse <pulse@example.com>",
+		To:   []string{"oncall@example.com"},
+	}
+}
+
+// parseMail returns the subject and the text and HTML parts of a message
+func parseMail(t *testing.T, data string) (subject, text, html string) {
+	t.Helper()
+
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("parsing message: %v", err)
+	}
+	subject, err = new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Expected multipart/alternative, got %q (%v)", mediaType, err)
+	}
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(part) // quoted-printable is decoded by the reader
+		switch {
+		case strings.HasPrefix(part.Header.Get("Content-Type"), "text/plain"):
+			text = string(body)
+		case strings.HasPrefix(part.Header.Get("Content-Type"), "text/html"):
+			html = string(body)
+		}
+	}
+	return subject, text, html
+}
+
+func downAlert(id string) Alert {
+	alert := sampleAlert()
+	alert.EndpointID = id
+	alert.EndpointName = id
+	alert.Result.StatusCode = 503
+	alert.Result.Elapsed = 42
+	alert.Result.Error = "<b>boom</b>"
+	return alert
+}
+
+func TestEmailNotifier_StartTLSWithAuth(t *testing.T) {
+	ca, serverTLS := newTestCA(t)
+	srv, err := NewMockSMTPServer(serverTLS, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	srv.RequireAuth("pulse", "hunter2")
+	t.Setenv("PULSE_TEST_SMTP_PASSWORD", "hunter2")
+
+	cfg := emailConfig(t, srv, EmailTLSStartTLS)
+	cfg.Username = "pulse"
+	cfg.Password = "$PULSE_TEST_SMTP_PASSWORD"
+	n, err := NewEmailNotifierWithTLSConfig(cfg, &tls.Config{RootCAs: ca.Pool})
+	if err != nil {
+		t.Fatalf("new email notifier: %v", err)
+	}
+
+	if err := n.Notify(context.Background(), downAlert("api")); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	mails := srv.Mails()
+	if len(mails) != 1 {
+		t.Fatalf("Expected 1 mail, got %d", len(mails))
+	}
+	m := mails[0]
+	if !m.TLS || m.User != "pulse" || m.From != "pulse@example.com" || len(m.To) != 1 || m.To[0] != "oncall@example.com" {
+		t.Errorf("Unexpected envelope: %+v", m)
+	}
+
+	subject, text, html := parseMail(t, m.Data)
+	if subject != "[pulse] [DOWN] api (was up)" {
+		t.Errorf("Unexpected subject: %q", subject)
+	}
+	for _, want := range []string{"503", "42ms", "<b>boom</b>", common.UnexpectedStatusCodeMessage} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected text body to contain %q, got:\n%s", want, text)
+		}
+	}
+	if !strings.Contains(html, "&lt;b&gt;boom&lt;/b&gt;") {
+		t.Errorf("Expected HTML body to escape the error, got:\n%s", html)
+	}
+}
+
+func TestEmailNotifier_ImplicitTLS(t *testing.T) {
+	ca, serverTLS := newTestCA(t)
+	srv, err := NewMockSMTPServer(serverTLS, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	n, err := NewEmailNotifierWithTLSConfig(emailConfig(t, srv, EmailTLSImplicit), &tls.Config{RootCAs: ca.Pool})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(context.Background(), downAlert("api")); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if mails := srv.Mails(); len(mails) != 1 || !mails[0].TLS {
+		t.Errorf("Expected one mail over TLS, got %+v", mails)
+	}
+}
+
+func TestEmailNotifier_StartTLSUnavailable(t *testing.T) {
+	srv, err := NewMockSMTPServer(nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	n, err := NewEmailNotifier(emailConfig(t, srv, EmailTLSStartTLS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(context.Background(), downAlert("api")); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("Expected a STARTTLS error, got %v", err)
+	}
+	if len(srv.Mails()) != 0 {
+		t.Errorf("Expected nothing to be sent in plain text")
+	}
+}
+
+func TestEmailNotifier_RoutesAndSummary(t *testing.T) {
+	srv, err := NewMockSMTPServer(nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	cfg := emailConfig(t, srv, EmailTLSNone)
+	cfg.Routes = []EmailRoute{
+		{Endpoints: []string{"db"}, To: []string{"dba@example.com"}},
+		{Endpoints: []string{"db", "cache"}, To: []string{"infra@example.com"}},
+	}
+	n, err := NewEmailNotifier(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recovered := downAlert("cache")
+	recovered.OldStatus, recovered.NewStatus = common.StatusDown, common.StatusUp
+	recovered.Duration = 90 * time.Second
+
+	alerts := []Alert{downAlert("api"), downAlert("db"), recovered, downAlert("web")}
+	if err := n.NotifyBatch(context.Background(), alerts); err != nil {
+		t.Fatalf("notify batch: %v", err)
+	}
+
+	mails := srv.Mails()
+	if len(mails) != 3 {
+		t.Fatalf("Expected one mail per recipient list, got %d", len(mails))
+	}
+
+	byTo := make(map[string]MockMail)
+	for _, m := range mails {
+		byTo[strings.Join(m.To, ",")] = m
+	}
+
+	subject, text, _ := parseMail(t, byTo["oncall@example.com"].Data)
+	if subject != "[pulse] 2 alerts: 2 failing, 0 recovered" {
+		t.Errorf("Unexpected summary subject: %q", subject)
+	}
+	if !strings.Contains(text, "api") || !strings.Contains(text, "web") {
+		t.Errorf("Expected both default-routed alerts in the summary, got:\n%s", text)
+	}
+
+	if _, ok := byTo["dba@example.com,infra@example.com"]; !ok {
+		t.Errorf("Expected db alert to go to both routes, got %v", byTo)
+	}
+	subject, text, _ = parseMail(t, byTo["infra@example.com"].Data)
+	if subject != "[pulse] [UP] cache (was down)" || !strings.Contains(text, "Down for:    1m30s") {
+		t.Errorf("Unexpected recovery mail %q:\n%s", subject, text)
+	}
+}
+
+func TestEmailNotifier_BatchedByDispatcher(t *testing.T) {
+	srv, err := NewMockSMTPServer(nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	cfg := emailConfig(t, srv, EmailTLSNone)
+	cfg.BatchWindow = 100 * time.Millisecond
+	n, err := NewEmailNotifier(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDispatcher([]Notifier{n}, testOptions())
+	for _, id := range []string{"a", "b", "c"} {
+		d.Dispatch(downAlert(id))
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if mails := srv.Mails(); len(mails) != 1 {
+		t.Fatalf("Expected alerts within the window to become one mail, got %d", len(mails))
+	}
+
+	// Shutdown flushes a pending batch right away
+	d.Dispatch(downAlert("d"))
+	if err := d.Close(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if mails := srv.Mails(); len(mails) != 2 {
+		t.Errorf("Expected the pending batch to be sent on close, got %d mails", len(mails))
+	}
+	if s := d.Stats()["mail"]; s.Sent != 4 {
+		t.Errorf("Expected 4 alerts counted as sent, got %+v", s)
+	}
+}
+
+func TestNewEmailNotifier_Invalid(t *testing.T) {
+	base := EmailConfig{Host: "smtp.example.com", From: "pulse@example.com", To: []string{"a@example.com"}}
+
+	tests := []struct {
+		name   string
+		modify func(*EmailConfig)
+	}{
+		{"missing host", func(c *EmailConfig) { c.Host = "" }},
+		{"bad tls mode", func(c *EmailConfig) { c.TLS = "ssl3" }},
+		{"bad from", func(c *EmailConfig) { c.From = "nope" }},
+		{"no recipients", func(c *EmailConfig) { c.To = nil }},
+		{"bad recipient", func(c *EmailConfig) { c.To = []string{"nope"} }},
+		{"empty route", func(c *EmailConfig) { c.Routes = []EmailRoute{{Endpoints: []string{"a"}}} }},
+		{"bad subject", func(c *EmailConfig) { c.Subject = "{{ .Nope }}" }},
+		{"bad html", func(c *EmailConfig) { c.HTML = "{{ .Alerts" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := base
+			tt.modify(&cfg)
+			if _, err := NewEmailNotifier(cfg); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+
+	n, err := NewEmailNotifier(base)
+	if err != nil {
+		t.Fatalf("Expected base config to be valid, got %v", err)
+	}
+	if n.cfg.Port != 587 || n.cfg.TLS != EmailTLSStartTLS {
+		t.Errorf("Expected starttls on 587 by default, got %s on %d", n.cfg.TLS, n.cfg.Port)
+	}
+}
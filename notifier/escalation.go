@@ -0,0 +1,236 @@
+package notifier
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// Sender queues alerts for delivery. Dispatcher is the Sender used in production.
+type Sender interface {
+	// Dispatch queues alert for every notifier.
+	Dispatch(alert Alert)
+	// DispatchTo queues alert for the named notifiers only.
+	DispatchTo(alert Alert, notifiers []string)
+}
+
+// EscalationPolicy pages more people the longer an incident stays unacknowledged.
+// The first tier is notified right away; every later tier once After has elapsed
+// since the incident started. Reached tiers are reminded every Repeat.
+type EscalationPolicy struct {
+	Name   string           `mapstructure:"name" json:"name" yaml:"name"`
+	Repeat time.Duration    `mapstructure:"repeat" json:"repeat,omitempty" yaml:"repeat,omitempty"` // 0 sends no reminders
+	Tiers  []EscalationTier `mapstructure:"tiers" json:"tiers" yaml:"tiers"`
+}
+
+// EscalationTier is a set of notifiers reached After the incident started.
+type EscalationTier struct {
+	After     time.Duration `mapstructure:"after" json:"after,omitempty" yaml:"after,omitempty"`
+	Notifiers []string      `mapstructure:"notifiers" json:"notifiers" yaml:"notifiers"`
+}
+
+// ValidateEscalationPolicy checks that p has tiers, the first one immediate and
+// the next ones in increasing order.
+func ValidateEscalationPolicy(p *EscalationPolicy) error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if p.Repeat < 0 {
+		return fmt.Errorf("escalation %q: repeat must be non-negative", p.Name)
+	}
+	if len(p.Tiers) == 0 {
+		return fmt.Errorf("escalation %q: at least one tier is required", p.Name)
+	}
+	for i, tier := range p.Tiers {
+		if len(tier.Notifiers) == 0 {
+			return fmt.Errorf("escalation %q: tier %d has no notifiers", p.Name, i+1)
+		}
+		if i == 0 && tier.After != 0 {
+			return fmt.Errorf("escalation %q: the first tier is notified right away, after must be unset", p.Name)
+		}
+		if i > 0 && tier.After <= p.Tiers[i-1].After {
+			return fmt.Errorf("escalation %q: tier %d must come after tier %d", p.Name, i+1, i)
+		}
+	}
+	return nil
+}
+
+// Escalator sends the alerts of endpoints with an escalation policy to its
+// tiers, escalates and repeats them while their incident is open and
+// unacknowledged. Alerts of other endpoints go to every notifier.
+type Escalator struct {
+	sender   Sender
+	policies map[string]EscalationPolicy
+
+	// Paused, when set, holds back escalations and reminders, e.g. during maintenance
+	Paused func(endpointID string, at time.Time) bool
+
+	mu     sync.Mutex
+	active map[string]*escalation // by endpoint ID
+}
+
+// escalation is the state of an endpoint that is failing under a policy.
+type escalation struct {
+	policy       EscalationPolicy
+	alert        Alert     // last alert of the incident
+	started      time.Time // incident start
+	reached      int       // tiers notified so far
+	lastSent     time.Time
+	acknowledged bool
+}
+
+// NewEscalator returns an Escalator sending through sender. Every notifier a
+// policy names must be in notifiers.
+func NewEscalator(sender Sender, policies []EscalationPolicy, notifiers []string) (*Escalator, error) {
+	e := &Escalator{
+		sender:   sender,
+		policies: make(map[string]EscalationPolicy, len(policies)),
+		active:   make(map[string]*escalation),
+	}
+	for _, p := range policies {
+		for _, tier := range p.Tiers {
+			for _, name := range tier.Notifiers {
+				if !slices.Contains(notifiers, name) {
+					return nil, fmt.Errorf("escalation %q: unknown notifier %q", p.Name, name)
+				}
+			}
+		}
+		e.policies[p.Name] = p
+	}
+	return e, nil
+}
+
+// Dispatch sends alert according to policy, the name of the endpoint's escalation
+// policy (empty for none). A failing alert starts escalating; a recovery is sent
+// to every tier reached and ends the escalation.
+func (e *Escalator) Dispatch(alert Alert, policy string) {
+	p, ok := e.policies[policy]
+	if !ok {
+		e.sender.Dispatch(alert)
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	esc, escalating := e.active[alert.EndpointID]
+	switch {
+	case escalating:
+		esc.alert = alert
+		esc.lastSent = alert.Time
+		e.sendReached(esc, alert)
+		if !common.IsFailing(alert.NewStatus) {
+			delete(e.active, alert.EndpointID)
+		}
+	case common.IsFailing(alert.NewStatus):
+		esc = &escalation{policy: p, alert: alert, started: alert.Time, reached: 1, lastSent: alert.Time}
+		if !alert.StartedAt.IsZero() {
+			esc.started = alert.StartedAt
+		}
+		e.active[alert.EndpointID] = esc
+		e.sendReached(esc, alert)
+	default:
+		// Degraded and other non-incident alerts only go to the first tier
+		e.sender.DispatchTo(alert, p.Tiers[0].Notifiers)
+	}
+}
+
+// Resume picks up the escalation of an incident left open by a previous run.
+// Tiers already due are considered notified, so nothing is re-sent right away.
+func (e *Escalator) Resume(alert Alert, policy string, acknowledged bool, now time.Time) {
+	p, ok := e.policies[policy]
+	if !ok {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	esc := &escalation{policy: p, alert: alert, started: alert.StartedAt, lastSent: now, acknowledged: acknowledged}
+	for _, tier := range p.Tiers {
+		if now.Sub(esc.started) >= tier.After {
+			esc.reached++
+		}
+	}
+	e.active[alert.EndpointID] = esc
+}
+
+// Acknowledge stops escalating and repeating the alerts of an incident.
+// It reports whether the incident was being escalated and had not been acknowledged yet.
+func (e *Escalator) Acknowledge(incidentID int64) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, esc := range e.active {
+		if esc.alert.IncidentID == incidentID && incidentID != 0 {
+			acknowledged := esc.acknowledged
+			esc.acknowledged = true
+			return !acknowledged
+		}
+	}
+	return false
+}
+
+// Forget stops escalating the alerts of an endpoint that is no longer monitored.
+func (e *Escalator) Forget(endpointID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.active, endpointID)
+}
+
+// Tick escalates the incidents whose next tier is due and reminds the reached
+// tiers of the ones unacknowledged for Repeat. Reminders due while an incident is
+// paused are skipped rather than sent once the pause ends.
+func (e *Escalator) Tick(now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for id, esc := range e.active {
+		if esc.acknowledged {
+			continue
+		}
+		if e.Paused != nil && e.Paused(id, now) {
+			if repeat := esc.policy.Repeat; repeat > 0 {
+				esc.lastSent = esc.lastSent.Add(now.Sub(esc.lastSent) / repeat * repeat)
+			}
+			continue
+		}
+
+		alert := esc.alert
+		alert.Time = now
+		alert.Duration = now.Sub(esc.started)
+
+		escalated := false
+		for esc.reached < len(esc.policy.Tiers) && now.Sub(esc.started) >= esc.policy.Tiers[esc.reached].After {
+			tier := esc.policy.Tiers[esc.reached]
+			esc.reached++
+			escalated = true
+			alert.Tier = esc.reached
+			e.sender.DispatchTo(alert, tier.Notifiers)
+		}
+		if escalated {
+			continue
+		}
+
+		if esc.policy.Repeat > 0 && now.Sub(esc.lastSent) >= esc.policy.Repeat {
+			esc.lastSent = now
+			alert.Reminder = true
+			e.sendReached(esc, alert)
+		}
+	}
+}
+
+// sendReached sends alert to every tier notified so far. e.mu must be held.
+func (e *Escalator) sendReached(esc *escalation, alert Alert) {
+	var names []string
+	for _, tier := range esc.policy.Tiers[:esc.reached] {
+		for _, name := range tier.Notifiers {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	e.sender.DispatchTo(alert, names)
+}
@@ -0,0 +1,189 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// fakeSender records where every alert was sent, "*" standing for every notifier.
+type fakeSender struct {
+	sent []string
+}
+
+func (f *fakeSender) Dispatch(alert Alert) {
+	f.sent = append(f.sent, alert.Title()+" -> *")
+}
+
+func (f *fakeSender) DispatchTo(alert Alert, notifiers []string) {
+	f.sent = append(f.sent, alert.Title()+" -> "+strings.Join(notifiers, ","))
+}
+
+func (f *fakeSender) take() []string {
+	sent := f.sent
+	f.sent = nil
+	return sent
+}
+
+var criticalPolicy = EscalationPolicy{
+	Name:   "critical",
+	Repeat: 10 * time.Minute,
+	Tiers: []EscalationTier{
+		{Notifiers: []string{"slack"}},
+		{After: 30 * time.Minute, Notifiers: []string{"pagerduty"}},
+	},
+}
+
+func newTestEscalator(t *testing.T) (*Escalator, *fakeSender) {
+	t.Helper()
+	sender := &fakeSender{}
+	e, err := NewEscalator(sender, []EscalationPolicy{criticalPolicy}, []string{"slack", "pagerduty", "email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e, sender
+}
+
+func expectSent(t *testing.T, sender *fakeSender, want ...string) {
+	t.Helper()
+	if got := sender.take(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestEscalator_RepeatsAndEscalates(t *testing.T) {
+	e, sender := newTestEscalator(t)
+	down, up := incidentAlerts()
+	start := down.Time
+
+	e.Dispatch(down, "critical")
+	expectSent(t, sender, "[DOWN] api (was up) -> slack")
+
+	e.Tick(start.Add(5 * time.Minute))
+	expectSent(t, sender)
+
+	e.Tick(start.Add(10 * time.Minute))
+	expectSent(t, sender, "[DOWN] api (still down after 10m0s) -> slack")
+
+	e.Tick(start.Add(30 * time.Minute))
+	expectSent(t, sender, "[DOWN] api (escalated, down for 30m0s) -> pagerduty")
+
+	e.Tick(start.Add(40 * time.Minute))
+	expectSent(t, sender, "[DOWN] api (still down after 40m0s) -> slack,pagerduty")
+
+	up.Time = start.Add(45 * time.Minute)
+	e.Dispatch(up, "critical")
+	expectSent(t, sender, "[UP] api (was down) -> slack,pagerduty")
+
+	e.Tick(start.Add(time.Hour))
+	expectSent(t, sender)
+}
+
+func TestEscalator_Acknowledge(t *testing.T) {
+	e, sender := newTestEscalator(t)
+	down, up := incidentAlerts()
+	start := down.Time
+
+	e.Dispatch(down, "critical")
+	sender.take()
+
+	if e.Acknowledge(42) {
+		t.Errorf("Expected an unknown incident not to be acknowledged")
+	}
+	if !e.Acknowledge(down.IncidentID) {
+		t.Fatalf("Expected incident %d to be acknowledged", down.IncidentID)
+	}
+	if e.Acknowledge(down.IncidentID) {
+		t.Errorf("Expected a second acknowledgement to change nothing")
+	}
+
+	// Neither reminders nor escalation once acknowledged, the recovery is still sent
+	e.Tick(start.Add(time.Hour))
+	expectSent(t, sender)
+	e.Dispatch(up, "critical")
+	expectSent(t, sender, "[UP] api (was down) -> slack")
+}
+
+func TestEscalator_Forget(t *testing.T) {
+	e, sender := newTestEscalator(t)
+	down, _ := incidentAlerts()
+
+	e.Dispatch(down, "critical")
+	sender.take()
+	e.Forget(down.EndpointID)
+
+	e.Tick(down.Time.Add(time.Hour))
+	expectSent(t, sender)
+}
+
+func TestEscalator_WithoutPolicy(t *testing.T) {
+	e, sender := newTestEscalator(t)
+	down, _ := incidentAlerts()
+
+	e.Dispatch(down, "")
+	expectSent(t, sender, "[DOWN] api (was up) -> *")
+
+	degraded := down
+	degraded.NewStatus = common.StatusDegraded
+	e.Dispatch(degraded, "critical")
+	expectSent(t, sender, "[DEGRADED] api (was up) -> slack")
+
+	e.Tick(down.Time.Add(time.Hour))
+	expectSent(t, sender)
+}
+
+func TestEscalator_ResumeAndPause(t *testing.T) {
+	e, sender := newTestEscalator(t)
+	down, _ := incidentAlerts()
+	now := down.Time.Add(35 * time.Minute)
+	down.StartedAt = down.Time
+
+	// Both tiers were due before the restart: nothing is re-sent until the next reminder
+	e.Resume(down, "critical", false, now)
+	e.Tick(now)
+	expectSent(t, sender)
+
+	// The reminder due while paused is skipped, the next one stays on schedule
+	paused := true
+	e.Paused = func(string, time.Time) bool { return paused }
+	e.Tick(now.Add(15 * time.Minute))
+	expectSent(t, sender)
+
+	paused = false
+	e.Tick(now.Add(15 * time.Minute))
+	expectSent(t, sender)
+	e.Tick(now.Add(20 * time.Minute))
+	expectSent(t, sender, "[DOWN] api (still down after 55m0s) -> slack,pagerduty")
+}
+
+func TestValidateEscalationPolicy(t *testing.T) {
+	tier := EscalationTier{Notifiers: []string{"slack"}}
+	tests := []struct {
+		name    string
+		policy  EscalationPolicy
+		wantErr bool
+	}{
+		{"valid", criticalPolicy, false},
+		{"no name", EscalationPolicy{Tiers: []EscalationTier{tier}}, true},
+		{"no tiers", EscalationPolicy{Name: "p"}, true},
+		{"empty tier", EscalationPolicy{Name: "p", Tiers: []EscalationTier{{}}}, true},
+		{"delayed first tier", EscalationPolicy{Name: "p", Tiers: []EscalationTier{{After: time.Minute, Notifiers: []string{"slack"}}}}, true},
+		{"unordered tiers", EscalationPolicy{Name: "p", Tiers: []EscalationTier{tier, tier}}, true},
+		{"negative repeat", EscalationPolicy{Name: "p", Repeat: -time.Minute, Tiers: []EscalationTier{tier}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateEscalationPolicy(&tt.policy); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewEscalator_UnknownNotifier(t *testing.T) {
+	if _, err := NewEscalator(&fakeSender{}, []EscalationPolicy{criticalPolicy}, []string{"slack"}); err == nil {
+		t.Errorf("Expected an unknown notifier error")
+	}
+}
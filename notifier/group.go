@@ -0,0 +1,217 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// groupTick is how often Run flushes the groups that are due.
+const groupTick = time.Second
+
+// alertGroup holds the alerts of a grouping route sharing the values of its
+// group_by labels until they are sent.
+type alertGroup struct {
+	route   *Route
+	labels  map[string]string // values of the group_by labels
+	pending []Alert
+	next    time.Time // when pending alerts are sent
+}
+
+// grouped reports whether r holds its alerts back to send them in groups.
+func (r *Route) grouped() bool {
+	return r.Digest > 0 || r.GroupWait > 0
+}
+
+// firstFlush returns when a group created at t is first sent.
+func (r *Route) firstFlush(t time.Time) time.Time {
+	if r.Digest > 0 {
+		return t.Truncate(r.Digest).Add(r.Digest)
+	}
+	return t.Add(r.GroupWait)
+}
+
+// nextFlush returns when a group sent at t is sent again.
+func (r *Route) nextFlush(t time.Time) time.Time {
+	if r.Digest > 0 {
+		return t.Truncate(r.Digest).Add(r.Digest)
+	}
+	return t.Add(r.GroupInterval)
+}
+
+// group adds alert to its group on route.
+func (r *Router) group(route *Route, alert Alert) {
+	labels := make(map[string]string, len(route.GroupBy))
+	all := RouteLabels(alert)
+	key := route.path
+	for _, name := range route.GroupBy {
+		labels[name] = all[name]
+		key += "\x00" + all[name]
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	g, ok := r.groups[key]
+	if !ok {
+		g = &alertGroup{route: route, labels: labels, next: route.firstFlush(alert.Time)}
+		r.groups[key] = g
+	}
+	g.pending = append(g.pending, alert)
+}
+
+// Flush sends the groups due at now. Groups with nothing new to send are dropped,
+// so the next alert of a quiet group waits group_wait again.
+func (r *Router) Flush(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, g := range r.groups {
+		if now.Before(g.next) {
+			continue
+		}
+		if len(g.pending) == 0 {
+			delete(r.groups, key)
+			continue
+		}
+		r.send(g)
+		g.next = g.route.nextFlush(now)
+	}
+}
+
+// Run flushes groups as they become due until ctx is done, then sends every
+// pending group so nothing is lost on shutdown.
+func (r *Router) Run(ctx context.Context) {
+	ticker := time.NewTicker(groupTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.mu.Lock()
+			for key, g := range r.groups {
+				if len(g.pending) > 0 {
+					r.send(g)
+				}
+				delete(r.groups, key)
+			}
+			r.mu.Unlock()
+			return
+		case now := <-ticker.C:
+			r.Flush(now)
+		}
+	}
+}
+
+// send delivers the pending alerts of g: as they are when there is a single one,
+// as a group alert otherwise.
+func (r *Router) send(g *alertGroup) {
+	alert := g.pending[0]
+	if len(g.pending) > 1 {
+		alert = GroupAlert(g.labels, g.pending)
+		alert.Digest = g.route.Digest
+	}
+	r.sender.DispatchTo(alert, g.route.Receivers)
+	g.pending = nil
+}
+
+// GroupAlert returns the alert standing for alerts, sent as one notification.
+// Its status is the worst of theirs.
+func GroupAlert(labels map[string]string, alerts []Alert) Alert {
+	group := Alert{
+		Labels:    labels,
+		Group:     alerts,
+		NewStatus: common.StatusUp,
+	}
+	for _, a := range alerts {
+		group.NewStatus = common.WorseStatus(group.NewStatus, a.NewStatus)
+		if a.Time.After(group.Time) {
+			group.Time = a.Time
+		}
+	}
+	return group
+}
+
+// groupTitle summarises a group alert, e.g.
+// "[DOWN] 3 alerts (team=payments): 2 down, 1 up".
+func groupTitle(a Alert) string {
+	counts := make(map[string]int)
+	var statuses []string
+	for _, alert := range a.Group {
+		if counts[alert.NewStatus] == 0 {
+			statuses = append(statuses, alert.NewStatus)
+		}
+		counts[alert.NewStatus]++
+	}
+	slices.SortStableFunc(statuses, func(x, y string) int {
+		return common.StatusSeverity(y) - common.StatusSeverity(x)
+	})
+	summary := make([]string, len(statuses))
+	for i, status := range statuses {
+		summary[i] = fmt.Sprintf("%d %s", counts[status], status)
+	}
+
+	title := "[" + strings.ToUpper(a.NewStatus) + "] "
+	if a.Digest > 0 {
+		title = "[DIGEST] "
+	}
+	title += fmt.Sprintf("%d alerts", len(a.Group))
+	if a.Digest > 0 {
+		title += " in the last " + a.Digest.String()
+	}
+	if len(a.Labels) > 0 {
+		pairs := make([]string, 0, len(a.Labels))
+		for name, value := range a.Labels {
+			pairs = append(pairs, name+"="+value)
+		}
+		slices.Sort(pairs)
+		title += " (" + strings.Join(pairs, ", ") + ")"
+	}
+	return title + ": " + strings.Join(summary, ", ")
+}
+
+// ungroup replaces the group alerts among alerts with the alerts they stand for.
+func ungroup(alerts []Alert) []Alert {
+	var flat []Alert
+	for _, a := range alerts {
+		if len(a.Group) > 0 {
+			flat = append(flat, a.Group...)
+		} else {
+			flat = append(flat, a)
+		}
+	}
+	return flat
+}
+
+// PartialError reports the alerts of a group that were not delivered. The
+// dispatcher retries only those, so the delivered ones are not sent twice.
+type PartialError struct {
+	Failed []Alert
+	Err    error
+}
+
+func (e *PartialError) Error() string { return e.Err.Error() }
+
+func (e *PartialError) Unwrap() error { return e.Err }
+
+// notifyEach delivers the alerts of a group one by one, for notifiers keeping
+// one alert per endpoint. Every alert is attempted; the errors are joined in a
+// PartialError listing the failed alerts.
+func notifyEach(ctx context.Context, group []Alert, notify func(context.Context, Alert) error) error {
+	var (
+		errs   []error
+		failed []Alert
+	)
+	for _, a := range group {
+		if err := notify(ctx, a); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", a.EndpointID, err))
+			failed = append(failed, a)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &PartialError{Failed: failed, Err: errors.Join(errs...)}
+}
@@ -0,0 +1,167 @@
+package notifier
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+func newGroupRouter(t *testing.T, root *Route) (*Router, *fakeSender) {
+	t.Helper()
+	if err := ValidateRoute(root); err != nil {
+		t.Fatal(err)
+	}
+	sender := &fakeSender{}
+	router, err := NewRouter(sender, root, []string{"ops", "digest"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router, sender
+}
+
+func teamAlert(id, team, status string, at time.Time) Alert {
+	alert := downAlert(id)
+	alert.NewStatus = status
+	alert.Labels = map[string]string{"team": team}
+	alert.Time = at
+	return alert
+}
+
+func TestRouter_GroupWaitAndInterval(t *testing.T) {
+	router, sender := newGroupRouter(t, &Route{
+		Receivers:     []string{"ops"},
+		GroupBy:       []string{"team"},
+		GroupWait:     30 * time.Second,
+		GroupInterval: 5 * time.Minute,
+	})
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	router.Dispatch(teamAlert("api", "payments", common.StatusDown, start))
+	router.Dispatch(teamAlert("db", "payments", common.StatusUnreachable, start.Add(5*time.Second)))
+	router.Flush(start.Add(29 * time.Second))
+	expectSent(t, sender)
+
+	router.Flush(start.Add(30 * time.Second))
+	expectSent(t, sender, "[UNREACHABLE] 2 alerts (team=payments): 1 unreachable, 1 down -> ops")
+
+	// Alerts joining a notified group wait for the group interval
+	late := teamAlert("cache", "payments", common.StatusDown, start.Add(time.Minute))
+	router.Dispatch(late)
+	router.Flush(start.Add(5 * time.Minute))
+	expectSent(t, sender)
+	router.Flush(start.Add(5*time.Minute + 30*time.Second))
+	expectSent(t, sender, late.Title()+" -> ops")
+
+	// A quiet group is dropped, the next alert waits group_wait again
+	router.Flush(start.Add(10*time.Minute + 30*time.Second))
+	next := teamAlert("api", "payments", common.StatusUp, start.Add(11*time.Minute))
+	router.Dispatch(next)
+	router.Flush(start.Add(11*time.Minute + 29*time.Second))
+	expectSent(t, sender)
+	router.Flush(start.Add(11*time.Minute + 30*time.Second))
+	expectSent(t, sender, next.Title()+" -> ops")
+}
+
+func TestRouter_GroupsByLabels(t *testing.T) {
+	router, sender := newGroupRouter(t, &Route{
+		Receivers: []string{"ops"},
+		GroupBy:   []string{"team"},
+	})
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	router.Dispatch(teamAlert("api", "payments", common.StatusDown, start))
+	router.Dispatch(teamAlert("web", "search", common.StatusDown, start))
+	router.Dispatch(teamAlert("db", "payments", common.StatusDown, start))
+	router.Flush(start.Add(DefaultGroupWait))
+
+	got := sender.take()
+	slices.Sort(got)
+	want := []string{
+		"[DOWN] 2 alerts (team=payments): 2 down -> ops",
+		"[DOWN] web (was up) -> ops",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestGroupAlert_WorstStatus(t *testing.T) {
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	group := GroupAlert(map[string]string{"team": "payments"}, []Alert{
+		teamAlert("api", "payments", common.StatusUp, start),
+		teamAlert("db", "payments", common.StatusUnreachable, start.Add(time.Minute)),
+		teamAlert("web", "payments", common.StatusDegraded, start),
+		teamAlert("cache", "payments", common.StatusDown, start),
+	})
+
+	if group.NewStatus != common.StatusUnreachable || !group.Time.Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected the worst status and latest time, got %s at %s", group.NewStatus, group.Time)
+	}
+	want := "[UNREACHABLE] 4 alerts (team=payments): 1 unreachable, 1 down, 1 degraded, 1 up"
+	if got := group.Title(); got != want {
+		t.Errorf("Expected title %q, got %q", want, got)
+	}
+}
+
+func TestRouter_Digest(t *testing.T) {
+	router, sender := newGroupRouter(t, &Route{
+		Receivers: []string{"ops"},
+		Routes: []Route{
+			{Matchers: []string{"team=internal"}, Receivers: []string{"digest"}, Digest: time.Hour},
+		},
+	})
+	start := time.Date(2026, 10, 17, 12, 10, 0, 0, time.UTC)
+
+	router.Dispatch(teamAlert("wiki", "internal", common.StatusDown, start))
+	router.Dispatch(teamAlert("api", "payments", common.StatusDown, start))
+	router.Dispatch(teamAlert("chat", "internal", common.StatusDegraded, start.Add(20*time.Minute)))
+	expectSent(t, sender, "[DOWN] api (was up) -> ops")
+
+	router.Flush(start.Add(49 * time.Minute))
+	expectSent(t, sender)
+	router.Flush(start.Add(50 * time.Minute)) // on the hour
+	expectSent(t, sender, "[DIGEST] 2 alerts in the last 1h0m0s: 1 down, 1 degraded -> digest")
+}
+
+func TestRouter_RunFlushesOnShutdown(t *testing.T) {
+	router, sender := newGroupRouter(t, &Route{Receivers: []string{"ops"}, GroupWait: time.Hour})
+	alert := teamAlert("api", "payments", common.StatusDown, time.Now())
+	router.Dispatch(alert)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	router.Run(ctx)
+	expectSent(t, sender, alert.Title()+" -> ops")
+}
+
+func TestValidateRoute_Grouping(t *testing.T) {
+	root := &Route{
+		Receivers: []string{"ops"},
+		GroupBy:   []string{"team"},
+		Routes: []Route{
+			{Matchers: []string{"env=prod"}, GroupWait: 10 * time.Second},
+			{Matchers: []string{"env=dev"}, Digest: 24 * time.Hour},
+		},
+	}
+	if err := ValidateRoute(root); err != nil {
+		t.Fatal(err)
+	}
+
+	if root.GroupWait != DefaultGroupWait || root.GroupInterval != DefaultGroupInterval {
+		t.Errorf("Expected default group settings, got %s and %s", root.GroupWait, root.GroupInterval)
+	}
+	prod := root.Routes[0]
+	if !slices.Equal(prod.GroupBy, []string{"team"}) || prod.GroupWait != 10*time.Second || prod.GroupInterval != DefaultGroupInterval {
+		t.Errorf("Expected inherited group settings, got %v, %s and %s", prod.GroupBy, prod.GroupWait, prod.GroupInterval)
+	}
+	if dev := root.Routes[1]; dev.Digest != 24*time.Hour {
+		t.Errorf("Expected a daily digest, got %s", dev.Digest)
+	}
+
+	if err := ValidateRoute(&Route{Receivers: []string{"ops"}, GroupWait: -time.Second}); err == nil {
+		t.Errorf("Expected an error for a negative group_wait")
+	}
+}
@@ -0,0 +1,214 @@
+package notifier
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// MockMail is an email received by a MockSMTPServer
+type MockMail struct {
+	From string
+	To   []string
+	Data string
+	User string // authenticated user, empty without AUTH
+	TLS  bool   // whether the message was sent over TLS
+}
+
+// MockSMTPServer is a minimal SMTP server for testing. It supports EHLO,
+// STARTTLS, AUTH PLAIN, MAIL, RCPT, DATA, RSET, NOOP and QUIT.
+type MockSMTPServer struct {
+	Addr string
+
+	listener  net.Listener
+	tlsConfig *tls.Config
+	implicit  bool
+	user      string
+	password  string
+
+	mu    sync.Mutex
+	mails []MockMail
+	wg    sync.WaitGroup
+}
+
+// NewMockSMTPServer starts a server on 127.0.0.1. With a TLS config it offers
+// STARTTLS, or speaks TLS right away when implicit is true.
+func NewMockSMTPServer(tlsConfig *tls.Config, implicit bool) (*MockSMTPServer, error) {
+	var (
+		l   net.Listener
+		err error
+	)
+	if implicit {
+		l, err = tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	} else {
+		l, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := &MockSMTPServer{
+		Addr:      l.Addr().String(),
+		listener:  l,
+		tlsConfig: tlsConfig,
+		implicit:  implicit,
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// RequireAuth rejects messages unless the client authenticates with user and password
+func (s *MockSMTPServer) RequireAuth(user, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user, s.password = user, password
+}
+
+// Mails returns the messages received so far
+func (s *MockSMTPServer) Mails() []MockMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]MockMail(nil), s.mails...)
+}
+
+// Close stops the server
+func (s *MockSMTPServer) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *MockSMTPServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *MockSMTPServer) handle(conn net.Conn) {
+	s.mu.Lock()
+	user, password := s.user, s.password
+	s.mu.Unlock()
+
+	var (
+		secure = s.implicit
+		reader = textproto.NewReader(bufio.NewReader(conn))
+		mail   MockMail
+		authed string
+	)
+	reply := func(format string, args ...any) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	reply("220 mock ESMTP ready")
+	for {
+		line, err := reader.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250-mock greets %s", arg)
+			if s.tlsConfig != nil && !secure {
+				reply("250-STARTTLS")
+			}
+			reply("250-AUTH PLAIN")
+			reply("250 8BITMIME")
+
+		case "STARTTLS":
+			if s.tlsConfig == nil || secure {
+				reply("502 not supported")
+				continue
+			}
+			reply("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, secure = tlsConn, true
+			reader = textproto.NewReader(bufio.NewReader(conn))
+			mail, authed = MockMail{}, ""
+
+		case "AUTH":
+			mech, initial, _ := strings.Cut(arg, " ")
+			if !strings.EqualFold(mech, "PLAIN") {
+				reply("504 unsupported mechanism")
+				continue
+			}
+			decoded, err := base64.StdEncoding.DecodeString(initial)
+			parts := strings.Split(string(decoded), "\x00")
+			if err != nil || len(parts) != 3 || parts[1] != user || parts[2] != password {
+				reply("535 authentication failed")
+				continue
+			}
+			authed = parts[1]
+			reply("235 authenticated")
+
+		case "MAIL":
+			if user != "" && authed == "" {
+				reply("530 authentication required")
+				continue
+			}
+			mail = MockMail{From: address(arg), User: authed, TLS: secure}
+			reply("250 OK")
+
+		case "RCPT":
+			mail.To = append(mail.To, address(arg))
+			reply("250 OK")
+
+		case "DATA":
+			if mail.From == "" || len(mail.To) == 0 {
+				reply("503 need MAIL and RCPT first")
+				continue
+			}
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			data, err := reader.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			mail.Data = string(data)
+			s.mu.Lock()
+			s.mails = append(s.mails, mail)
+			s.mu.Unlock()
+			mail = MockMail{User: authed, TLS: secure}
+			reply("250 OK queued")
+
+		case "RSET":
+			mail = MockMail{User: authed, TLS: secure}
+			reply("250 OK")
+
+		case "NOOP":
+			reply("250 OK")
+
+		case "QUIT":
+			reply("221 bye")
+			return
+
+		default:
+			reply("500 unknown command")
+		}
+	}
+}
+
+// address extracts the address of "FROM:<a@b>" or "TO:<a@b>"
+func address(arg string) string {
+	_, addr, _ := strings.Cut(arg, ":")
+	addr, _, _ = strings.Cut(strings.TrimSpace(addr), " ")
+	return strings.Trim(addr, "<>")
+}
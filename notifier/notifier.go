@@ -0,0 +1,103 @@
+package notifier
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// Notifier delivers alerts to an external system (webhook, email, chat...).
+// Notify must honour ctx cancellation; the dispatcher bounds every call with a timeout.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// Batcher is implemented by notifiers that deliver several alerts at once. The
+// dispatcher collects the alerts arriving within BatchWindow of the first one
+// and hands them over together, so a mass outage becomes a single message.
+type Batcher interface {
+	Notifier
+	BatchWindow() time.Duration
+	NotifyBatch(ctx context.Context, alerts []Alert) error
+}
+
+// Alert is a status change of an endpoint worth telling someone about.
+type Alert struct {
+	EndpointID   string            `json:"endpoint_id"`
+	EndpointName string            `json:"endpoint_name"`
+	URL          string            `json:"url"`
+	Labels       map[string]string `json:"labels,omitempty"` // the endpoint's labels
+	OldStatus    string            `json:"old_status"`       // empty for the first result of an endpoint
+	NewStatus    string            `json:"new_status"`
+	Result       common.Result     `json:"result"`
+	IncidentID   int64             `json:"incident_id,omitempty"`
+	StartedAt    time.Time         `json:"started_at,omitzero"` // incident start
+	Duration     time.Duration     `json:"duration,omitempty"`  // incident length, set on recovery, escalations and reminders
+	Flap         string            `json:"flap,omitempty"`      // common.FlapStart or common.FlapStop
+	Tier         int               `json:"tier,omitempty"`      // escalation tier the alert was escalated to, from 2
+	Reminder     bool              `json:"reminder,omitempty"`  // re-sent while the incident is open and unacknowledged
+	Group        []Alert           `json:"group,omitempty"`     // alerts sent together by a grouping route, see GroupAlert
+	Digest       time.Duration     `json:"digest,omitempty"`    // period covered by a digest group
+	Time         time.Time         `json:"time"`
+}
+
+// NewAlert builds the alert for a transition of ep.
+func NewAlert(t *common.Transition, ep common.Endpoint) Alert {
+	alert := Alert{
+		EndpointID:   t.EndpointID,
+		EndpointName: ep.Name,
+		URL:          t.Result.URL,
+		Labels:       ep.Labels,
+		OldStatus:    t.From,
+		NewStatus:    t.To,
+		Result:       t.Result,
+		Flap:         t.Flap,
+		Time:         t.At,
+	}
+	if t.Incident != nil {
+		alert.IncidentID = t.Incident.ID
+		alert.StartedAt = t.Incident.StartedAt
+		if !t.Incident.Open() {
+			alert.Duration = t.Incident.Duration(t.At)
+		}
+	}
+	return alert
+}
+
+// Recovered reports whether the alert closes an outage.
+func (a Alert) Recovered() bool {
+	return common.IsFailing(a.OldStatus) && !common.IsFailing(a.NewStatus)
+}
+
+// Title is a short one-line summary, e.g. "[DOWN] api (was up)",
+// "[DOWN] api (still down after 30m0s)", "[FLAPPING] api (now down)" or
+// "[DOWN] 3 alerts (team=payments): 2 down, 1 up".
+func (a Alert) Title() string {
+	if len(a.Group) > 0 {
+		return groupTitle(a)
+	}
+	name := a.EndpointName
+	if name == "" {
+		name = a.EndpointID
+	}
+	switch {
+	case a.Reminder:
+		return "[" + strings.ToUpper(a.NewStatus) + "] " + name + " (still " + a.NewStatus + " after " + a.Duration.Round(time.Second).String() + ")"
+	case a.Tier > 1:
+		return "[" + strings.ToUpper(a.NewStatus) + "] " + name + " (escalated, " + a.NewStatus + " for " + a.Duration.Round(time.Second).String() + ")"
+	}
+	switch a.Flap {
+	case common.FlapStart:
+		return "[FLAPPING] " + name + " (now " + a.NewStatus + ")"
+	case common.FlapStop:
+		return "[" + strings.ToUpper(a.NewStatus) + "] " + name + " (stopped flapping)"
+	}
+	title := "[" + strings.ToUpper(a.NewStatus) + "] " + name
+	if a.OldStatus != "" {
+		title += " (was " + a.OldStatus + ")"
+	}
+	return title
+}
@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+func TestAlert_Title(t *testing.T) {
+	tests := []struct {
+		name  string
+		alert Alert
+		want  string
+	}{
+		{"first result", Alert{EndpointID: "api", NewStatus: common.StatusDown}, "[DOWN] api"},
+		{"transition", Alert{EndpointName: "API", OldStatus: common.StatusUp, NewStatus: common.StatusDegraded}, "[DEGRADED] API (was up)"},
+		{"flapping", Alert{EndpointID: "api", OldStatus: common.StatusUp, NewStatus: common.StatusDown, Flap: common.FlapStart}, "[FLAPPING] api (now down)"},
+		{"stable", Alert{EndpointID: "api", OldStatus: common.StatusDown, NewStatus: common.StatusUp, Flap: common.FlapStop}, "[UP] api (stopped flapping)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.alert.Title(); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
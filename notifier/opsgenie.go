@@ -0,0 +1,147 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// DefaultOpsgenieAPIURL is the Opsgenie API (use https://api.eu.opsgenie.com in the EU).
+const DefaultOpsgenieAPIURL = "https://api.opsgenie.com"
+
+// DefaultOpsgeniePriorities maps pulse statuses to Opsgenie priorities.
+var DefaultOpsgeniePriorities = map[string]string{
+	common.StatusUnreachable: "P1",
+	common.StatusDown:        "P2",
+	common.StatusDegraded:    "P4",
+}
+
+var opsgeniePriorities = map[string]bool{"P1": true, "P2": true, "P3": true, "P4": true, "P5": true}
+
+// OpsgenieConfig configures an Opsgenie API integration.
+type OpsgenieConfig struct {
+	Name string `mapstructure:"name" json:"name" yaml:"name"`
+	// APIKey is the integration API key, expanded from the environment ($VAR or ${VAR})
+	APIKey string   `mapstructure:"api_key" json:"-" yaml:"-"`
+	APIURL string   `mapstructure:"api_url" json:"api_url,omitempty" yaml:"api_url,omitempty"`
+	Tags   []string `mapstructure:"tags" json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Priorities overrides the priority of a status (P1 to P5)
+	Priorities map[string]string `mapstructure:"priorities" json:"priorities,omitempty" yaml:"priorities,omitempty"`
+}
+
+// OpsgenieNotifier creates and closes Opsgenie alerts.
+type OpsgenieNotifier struct {
+	cfg    OpsgenieConfig
+	apiKey string
+	client *http.Client
+}
+
+// NewOpsgenieNotifier validates cfg.
+func NewOpsgenieNotifier(cfg OpsgenieConfig) (*OpsgenieNotifier, error) {
+	return NewOpsgenieNotifierWithClient(cfg, &http.Client{})
+}
+
+// NewOpsgenieNotifierWithClient is NewOpsgenieNotifier with a custom HTTP client.
+func NewOpsgenieNotifierWithClient(cfg OpsgenieConfig, client *http.Client) (*OpsgenieNotifier, error) {
+	apiKey := os.ExpandEnv(cfg.APIKey)
+	if apiKey == "" {
+		return nil, fmt.Errorf("opsgenie %q: api_key is required", cfg.Name)
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultOpsgenieAPIURL
+	}
+	cfg.APIURL = strings.TrimRight(cfg.APIURL, "/")
+
+	priorities := maps.Clone(DefaultOpsgeniePriorities)
+	for status, priority := range cfg.Priorities {
+		priority = strings.ToUpper(priority)
+		if !opsgeniePriorities[priority] {
+			return nil, fmt.Errorf("opsgenie %q: invalid priority %q for %s (use P1 to P5)", cfg.Name, priority, status)
+		}
+		priorities[status] = priority
+	}
+	cfg.Priorities = priorities
+
+	return &OpsgenieNotifier{cfg: cfg, apiKey: apiKey, client: client}, nil
+}
+
+func (o *OpsgenieNotifier) Name() string {
+	return o.cfg.Name
+}
+
+type opsgenieAlert struct {
+	Message     string         `json:"message"`
+	Alias       string         `json:"alias"`
+	Description string         `json:"description,omitempty"`
+	Entity      string         `json:"entity,omitempty"`
+	Source      string         `json:"source"`
+	Priority    string         `json:"priority"`
+	Tags        []string       `json:"tags,omitempty"`
+	Details     map[string]any `json:"details,omitempty"`
+}
+
+func (o *OpsgenieNotifier) Notify(ctx context.Context, alert Alert) error {
+	// Every endpoint has its own Opsgenie alert, grouped ones included
+	if len(alert.Group) > 0 {
+		return notifyEach(ctx, alert.Group, o.Notify)
+	}
+	alias := DedupKey(alert.EndpointID)
+
+	priority, ok := o.cfg.Priorities[alert.NewStatus]
+	if alert.NewStatus == common.StatusUp || !ok {
+		// Requests are processed asynchronously, so closing an alert that is
+		// not open is accepted and simply ignored
+		target := o.cfg.APIURL + "/v2/alerts/" + url.PathEscape(alias) + "/close?identifierType=alias"
+		return o.post(ctx, target, map[string]string{
+			"source": "pulse",
+			"note":   alert.Title() + recoveryNote(alert),
+		})
+	}
+
+	// Opsgenie deduplicates open alerts sharing an alias, so repeated failures
+	// only bump the count of the existing alert
+	details := map[string]any{}
+	for k, v := range alertDetails(alert) {
+		details[k] = fmt.Sprint(v) // details must be strings
+	}
+	return o.post(ctx, o.cfg.APIURL+"/v2/alerts", opsgenieAlert{
+		Message:     truncate(alert.Title(), 130),
+		Alias:       alias,
+		Description: truncate(alertSummary(alert), 15000),
+		Entity:      sourceOf(alert),
+		Source:      "pulse",
+		Priority:    priority,
+		Tags:        o.cfg.Tags,
+		Details:     details,
+	})
+}
+
+func recoveryNote(a Alert) string {
+	if a.Duration > 0 {
+		return ", down for " + a.Duration.String()
+	}
+	return ""
+}
+
+func (o *OpsgenieNotifier) post(ctx context.Context, target string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+o.apiKey)
+
+	return send(o.client, req, nil)
+}
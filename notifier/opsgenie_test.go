@@ -0,0 +1,95 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+func TestOpsgenieNotifier_CreateAndClose(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		auth   []string
+		paths  []string
+		alerts []opsgenieAlert
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		auth = append(auth, r.Header.Get("Authorization"))
+		paths = append(paths, r.URL.RequestURI())
+		if r.URL.Path == "/v2/alerts" {
+			var a opsgenieAlert
+			json.NewDecoder(r.Body).Decode(&a)
+			alerts = append(alerts, a)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"result":"Request will be processed","requestId":"1"}`))
+	}))
+	defer srv.Close()
+	t.Setenv("PULSE_TEST_GENIE_KEY", "genie")
+
+	n, err := NewOpsgenieNotifier(OpsgenieConfig{
+		Name:       "opsgenie",
+		APIKey:     "${PULSE_TEST_GENIE_KEY}",
+		APIURL:     srv.URL + "/",
+		Tags:       []string{"pulse"},
+		Priorities: map[string]string{common.StatusDown: "p1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	down, up := incidentAlerts()
+	for _, a := range []Alert{down, down, up} {
+		if err := n.Notify(context.Background(), a); err != nil {
+			t.Fatalf("notify %s: %v", a.NewStatus, err)
+		}
+	}
+
+	for _, a := range auth {
+		if a != "GenieKey genie" {
+			t.Errorf("Expected GenieKey auth, got %q", a)
+		}
+	}
+	if len(alerts) != 2 || alerts[0].Alias != "pulse/api" || alerts[1].Alias != alerts[0].Alias {
+		t.Fatalf("Expected both failures under the same alias, got %+v", alerts)
+	}
+	if alerts[0].Priority != "P1" || alerts[0].Details["status_code"] != "503" || len(alerts[0].Tags) != 1 {
+		t.Errorf("Unexpected alert: %+v", alerts[0])
+	}
+	if want := "/v2/alerts/pulse%2Fapi/close?identifierType=alias"; paths[2] != want {
+		t.Errorf("Expected the recovery to close %s, got %s", want, paths[2])
+	}
+}
+
+func TestOpsgenieNotifier_Priority(t *testing.T) {
+	n, err := NewOpsgenieNotifier(OpsgenieConfig{APIKey: "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{common.StatusUnreachable: "P1", common.StatusDown: "P2", common.StatusDegraded: "P4"}
+	for status, priority := range want {
+		if got := n.cfg.Priorities[status]; got != priority {
+			t.Errorf("Expected %s for %s, got %s", priority, status, got)
+		}
+	}
+	if !strings.HasPrefix(n.cfg.APIURL, "https://api.opsgenie.com") {
+		t.Errorf("Expected the default API URL, got %s", n.cfg.APIURL)
+	}
+}
+
+func TestNewOpsgenieNotifier_Invalid(t *testing.T) {
+	if _, err := NewOpsgenieNotifier(OpsgenieConfig{}); err == nil {
+		t.Errorf("Expected a missing api_key error")
+	}
+	if _, err := NewOpsgenieNotifier(OpsgenieConfig{APIKey: "key", Priorities: map[string]string{"down": "P9"}}); err == nil {
+		t.Errorf("Expected an invalid priority error")
+	}
+}
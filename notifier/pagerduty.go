@@ -0,0 +1,171 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// DefaultPagerDutyEventsURL is the PagerDuty Events API v2 endpoint.
+const DefaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// DefaultPagerDutySeverities maps pulse statuses to PagerDuty severities.
+var DefaultPagerDutySeverities = map[string]string{
+	common.StatusUnreachable: "critical",
+	common.StatusDown:        "critical",
+	common.StatusDegraded:    "warning",
+}
+
+var pagerDutySeverities = map[string]bool{"critical": true, "error": true, "warning": true, "info": true}
+
+// DedupKey is the key an endpoint's alerts are deduplicated under in on-call
+// tools: repeated failures update one open alert and a recovery resolves it.
+func DedupKey(endpointID string) string {
+	return "pulse/" + endpointID
+}
+
+// PagerDutyConfig configures a PagerDuty service integration (Events API v2).
+type PagerDutyConfig struct {
+	Name string `mapstructure:"name" json:"name" yaml:"name"`
+	// RoutingKey is the integration key, expanded from the environment ($VAR or ${VAR})
+	RoutingKey string `mapstructure:"routing_key" json:"-" yaml:"-"`
+	EventsURL  string `mapstructure:"events_url" json:"events_url,omitempty" yaml:"events_url,omitempty"`
+	// Severities overrides the severity of a status (critical, error, warning or info)
+	Severities map[string]string `mapstructure:"severities" json:"severities,omitempty" yaml:"severities,omitempty"`
+}
+
+// PagerDutyNotifier triggers and resolves PagerDuty alerts.
+type PagerDutyNotifier struct {
+	cfg        PagerDutyConfig
+	routingKey string
+	client     *http.Client
+}
+
+// NewPagerDutyNotifier validates cfg.
+func NewPagerDutyNotifier(cfg PagerDutyConfig) (*PagerDutyNotifier, error) {
+	return NewPagerDutyNotifierWithClient(cfg, &http.Client{})
+}
+
+// NewPagerDutyNotifierWithClient is NewPagerDutyNotifier with a custom HTTP client.
+func NewPagerDutyNotifierWithClient(cfg PagerDutyConfig, client *http.Client) (*PagerDutyNotifier, error) {
+	routingKey := os.ExpandEnv(cfg.RoutingKey)
+	if routingKey == "" {
+		return nil, fmt.Errorf("pagerduty %q: routing_key is required", cfg.Name)
+	}
+	if cfg.EventsURL == "" {
+		cfg.EventsURL = DefaultPagerDutyEventsURL
+	}
+
+	severities := maps.Clone(DefaultPagerDutySeverities)
+	for status, severity := range cfg.Severities {
+		if !pagerDutySeverities[severity] {
+			return nil, fmt.Errorf("pagerduty %q: invalid severity %q for %s (use critical, error, warning or info)", cfg.Name, severity, status)
+		}
+		severities[status] = severity
+	}
+	cfg.Severities = severities
+
+	return &PagerDutyNotifier{cfg: cfg, routingKey: routingKey, client: client}, nil
+}
+
+func (p *PagerDutyNotifier) Name() string {
+	return p.cfg.Name
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"` // trigger or resolve
+	DedupKey    string            `json:"dedup_key"`
+	Client      string            `json:"client,omitempty"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     string         `json:"timestamp"`
+	Component     string         `json:"component,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+func (p *PagerDutyNotifier) Notify(ctx context.Context, alert Alert) error {
+	// Every endpoint has its own PagerDuty alert, grouped ones included
+	if len(alert.Group) > 0 {
+		return notifyEach(ctx, alert.Group, p.Notify)
+	}
+	event := pagerDutyEvent{
+		RoutingKey: p.routingKey,
+		DedupKey:   DedupKey(alert.EndpointID),
+		Client:     "pulse",
+	}
+
+	severity, ok := p.cfg.Severities[alert.NewStatus]
+	if alert.NewStatus == common.StatusUp || !ok {
+		// Resolving needs no payload; an unknown alert is resolved as a no-op
+		event.EventAction = "resolve"
+	} else {
+		event.EventAction = "trigger"
+		event.Payload = &pagerDutyPayload{
+			Summary:       truncate(alert.Title()+": "+alertSummary(alert), 1024),
+			Source:        sourceOf(alert),
+			Severity:      severity,
+			Timestamp:     alert.Time.UTC().Format(time.RFC3339),
+			Component:     alert.EndpointID,
+			CustomDetails: alertDetails(alert),
+		}
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.EventsURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return send(p.client, req, nil)
+}
+
+// alertSummary is the reason of a failing alert: its error, messages or status.
+func alertSummary(a Alert) string {
+	return common.FailureReason(a.Result)
+}
+
+// sourceOf names what is being monitored, the URL when known.
+func sourceOf(a Alert) string {
+	if a.URL != "" {
+		return a.URL
+	}
+	return a.EndpointID
+}
+
+// alertDetails are the key/value details attached to on-call alerts.
+func alertDetails(a Alert) map[string]any {
+	details := map[string]any{
+		"endpoint":    a.EndpointID,
+		"old_status":  a.OldStatus,
+		"new_status":  a.NewStatus,
+		"status_code": a.Result.StatusCode,
+		"elapsed_ms":  a.Result.Elapsed,
+	}
+	if a.Result.Error != "" {
+		details["error"] = a.Result.Error
+	}
+	if len(a.Result.Messages) > 0 {
+		details["messages"] = a.Result.Messages
+	}
+	if a.IncidentID != 0 {
+		details["incident_id"] = a.IncidentID
+		details["incident_started_at"] = a.StartedAt.UTC().Format(time.RFC3339)
+	}
+	return details
+}
@@ -0,0 +1,204 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// pagerDutyStub mimics the Events API v2: triggers sharing a dedup key update
+// the open incident instead of opening another one.
+type pagerDutyStub struct {
+	mu        sync.Mutex
+	events    []pagerDutyEvent
+	open      map[string]bool
+	incidents int
+	fails     map[string]int // events of a dedup key rejected before accepting them
+}
+
+func newPagerDutyStub(t *testing.T) (*pagerDutyStub, *httptest.Server) {
+	stub := &pagerDutyStub{open: map[string]bool{}, fails: map[string]int{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil || event.RoutingKey == "" || event.DedupKey == "" {
+			http.Error(w, `{"status":"invalid event"}`, http.StatusBadRequest)
+			return
+		}
+
+		stub.mu.Lock()
+		if stub.fails[event.DedupKey] > 0 {
+			stub.fails[event.DedupKey]--
+			stub.mu.Unlock()
+			http.Error(w, `{"status":"unavailable"}`, http.StatusServiceUnavailable)
+			return
+		}
+		stub.events = append(stub.events, event)
+		switch event.EventAction {
+		case "trigger":
+			if !stub.open[event.DedupKey] {
+				stub.open[event.DedupKey] = true
+				stub.incidents++
+			}
+		case "resolve":
+			delete(stub.open, event.DedupKey)
+		}
+		stub.mu.Unlock()
+
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"status": "success", "dedup_key": event.DedupKey})
+	}))
+	t.Cleanup(srv.Close)
+	return stub, srv
+}
+
+func TestPagerDutyNotifier_TriggerAndResolve(t *testing.T) {
+	stub, srv := newPagerDutyStub(t)
+	t.Setenv("PULSE_TEST_PD_KEY", "routing-key")
+
+	n, err := NewPagerDutyNotifier(PagerDutyConfig{Name: "pd", RoutingKey: "$PULSE_TEST_PD_KEY", EventsURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	down, up := incidentAlerts()
+	unreachable := down
+	unreachable.OldStatus, unreachable.NewStatus = common.StatusDown, common.StatusUnreachable
+	for _, a := range []Alert{down, unreachable, up} {
+		if err := n.Notify(context.Background(), a); err != nil {
+			t.Fatalf("notify %s: %v", a.NewStatus, err)
+		}
+	}
+
+	if stub.incidents != 1 {
+		t.Errorf("Expected repeated failures to open 1 incident, got %d", stub.incidents)
+	}
+	if len(stub.open) != 0 {
+		t.Errorf("Expected the recovery to resolve the incident, still open: %v", stub.open)
+	}
+
+	first := stub.events[0]
+	if first.RoutingKey != "routing-key" || first.DedupKey != "pulse/api" || first.EventAction != "trigger" {
+		t.Errorf("Unexpected trigger: %+v", first)
+	}
+	if first.Payload == nil || first.Payload.Severity != "critical" || first.Payload.Summary == "" {
+		t.Errorf("Expected a critical payload, got %+v", first.Payload)
+	}
+	if last := stub.events[2]; last.EventAction != "resolve" || last.DedupKey != first.DedupKey || last.Payload != nil {
+		t.Errorf("Expected a resolve with the same dedup key, got %+v", last)
+	}
+}
+
+func TestPagerDutyNotifier_Severity(t *testing.T) {
+	stub, srv := newPagerDutyStub(t)
+
+	n, err := NewPagerDutyNotifier(PagerDutyConfig{
+		RoutingKey: "key",
+		EventsURL:  srv.URL,
+		Severities: map[string]string{common.StatusDown: "error"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	down := downAlert("api")
+	degraded := down
+	degraded.NewStatus = common.StatusDegraded
+	for _, a := range []Alert{down, degraded} {
+		if err := n.Notify(context.Background(), a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := stub.events[0].Payload.Severity; got != "error" {
+		t.Errorf("Expected the overridden severity error, got %s", got)
+	}
+	if got := stub.events[1].Payload.Severity; got != "warning" {
+		t.Errorf("Expected warning for degraded, got %s", got)
+	}
+}
+
+func TestPagerDutyNotifier_Rejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"status":"invalid routing key"}`, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	n, err := NewPagerDutyNotifier(PagerDutyConfig{RoutingKey: "key", EventsURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(context.Background(), downAlert("api")); err == nil {
+		t.Errorf("Expected an error on 400")
+	}
+}
+
+func TestNewPagerDutyNotifier_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  PagerDutyConfig
+	}{
+		{"missing routing key", PagerDutyConfig{RoutingKey: "$PULSE_TEST_UNSET"}},
+		{"invalid severity", PagerDutyConfig{RoutingKey: "key", Severities: map[string]string{"down": "page"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPagerDutyNotifier(tt.cfg); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+func TestPagerDutyNotifier_Group(t *testing.T) {
+	stub, srv := newPagerDutyStub(t)
+
+	n, err := NewPagerDutyNotifier(PagerDutyConfig{Name: "pd", RoutingKey: "routing-key", EventsURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	group := GroupAlert(nil, []Alert{downAlert("api"), downAlert("db")})
+	if err := n.Notify(context.Background(), group); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	// Grouped alerts still page one incident per endpoint
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	if stub.incidents != 2 || !stub.open[DedupKey("api")] || !stub.open[DedupKey("db")] {
+		t.Errorf("Expected an incident per endpoint, got %d: %v", stub.incidents, stub.open)
+	}
+}
+
+func TestPagerDutyNotifier_GroupRetriesFailedAlerts(t *testing.T) {
+	stub, srv := newPagerDutyStub(t)
+	stub.fails[DedupKey("db")] = 1
+
+	n, err := NewPagerDutyNotifier(PagerDutyConfig{Name: "pd", RoutingKey: "routing-key", EventsURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewDispatcher([]Notifier{n}, testOptions())
+	d.Dispatch(GroupAlert(nil, []Alert{downAlert("api"), downAlert("db"), downAlert("web")}))
+	d.Close(time.Second)
+
+	// The retry only sends the event that failed
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	var keys []string
+	for _, event := range stub.events {
+		keys = append(keys, event.DedupKey)
+	}
+	if want := []string{DedupKey("api"), DedupKey("web"), DedupKey("db")}; !slices.Equal(keys, want) {
+		t.Errorf("Expected events %v, got %v", want, keys)
+	}
+	if s := d.Stats()["pd"]; s.Sent != 1 || s.Retried != 1 || s.Failed != 0 {
+		t.Errorf("Expected the group to be delivered after one retry, got %+v", s)
+	}
+}
@@ -0,0 +1,201 @@
+package notifier
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// Route sends the alerts matching all of its Matchers to its Receivers, the
+// names of notifiers. Alerts are handed to the first child route they match,
+// or to the route itself when no child matches; a child with Continue set lets
+// the next children match too. The root route matches every alert and is the
+// default route.
+//
+// A route with GroupBy, GroupWait or GroupInterval set groups its alerts by the
+// values of the GroupBy labels (all together when empty): the alerts of a new
+// group are sent together after GroupWait, and the ones joining it later every
+// GroupInterval. A route with a Digest sends its groups every Digest instead.
+// Unset grouping settings are inherited from the parent.
+type Route struct {
+	Receivers     []string      `mapstructure:"receivers" json:"receivers,omitempty" yaml:"receivers,omitempty"` // inherited from the parent when empty
+	Matchers      []string      `mapstructure:"matchers" json:"matchers,omitempty" yaml:"matchers,omitempty"`
+	Continue      bool          `mapstructure:"continue" json:"continue,omitempty" yaml:"continue,omitempty"`
+	GroupBy       []string      `mapstructure:"group_by" json:"group_by,omitempty" yaml:"group_by,omitempty"`
+	GroupWait     time.Duration `mapstructure:"group_wait" json:"group_wait,omitempty" yaml:"group_wait,omitempty"`
+	GroupInterval time.Duration `mapstructure:"group_interval" json:"group_interval,omitempty" yaml:"group_interval,omitempty"`
+	Digest        time.Duration `mapstructure:"digest" json:"digest,omitempty" yaml:"digest,omitempty"`
+	Routes        []Route       `mapstructure:"routes" json:"routes,omitempty" yaml:"routes,omitempty"`
+
+	matchers []common.Matcher // Matchers parsed once at config load
+	path     string           // position in the tree, e.g. "route.routes[1]"
+}
+
+// Grouping defaults of routes that group alerts
+const (
+	DefaultGroupWait     = 30 * time.Second
+	DefaultGroupInterval = 5 * time.Minute
+)
+
+// ValidateRoute validates the routing tree rooted at r and parses its matchers.
+func ValidateRoute(r *Route) error {
+	if len(r.Matchers) > 0 || r.Continue {
+		return fmt.Errorf("the root route matches every alert, matchers and continue must be unset")
+	}
+	if len(r.Receivers) == 0 {
+		return fmt.Errorf("the root route needs receivers, it handles the alerts no other route matches")
+	}
+	return validateRoutes(r, "route")
+}
+
+func validateRoutes(r *Route, path string) error {
+	r.path = path
+	if r.GroupWait < 0 || r.GroupInterval < 0 || r.Digest < 0 {
+		return fmt.Errorf("%s: group_wait, group_interval and digest must be non-negative", path)
+	}
+	if slices.Contains(r.GroupBy, "") {
+		return fmt.Errorf("%s: group_by holds an empty label name", path)
+	}
+	if len(r.GroupBy) > 0 || r.GroupWait > 0 || r.GroupInterval > 0 {
+		if r.GroupWait == 0 {
+			r.GroupWait = DefaultGroupWait
+		}
+		if r.GroupInterval == 0 {
+			r.GroupInterval = DefaultGroupInterval
+		}
+	}
+
+	matchers, err := common.ParseMatchers(r.Matchers)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	r.matchers = matchers
+
+	for i := range r.Routes {
+		child := &r.Routes[i]
+		if len(child.Receivers) == 0 {
+			child.Receivers = r.Receivers
+		}
+		if child.GroupBy == nil {
+			child.GroupBy = r.GroupBy
+		}
+		if child.GroupWait == 0 {
+			child.GroupWait = r.GroupWait
+		}
+		if child.GroupInterval == 0 {
+			child.GroupInterval = r.GroupInterval
+		}
+		if child.Digest == 0 {
+			child.Digest = r.Digest
+		}
+		if err := validateRoutes(child, fmt.Sprintf("%s.routes[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Match returns the routes handling an alert with labels, in tree order.
+func (r *Route) Match(labels map[string]string) []*Route {
+	for _, m := range r.matchers {
+		if !m.Matches(labels) {
+			return nil
+		}
+	}
+
+	var matched []*Route
+	for i := range r.Routes {
+		child := &r.Routes[i]
+		routes := child.Match(labels)
+		matched = append(matched, routes...)
+		if len(routes) > 0 && !child.Continue {
+			break
+		}
+	}
+	if len(matched) == 0 {
+		matched = []*Route{r}
+	}
+	return matched
+}
+
+// each calls fn for r and every route below it.
+func (r *Route) each(fn func(*Route)) {
+	fn(r)
+	for i := range r.Routes {
+		r.Routes[i].each(fn)
+	}
+}
+
+// Router is a Sender delivering every alert to the receivers of the routes it
+// matches. Alerts sent to named notifiers, e.g. by escalation policies, bypass it.
+// Alerts of grouping routes are held until Flush sends their group.
+type Router struct {
+	sender Sender
+	root   *Route
+
+	mu     sync.Mutex
+	groups map[string]*alertGroup // by route and group labels
+}
+
+// NewRouter returns a Router sending through sender. A nil root sends every alert
+// to every notifier. Every receiver of the tree must be in notifiers; root must
+// have been validated.
+func NewRouter(sender Sender, root *Route, notifiers []string) (*Router, error) {
+	if root != nil {
+		var err error
+		root.each(func(r *Route) {
+			for _, name := range r.Receivers {
+				if err == nil && !slices.Contains(notifiers, name) {
+					err = fmt.Errorf("route: unknown receiver %q", name)
+				}
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Router{sender: sender, root: root, groups: make(map[string]*alertGroup)}, nil
+}
+
+// Dispatch sends alert to the receivers of the routes it matches, or adds it to
+// the group it belongs to on grouping routes.
+func (r *Router) Dispatch(alert Alert) {
+	if r.root == nil {
+		r.sender.Dispatch(alert)
+		return
+	}
+
+	var receivers []string
+	for _, route := range r.root.Match(RouteLabels(alert)) {
+		if route.grouped() {
+			r.group(route, alert)
+			continue
+		}
+		for _, name := range route.Receivers {
+			if !slices.Contains(receivers, name) {
+				receivers = append(receivers, name)
+			}
+		}
+	}
+	if len(receivers) > 0 {
+		r.sender.DispatchTo(alert, receivers)
+	}
+}
+
+// DispatchTo sends alert to the named notifiers.
+func (r *Router) DispatchTo(alert Alert, notifiers []string) {
+	r.sender.DispatchTo(alert, notifiers)
+}
+
+// RouteLabels returns the labels routes match alert on: the endpoint's labels and
+// its ID. The status is left out so a recovery takes the same route as its outage.
+func RouteLabels(alert Alert) map[string]string {
+	labels := make(map[string]string, len(alert.Labels)+1)
+	maps.Copy(labels, alert.Labels)
+	labels[common.LabelEndpoint] = alert.EndpointID
+	return labels
+}
@@ -0,0 +1,152 @@
+package notifier
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+func TestParseMatcher(t *testing.T) {
+	tests := []struct {
+		matcher string
+		labels  map[string]string
+		want    bool
+		wantErr bool
+	}{
+		{matcher: "team=payments", labels: map[string]string{"team": "payments"}, want: true},
+		{matcher: `team = "payments"`, labels: map[string]string{"team": "payments"}, want: true},
+		{matcher: "team=payments", labels: map[string]string{"team": "search"}, want: false},
+		{matcher: "team!=payments", labels: map[string]string{"team": "search"}, want: true},
+		{matcher: "team!=payments", labels: map[string]string{}, want: true},
+		{matcher: "team=", labels: map[string]string{}, want: true},
+		{matcher: `env=~"prod|staging"`, labels: map[string]string{"env": "staging"}, want: true},
+		{matcher: "env=~prod", labels: map[string]string{"env": "production"}, want: false}, // anchored
+		{matcher: "env!~dev.*", labels: map[string]string{"env": "prod"}, want: true},
+		{matcher: "env!~dev.*", labels: map[string]string{"env": "dev-eu"}, want: false},
+		{matcher: "env=~(", wantErr: true},
+		{matcher: "=prod", wantErr: true},
+		{matcher: "env", wantErr: true},
+		{matcher: "env!prod", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		m, err := common.ParseMatcher(tt.matcher)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMatcher(%q): expected error %v, got %v", tt.matcher, tt.wantErr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := m.Matches(tt.labels); got != tt.want {
+			t.Errorf("%q matching %v: expected %v, got %v", tt.matcher, tt.labels, tt.want, got)
+		}
+	}
+}
+
+func testRoute(t *testing.T) *Route {
+	t.Helper()
+	root := &Route{
+		Receivers: []string{"slack-ops"},
+		Routes: []Route{
+			{
+				Matchers:  []string{"team=payments"},
+				Receivers: []string{"pagerduty-payments"},
+				Routes: []Route{
+					{Matchers: []string{"env!=prod"}, Receivers: []string{"slack-payments"}},
+				},
+			},
+			{Matchers: []string{"env=~dev|staging"}, Receivers: []string{"slack-dev"}, Continue: true},
+			{Matchers: []string{"team=search"}}, // inherits slack-ops
+			{Matchers: []string{`endpoint=~"db-.*"`}, Receivers: []string{"dba"}},
+		},
+	}
+	if err := ValidateRoute(root); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestRoute_Match(t *testing.T) {
+	root := testRoute(t)
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   []string
+	}{
+		{"default route", map[string]string{"team": "billing"}, []string{"slack-ops"}},
+		{"first match stops", map[string]string{"team": "payments", "env": "staging"}, []string{"slack-payments"}},
+		{"parent when no child matches", map[string]string{"team": "payments", "env": "prod"}, []string{"pagerduty-payments"}},
+		{"continue", map[string]string{"env": "dev", "endpoint": "db-main"}, []string{"slack-dev", "dba"}},
+		{"continue to default", map[string]string{"env": "dev"}, []string{"slack-dev"}},
+		{"inherited receivers", map[string]string{"team": "search"}, []string{"slack-ops"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range root.Match(tt.labels) {
+				got = append(got, r.Receivers...)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateRoute(t *testing.T) {
+	tests := []struct {
+		name  string
+		route Route
+	}{
+		{"root without receivers", Route{}},
+		{"root with matchers", Route{Receivers: []string{"a"}, Matchers: []string{"team=x"}}},
+		{"root with continue", Route{Receivers: []string{"a"}, Continue: true}},
+		{"invalid nested matcher", Route{Receivers: []string{"a"}, Routes: []Route{{Routes: []Route{{Matchers: []string{"team"}}}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateRoute(&tt.route); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+func TestRouter(t *testing.T) {
+	down, up := incidentAlerts()
+	down.Labels = map[string]string{"team": "payments", "env": "prod"}
+	up.Labels = down.Labels
+
+	sender := &fakeSender{}
+	names := []string{"slack-ops", "pagerduty-payments", "slack-payments", "slack-dev", "dba"}
+	router, err := NewRouter(sender, testRoute(t), names)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.Dispatch(down)
+	router.Dispatch(up)
+	router.DispatchTo(down, []string{"dba"})
+	expectSent(t, sender,
+		down.Title()+" -> pagerduty-payments",
+		up.Title()+" -> pagerduty-payments",
+		down.Title()+" -> dba",
+	)
+
+	if _, err := NewRouter(sender, testRoute(t), names[:1]); err == nil || !strings.Contains(err.Error(), "pagerduty-payments") {
+		t.Errorf("Expected an unknown receiver error, got %v", err)
+	}
+
+	unrouted, err := NewRouter(sender, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unrouted.Dispatch(down)
+	expectSent(t, sender, down.Title()+" -> *")
+}
@@ -0,0 +1,161 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// DefaultSlackAPIURL is the Slack Web API used with a bot token.
+const DefaultSlackAPIURL = "https://slack.com/api"
+
+// SlackConfig configures a Slack notifier. Either WebhookURL (incoming webhook)
+// or Token and Channel (bot, Web API) must be set; only the bot can thread the
+// recovery under the original alert.
+type SlackConfig struct {
+	Name       string `mapstructure:"name" json:"name" yaml:"name"`
+	WebhookURL string `mapstructure:"webhook_url" json:"-" yaml:"-"`
+	// Token is a bot token (xoxb-...), expanded from the environment ($VAR or ${VAR})
+	Token   string `mapstructure:"token" json:"-" yaml:"-"`
+	Channel string `mapstructure:"channel" json:"channel,omitempty" yaml:"channel,omitempty"`
+	APIURL  string `mapstructure:"api_url" json:"api_url,omitempty" yaml:"api_url,omitempty"`
+}
+
+// SlackNotifier posts Block Kit messages to Slack.
+type SlackNotifier struct {
+	cfg     SlackConfig
+	token   string
+	client  *http.Client
+	threads threads
+}
+
+// NewSlackNotifier validates cfg.
+func NewSlackNotifier(cfg SlackConfig) (*SlackNotifier, error) {
+	return NewSlackNotifierWithClient(cfg, &http.Client{})
+}
+
+// NewSlackNotifierWithClient is NewSlackNotifier with a custom HTTP client.
+func NewSlackNotifierWithClient(cfg SlackConfig, client *http.Client) (*SlackNotifier, error) {
+	token := os.ExpandEnv(cfg.Token)
+	switch {
+	case cfg.WebhookURL != "" && token != "":
+		return nil, fmt.Errorf("slack %q: set either webhook_url or token, not both", cfg.Name)
+	case cfg.WebhookURL == "" && token == "":
+		return nil, fmt.Errorf("slack %q: webhook_url or token is required", cfg.Name)
+	case token != "" && cfg.Channel == "":
+		return nil, fmt.Errorf("slack %q: channel is required with a token", cfg.Name)
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultSlackAPIURL
+	}
+	cfg.APIURL = strings.TrimRight(cfg.APIURL, "/")
+
+	return &SlackNotifier{cfg: cfg, token: token, client: client}, nil
+}
+
+func (s *SlackNotifier) Name() string {
+	return s.cfg.Name
+}
+
+type slackMessage struct {
+	Channel        string            `json:"channel,omitempty"`
+	Text           string            `json:"text"` // notification fallback
+	ThreadTS       string            `json:"thread_ts,omitempty"`
+	ReplyBroadcast bool              `json:"reply_broadcast,omitempty"`
+	Attachments    []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Color  string `json:"color"`
+	Blocks []any  `json:"blocks"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (s *SlackNotifier) Notify(ctx context.Context, alert Alert) error {
+	msg := slackMessage{
+		Channel: s.cfg.Channel,
+		Text:    alert.Title(),
+		Attachments: []slackAttachment{{
+			Color:  hexColor(statusColor(alert.NewStatus)),
+			Blocks: slackBlocks(alert),
+		}},
+	}
+
+	if s.token == "" {
+		return s.post(ctx, s.cfg.WebhookURL, msg, nil)
+	}
+
+	if ts, ok := s.threads.get(alert); ok {
+		msg.ThreadTS, msg.ReplyBroadcast = ts, true
+	}
+	var resp struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+		TS    string `json:"ts"`
+	}
+	if err := s.post(ctx, s.cfg.APIURL+"/chat.postMessage", msg, &resp); err != nil {
+		return err
+	}
+	// The Web API reports failures with a 200 and ok=false
+	if !resp.OK {
+		return fmt.Errorf("slack: chat.postMessage: %s", resp.Error)
+	}
+	s.threads.set(alert, resp.TS)
+	return nil
+}
+
+func slackBlocks(a Alert) []any {
+	heading := "*" + a.Title() + "*"
+	if a.URL != "" {
+		heading += "\n" + a.URL
+	}
+
+	var fields []slackText
+	for _, f := range alertFields(a) {
+		fields = append(fields, slackText{Type: "mrkdwn", Text: "*" + f.Name + "*\n" + f.Value})
+	}
+
+	blocks := []any{
+		map[string]any{"type": "section", "text": slackText{Type: "mrkdwn", Text: heading}},
+	}
+	// A section holds at most 10 fields
+	for len(fields) > 0 {
+		n := min(len(fields), 10)
+		blocks = append(blocks, map[string]any{"type": "section", "fields": fields[:n]})
+		fields = fields[n:]
+	}
+	context := fmt.Sprintf("<!date^%d^{date_short_pretty} {time_secs}|%s>", a.Time.Unix(), a.Time.UTC().Format("2006-01-02 15:04:05 UTC"))
+	if a.EndpointID != "" {
+		context = a.EndpointID + " · " + context
+	}
+	blocks = append(blocks, map[string]any{
+		"type":     "context",
+		"elements": []slackText{{Type: "mrkdwn", Text: context}},
+	})
+	return blocks
+}
+
+// post sends payload as JSON and decodes the response into out when set.
+func (s *SlackNotifier) post(ctx context.Context, url string, payload any, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	return send(s.client, req, out)
+}
@@ -0,0 +1,124 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// incidentAlerts returns the alert opening incident 5 and the one closing it
+func incidentAlerts() (Alert, Alert) {
+	down := downAlert("api")
+	down.IncidentID = 5
+
+	up := down
+	up.OldStatus, up.NewStatus = common.StatusDown, common.StatusUp
+	up.Result = common.Result{EndpointID: "api", Status: common.StatusUp, StatusCode: 200, Elapsed: 12}
+	up.Duration = 3 * time.Minute
+	return down, up
+}
+
+func TestSlackNotifier_Webhook(t *testing.T) {
+	srv, requests := newCaptureServer(t, http.StatusOK)
+
+	s, err := NewSlackNotifier(SlackConfig{Name: "slack", WebhookURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Notify(context.Background(), downAlert("api")); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	var msg slackMessage
+	body := (<-requests).body
+	if err := json.Unmarshal(body, &msg); err != nil {
+		t.Fatalf("Expected JSON, got %s", body)
+	}
+	if msg.Text != "[DOWN] api (was up)" || len(msg.Attachments) != 1 {
+		t.Fatalf("Unexpected message: %s", body)
+	}
+	if msg.Attachments[0].Color != "#E01E5A" {
+		t.Errorf("Expected red for down, got %s", msg.Attachments[0].Color)
+	}
+	for _, want := range []string{"503", "42ms", common.UnexpectedStatusCodeMessage} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected message to contain %q: %s", want, body)
+		}
+	}
+}
+
+func TestSlackNotifier_BotThreadsRecovery(t *testing.T) {
+	var (
+		messages []slackMessage
+		auth     string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.postMessage" {
+			http.NotFound(w, r)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		var msg slackMessage
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &msg)
+		messages = append(messages, msg)
+		if msg.Channel == "#nope" {
+			w.Write([]byte(`{"ok": false, "error": "channel_not_found"}`))
+			return
+		}
+		w.Write([]byte(`{"ok": true, "ts": "1700000000.000100"}`))
+	}))
+	defer srv.Close()
+	t.Setenv("PULSE_TEST_SLACK_TOKEN", "xoxb-test")
+
+	s, err := NewSlackNotifier(SlackConfig{Name: "slack", Token: "${PULSE_TEST_SLACK_TOKEN}", Channel: "#ops", APIURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	down, up := incidentAlerts()
+	if err := s.Notify(context.Background(), down); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if err := s.Notify(context.Background(), up); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	if auth != "Bearer xoxb-test" {
+		t.Errorf("Expected bot token auth, got %q", auth)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(messages))
+	}
+	if messages[0].ThreadTS != "" {
+		t.Errorf("Expected the alert to start a new thread")
+	}
+	if messages[1].ThreadTS != "1700000000.000100" || messages[1].Attachments[0].Color != "#2EB67D" {
+		t.Errorf("Expected a green recovery in the alert's thread, got %+v", messages[1])
+	}
+
+	bad, _ := NewSlackNotifier(SlackConfig{Name: "slack", Token: "xoxb", Channel: "#nope", APIURL: srv.URL})
+	if err := bad.Notify(context.Background(), down); err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("Expected the API error to be returned, got %v", err)
+	}
+}
+
+func TestNewSlackNotifier_Invalid(t *testing.T) {
+	invalid := []SlackConfig{
+		{},
+		{WebhookURL: "https://hooks.slack.com/x", Token: "xoxb"},
+		{Token: "xoxb"},
+	}
+	for _, cfg := range invalid {
+		if _, err := NewSlackNotifier(cfg); err == nil {
+			t.Errorf("Expected %+v to be invalid", cfg)
+		}
+	}
+}
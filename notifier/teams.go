@@ -0,0 +1,111 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// TeamsConfig configures a Microsoft Teams incoming webhook (or Workflows webhook).
+// Teams webhooks cannot reply in threads, so recoveries are posted as new cards.
+type TeamsConfig struct {
+	Name       string `mapstructure:"name" json:"name" yaml:"name"`
+	WebhookURL string `mapstructure:"webhook_url" json:"-" yaml:"-"`
+}
+
+// TeamsNotifier posts adaptive cards to Microsoft Teams.
+type TeamsNotifier struct {
+	cfg    TeamsConfig
+	client *http.Client
+}
+
+// NewTeamsNotifier validates cfg.
+func NewTeamsNotifier(cfg TeamsConfig) (*TeamsNotifier, error) {
+	return NewTeamsNotifierWithClient(cfg, &http.Client{})
+}
+
+// NewTeamsNotifierWithClient is NewTeamsNotifier with a custom HTTP client.
+func NewTeamsNotifierWithClient(cfg TeamsConfig, client *http.Client) (*TeamsNotifier, error) {
+	u, err := url.Parse(cfg.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("teams %q: invalid webhook_url", cfg.Name)
+	}
+	return &TeamsNotifier{cfg: cfg, client: client}, nil
+}
+
+func (t *TeamsNotifier) Name() string {
+	return t.cfg.Name
+}
+
+// Adaptive cards only know a few named colours
+var teamsStyles = map[string]struct{ container, text string }{
+	common.StatusUp:          {"good", "Good"},
+	common.StatusDegraded:    {"warning", "Warning"},
+	common.StatusDown:        {"attention", "Attention"},
+	common.StatusUnreachable: {"attention", "Attention"},
+}
+
+func (t *TeamsNotifier) Notify(ctx context.Context, alert Alert) error {
+	style, ok := teamsStyles[alert.NewStatus]
+	if !ok {
+		style.container, style.text = "default", "Default"
+	}
+
+	var facts []map[string]string
+	for _, f := range alertFields(alert) {
+		facts = append(facts, map[string]string{"title": f.Name, "value": f.Value})
+	}
+
+	header := []map[string]any{{
+		"type":   "TextBlock",
+		"text":   alert.Title(),
+		"weight": "Bolder",
+		"size":   "Medium",
+		"color":  style.text,
+		"wrap":   true,
+	}}
+	if alert.URL != "" {
+		header = append(header, map[string]any{
+			"type":     "TextBlock",
+			"text":     alert.URL,
+			"isSubtle": true,
+			"spacing":  "None",
+			"wrap":     true,
+		})
+	}
+
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"msteams": map[string]any{"width": "Full"},
+		"body": []map[string]any{
+			{"type": "Container", "style": style.container, "bleed": true, "items": header},
+			{"type": "FactSet", "facts": facts},
+		},
+	}
+	msg := map[string]any{
+		"type":    "message",
+		"summary": alert.Title(),
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     card,
+		}},
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return send(t.client, req, nil)
+}
@@ -0,0 +1,52 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTeamsNotifier_AdaptiveCard(t *testing.T) {
+	srv, requests := newCaptureServer(t, http.StatusAccepted)
+
+	n, err := NewTeamsNotifier(TeamsConfig{Name: "teams", WebhookURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, up := incidentAlerts()
+	if err := n.Notify(context.Background(), up); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	body := (<-requests).body
+	var msg struct {
+		Type        string `json:"type"`
+		Attachments []struct {
+			ContentType string `json:"contentType"`
+			Content     struct {
+				Type string           `json:"type"`
+				Body []map[string]any `json:"body"`
+			} `json:"content"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		t.Fatalf("Expected JSON, got %s", body)
+	}
+	if msg.Type != "message" || len(msg.Attachments) != 1 || msg.Attachments[0].Content.Type != "AdaptiveCard" {
+		t.Fatalf("Unexpected message: %s", body)
+	}
+	if style := msg.Attachments[0].Content.Body[0]["style"]; style != "good" {
+		t.Errorf("Expected the good style on recovery, got %v", style)
+	}
+	if !strings.Contains(string(body), `"Down for"`) || !strings.Contains(string(body), `"3m0s"`) {
+		t.Errorf("Expected the incident duration on recovery: %s", body)
+	}
+}
+
+func TestNewTeamsNotifier_Invalid(t *testing.T) {
+	if _, err := NewTeamsNotifier(TeamsConfig{WebhookURL: "not a url"}); err == nil {
+		t.Errorf("Expected an invalid webhook_url error")
+	}
+}
@@ -0,0 +1,43 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"strings"
+	"text/template"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// TemplateFuncs are available in every notifier template, on top of the
+// request body functions (now, unix, uuid, env...):
+//
+//	{{ json .Result.Messages }}   value as JSON, e.g. to embed strings safely in a JSON body
+//	{{ upper .NewStatus }}        upper case
+//	{{ lower .NewStatus }}        lower case
+//	{{ join .Result.Messages ", " }}
+var TemplateFuncs = func() template.FuncMap {
+	funcs := maps.Clone(common.TemplateFuncs)
+	funcs["json"] = func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+	funcs["upper"] = strings.ToUpper
+	funcs["lower"] = strings.ToLower
+	funcs["join"] = strings.Join
+	return funcs
+}()
+
+// parseTemplate compiles a notifier template, failing on unknown fields at render time.
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(TemplateFuncs).Option("missingkey=error").Parse(text)
+}
+
+func render(tmpl *template.Template, alert Alert) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, alert); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
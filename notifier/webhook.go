@@ -0,0 +1,181 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// DefaultSignatureHeader carries the HMAC-SHA256 of the body when a secret is set.
+const DefaultSignatureHeader = "X-Pulse-Signature"
+
+// defaultWebhookTemplate sends the whole alert as JSON.
+const defaultWebhookTemplate = "{{ json . }}"
+
+// WebhookConfig configures a generic webhook.
+type WebhookConfig struct {
+	Name        string            `mapstructure:"name" json:"name" yaml:"name"`
+	URL         string            `mapstructure:"url" json:"url" yaml:"url"`
+	Method      string            `mapstructure:"method" json:"method" yaml:"method"` // POST by default
+	Headers     map[string]string `mapstructure:"headers" json:"headers,omitempty" yaml:"headers,omitempty"`
+	ContentType string            `mapstructure:"content_type" json:"content_type,omitempty" yaml:"content_type,omitempty"`
+	// Template is a text/template rendered with the Alert; the alert as JSON by default
+	Template string `mapstructure:"template" json:"template,omitempty" yaml:"template,omitempty"`
+	// Secret signs the body with HMAC-SHA256; $VAR and ${VAR} are expanded from the environment
+	Secret          string `mapstructure:"secret" json:"-" yaml:"-"`
+	SignatureHeader string `mapstructure:"signature_header" json:"signature_header,omitempty" yaml:"signature_header,omitempty"`
+}
+
+// WebhookNotifier sends alerts to an HTTP endpoint.
+type WebhookNotifier struct {
+	cfg    WebhookConfig
+	secret []byte
+	tmpl   *template.Template
+	client *http.Client
+}
+
+// NewWebhookNotifier validates cfg and compiles its template.
+func NewWebhookNotifier(cfg WebhookConfig) (*WebhookNotifier, error) {
+	return NewWebhookNotifierWithClient(cfg, &http.Client{})
+}
+
+// NewWebhookNotifierWithClient is NewWebhookNotifier with a custom HTTP client.
+// Timeouts come from the dispatcher context, so the client needs none.
+func NewWebhookNotifierWithClient(cfg WebhookConfig, client *http.Client) (*WebhookNotifier, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("webhook %q: invalid url %q", cfg.Name, cfg.URL)
+	}
+	if cfg.Method == "" {
+		cfg.Method = http.MethodPost
+	}
+	cfg.Method = strings.ToUpper(cfg.Method)
+	if err := common.ValidateMethod(cfg.Method); err != nil {
+		return nil, fmt.Errorf("webhook %q: %w", cfg.Name, err)
+	}
+	if cfg.ContentType == "" {
+		cfg.ContentType = common.ContentTypeJSON
+	}
+	if cfg.SignatureHeader == "" {
+		cfg.SignatureHeader = DefaultSignatureHeader
+	}
+	if cfg.Template == "" {
+		cfg.Template = defaultWebhookTemplate
+	}
+
+	tmpl, err := parseTemplate(cfg.Name, cfg.Template)
+	if err != nil {
+		return nil, fmt.Errorf("webhook %q: invalid template: %w", cfg.Name, err)
+	}
+	// Render a sample alert so template errors surface at startup, not during an outage
+	if _, err := render(tmpl, sampleAlert()); err != nil {
+		return nil, fmt.Errorf("webhook %q: invalid template: %w", cfg.Name, err)
+	}
+
+	return &WebhookNotifier{
+		cfg:    cfg,
+		secret: []byte(os.ExpandEnv(cfg.Secret)),
+		tmpl:   tmpl,
+		client: client,
+	}, nil
+}
+
+func (w *WebhookNotifier) Name() string {
+	return w.cfg.Name
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := render(w.tmpl, alert)
+	if err != nil {
+		return fmt.Errorf("rendering webhook body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, w.cfg.Method, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", w.cfg.ContentType)
+	req.Header.Set("User-Agent", "pulse")
+	for k, v := range w.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if len(w.secret) > 0 {
+		req.Header.Set(w.cfg.SignatureHeader, Sign(w.secret, body))
+	}
+
+	return send(w.client, req, nil)
+}
+
+// Sign returns the signature sent with webhook bodies: "sha256=" followed by
+// the hex HMAC-SHA256 of body. Receivers recompute it with the shared secret.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// send performs req, turns non-2xx responses into errors and decodes a JSON
+// response into out when set. Errors only name the host: chat webhook URLs
+// carry their secret in the path.
+func send(client *http.Client, req *http.Request, out any) error {
+	target := req.Method + " " + req.URL.Scheme + "://" + req.URL.Host
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("%s: %w", target, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet := body[:min(len(body), 512)]
+		return fmt.Errorf("%s: unexpected status %d: %s", target, resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("%s: decoding response: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// sampleAlert is used to validate templates at startup.
+func sampleAlert() Alert {
+	now := time.Now()
+	return Alert{
+		EndpointID:   "sample",
+		EndpointName: "sample",
+		URL:          "http://localhost",
+		OldStatus:    common.StatusUp,
+		NewStatus:    common.StatusDown,
+		Result: common.Result{
+			EndpointID: "sample",
+			URL:        "http://localhost",
+			Status:     common.StatusDown,
+			Timestamp:  now,
+			Error:      "sample error",
+			Messages:   []string{common.UnexpectedStatusCodeMessage},
+		},
+		IncidentID: 1,
+		StartedAt:  now,
+		Time:       now,
+	}
+}
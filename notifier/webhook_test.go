@@ -0,0 +1,151 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type capturedRequest struct {
+	method string
+	header http.Header
+	body   []byte
+}
+
+func newCaptureServer(t *testing.T, status int) (*httptest.Server, chan capturedRequest) {
+	t.Helper()
+
+	requests := make(chan capturedRequest, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- capturedRequest{method: r.Method, header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+		w.Write([]byte("nope"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+func TestWebhookNotifier_DefaultBody(t *testing.T) {
+	srv, requests := newCaptureServer(t, http.StatusOK)
+
+	w, err := NewWebhookNotifier(WebhookConfig{Name: "hook", URL: srv.URL})
+	if err != nil {
+		t.Fatalf("new webhook: %v", err)
+	}
+	alert := sampleAlert()
+	if err := w.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	req := <-requests
+	if req.method != http.MethodPost {
+		t.Errorf("Expected POST, got %s", req.method)
+	}
+	if ct := req.header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", ct)
+	}
+	if req.header.Get(DefaultSignatureHeader) != "" {
+		t.Errorf("Expected no signature without a secret")
+	}
+
+	var got Alert
+	if err := json.Unmarshal(req.body, &got); err != nil {
+		t.Fatalf("Expected the alert as JSON, got %s: %v", req.body, err)
+	}
+	if got.EndpointID != alert.EndpointID || got.NewStatus != alert.NewStatus || got.Result.Error != alert.Result.Error {
+		t.Errorf("Expected %+v, got %+v", alert, got)
+	}
+}
+
+func TestWebhookNotifier_TemplateHeadersAndSignature(t *testing.T) {
+	srv, requests := newCaptureServer(t, http.StatusAccepted)
+	t.Setenv("PULSE_TEST_SECRET", "s3cret")
+
+	w, err := NewWebhookNotifier(WebhookConfig{
+		Name:            "hook",
+		URL:             srv.URL,
+		Method:          "put",
+		Headers:         map[string]string{"authorization": "Bearer token"},
+		Template:        `{"text": {{ json (printf "%s is %s: %s" .EndpointName (upper .NewStatus) .Result.Error) }}, "code": {{ .Result.StatusCode }}}`,
+		Secret:          "${PULSE_TEST_SECRET}",
+		SignatureHeader: "X-Signature",
+	})
+	if err != nil {
+		t.Fatalf("new webhook: %v", err)
+	}
+
+	alert := sampleAlert()
+	alert.Result.Error = `quote " here`
+	if err := w.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	req := <-requests
+	if req.method != http.MethodPut {
+		t.Errorf("Expected PUT, got %s", req.method)
+	}
+	if auth := req.header.Get("Authorization"); auth != "Bearer token" {
+		t.Errorf("Expected custom header, got %q", auth)
+	}
+
+	var body struct {
+		Text string `json:"text"`
+		Code int    `json:"code"`
+	}
+	if err := json.Unmarshal(req.body, &body); err != nil {
+		t.Fatalf("Expected valid JSON, got %s: %v", req.body, err)
+	}
+	if body.Text != `sample is DOWN: quote " here` {
+		t.Errorf("Unexpected rendered text: %q", body.Text)
+	}
+
+	if sig := req.header.Get("X-Signature"); sig != Sign([]byte("s3cret"), req.body) {
+		t.Errorf("Expected signature of the body, got %q", sig)
+	}
+}
+
+func TestWebhookNotifier_ErrorStatus(t *testing.T) {
+	srv, _ := newCaptureServer(t, http.StatusInternalServerError)
+
+	w, err := NewWebhookNotifier(WebhookConfig{Name: "hook", URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Notify(context.Background(), sampleAlert())
+	if err == nil || !strings.Contains(err.Error(), "500") || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("Expected an error with status and body, got %v", err)
+	}
+}
+
+func TestNewWebhookNotifier_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  WebhookConfig
+	}{
+		{"missing url", WebhookConfig{}},
+		{"bad scheme", WebhookConfig{URL: "ftp://example.com"}},
+		{"bad method", WebhookConfig{URL: "http://example.com", Method: "FETCH"}},
+		{"bad template", WebhookConfig{URL: "http://example.com", Template: "{{ .Nope"}},
+		{"unknown field", WebhookConfig{URL: "http://example.com", Template: "{{ .Nope }}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewWebhookNotifier(tt.cfg); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"a":1}' | openssl dgst -sha256 -hmac key
+	want := "sha256=88a67f24bbcdaed0e6c997404bb79a743baf44c6bab2f4c27328e3009d22e342"
+	if got := Sign([]byte("key"), []byte(`{"a":1}`)); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
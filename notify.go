@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+	"github.com/mohamedbeat/pulse/notifier"
+	"github.com/mohamedbeat/pulse/store"
+)
+
+// consoleNotifier writes alerts to the structured log.
+type consoleNotifier struct{}
+
+func (consoleNotifier) Name() string { return "console" }
+
+func (consoleNotifier) Notify(_ context.Context, alert notifier.Alert) error {
+	args := []any{
+		"endpoint", alert.EndpointID,
+		"url", alert.URL,
+		"old_status", alert.OldStatus,
+		"new_status", alert.NewStatus,
+		"status_code", alert.Result.StatusCode,
+		"elapsed", alert.Result.Elapsed,
+		"messages", alert.Result.Messages,
+		"error", alert.Result.Error,
+	}
+	if alert.IncidentID != 0 {
+		args = append(args, "incident", alert.IncidentID)
+	}
+	if alert.Duration > 0 {
+		args = append(args, "duration", alert.Duration.String())
+	}
+	if alert.Flap != "" {
+		args = append(args, "flap", alert.Flap)
+	}
+	// A group only carries the alerts it stands for
+	if len(alert.Group) > 0 {
+		titles := make([]string, len(alert.Group))
+		for i, a := range alert.Group {
+			titles[i] = a.Title()
+		}
+		args = []any{"alerts", titles}
+	}
+
+	switch {
+	case common.IsFailing(alert.NewStatus):
+		Error(alert.Title(), args...)
+	case alert.NewStatus == common.StatusDegraded:
+		Warn(alert.Title(), args...)
+	default:
+		Info(alert.Title(), args...)
+	}
+	return nil
+}
+
+// recordAlert stores alert as handed to the notifier name; err is why it was not
+// delivered, nil once it was. The alerts of a group are stored one by one.
+func recordAlert(st store.Store, name string, alert notifier.Alert, err error) {
+	if len(alert.Group) > 0 {
+		for _, a := range alert.Group {
+			recordAlert(st, name, a, err)
+		}
+		return
+	}
+
+	record := store.Alert{
+		EndpointID: alert.EndpointID,
+		IncidentID: alert.IncidentID,
+		Notifier:   name,
+		OldStatus:  alert.OldStatus,
+		NewStatus:  alert.NewStatus,
+		Title:      alert.Title(),
+		CreatedAt:  time.Now(),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if err := st.SaveAlert(context.Background(), &record); err != nil {
+		Error("save_alert_failed",
+			"notifier", name,
+			"endpoint", alert.EndpointID,
+			"error", err.Error(),
+		)
+	}
+}
+
+// buildNotifiers creates the notifiers enabled in the config.
+// Unnamed notifiers are named after their kind and position, e.g. "webhook-1".
+func buildNotifiers(cfg Notifications) ([]notifier.Notifier, error) {
+	var notifiers []notifier.Notifier
+	add := func(n notifier.Notifier, err error) error {
+		if err != nil {
+			return err
+		}
+		notifiers = append(notifiers, n)
+		return nil
+	}
+
+	if cfg.Console {
+		notifiers = append(notifiers, consoleNotifier{})
+	}
+	for i, c := range cfg.Webhooks {
+		c.Name = notifierName(c.Name, "webhook", i)
+		if err := add(notifier.NewWebhookNotifier(c)); err != nil {
+			return nil, err
+		}
+	}
+	for i, c := range cfg.Emails {
+		c.Name = notifierName(c.Name, "email", i)
+		if err := add(notifier.NewEmailNotifier(c)); err != nil {
+			return nil, err
+		}
+	}
+	for i, c := range cfg.Slack {
+		c.Name = notifierName(c.Name, "slack", i)
+		if err := add(notifier.NewSlackNotifier(c)); err != nil {
+			return nil, err
+		}
+	}
+	for i, c := range cfg.Discord {
+		c.Name = notifierName(c.Name, "discord", i)
+		if err := add(notifier.NewDiscordNotifier(c)); err != nil {
+			return nil, err
+		}
+	}
+	for i, c := range cfg.Teams {
+		c.Name = notifierName(c.Name, "teams", i)
+		if err := add(notifier.NewTeamsNotifier(c)); err != nil {
+			return nil, err
+		}
+	}
+	for i, c := range cfg.PagerDuty {
+		c.Name = notifierName(c.Name, "pagerduty", i)
+		if err := add(notifier.NewPagerDutyNotifier(c)); err != nil {
+			return nil, err
+		}
+	}
+	for i, c := range cfg.Opsgenie {
+		c.Name = notifierName(c.Name, "opsgenie", i)
+		if err := add(notifier.NewOpsgenieNotifier(c)); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make(map[string]bool, len(notifiers))
+	for _, n := range notifiers {
+		if names[n.Name()] {
+			return nil, fmt.Errorf("duplicate notifier name %q", n.Name())
+		}
+		names[n.Name()] = true
+	}
+	return notifiers, nil
+}
+
+func notifierName(name, kind string, i int) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s-%d", kind, i+1)
+}
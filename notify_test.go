@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/mohamedbeat/pulse/common"
+	"github.com/mohamedbeat/pulse/notifier"
+	"github.com/mohamedbeat/pulse/store"
+)
+
+func TestRecordAlert(t *testing.T) {
+	st, err := store.OpenSQLite(filepath.Join(t.TempDir(), "pulse.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	api := notifier.Alert{EndpointID: "api", IncidentID: 3, OldStatus: common.StatusUp, NewStatus: common.StatusDown}
+	db := notifier.Alert{EndpointID: "db", OldStatus: common.StatusUp, NewStatus: common.StatusDown}
+	recordAlert(st, "slack", api, nil)
+	recordAlert(st, "pagerduty", notifier.GroupAlert(nil, []notifier.Alert{api, db}), errors.New("boom"))
+
+	alerts, err := st.Alerts(context.Background(), store.AlertQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 3 {
+		t.Fatalf("Expected the alert and both grouped ones, got %+v", alerts)
+	}
+	sent, failed := alerts[2], alerts[1]
+	if sent.Notifier != "slack" || sent.IncidentID != 3 || sent.Error != "" || sent.Title != api.Title() {
+		t.Errorf("Expected the delivered alert, got %+v", sent)
+	}
+	if failed.Notifier != "pagerduty" || failed.EndpointID != "api" || failed.Error != "boom" {
+		t.Errorf("Expected the grouped alert of api to be stored as failed, got %+v", failed)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// Retry defaults, applied in applyDefaultsToEndpoints
+const (
+	DefaultRetryBackoff    = 1 * time.Second
+	DefaultRetryMaxBackoff = 30 * time.Second
+	DefaultRetryMultiplier = 2.0
+)
+
+// retryDelay returns the delay before re-check number attempt (1-based):
+// RetryBackoff * RetryMultiplier^(attempt-1), capped at RetryMaxBackoff,
+// with ±RetryJitter of randomness.
+func retryDelay(ep common.Endpoint, attempt int) time.Duration {
+	delay := float64(ep.RetryBackoff) * math.Pow(ep.RetryMultiplier, float64(attempt-1))
+	if ep.RetryMaxBackoff > 0 {
+		delay = math.Min(delay, float64(ep.RetryMaxBackoff))
+	}
+	if ep.RetryJitter > 0 {
+		delay *= 1 + ep.RetryJitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay)
+}
+
+// checkWithRetry runs the check and, while it is not up, re-checks right away
+// following the endpoint's backoff policy. The returned result is the last
+// attempt, carrying every attempt made. ok is false if ctx was done first.
+func (s *Scheduler) checkWithRetry(ctx context.Context, checker Checker, ep common.Endpoint) (result common.Result, ok bool) {
+	start := time.Now()
+	attempts := make([]common.Attempt, 0, 1)
+
+	for attempt := 0; ; attempt++ {
+		release, ok := s.acquire(ctx, hostKey(ep))
+		if !ok {
+			return result, false
+		}
+		result = checker.Check(ctx, ep)
+		release()
+
+		// A check aborted by shutdown, or by pausing or removing the endpoint, is not a real failure
+		if ctx.Err() != nil {
+			return result, false
+		}
+
+		attempts = append(attempts, common.Attempt{
+			Status:     result.Status,
+			StatusCode: result.StatusCode,
+			Timestamp:  result.Timestamp,
+			Elapsed:    result.Elapsed,
+			Error:      result.Error,
+			Messages:   result.Messages,
+		})
+
+		if result.Status == common.StatusUp || attempt >= ep.Retry {
+			break
+		}
+
+		delay := retryDelay(ep, attempt+1)
+		if ep.RetryMaxTime > 0 && time.Since(start)+delay > ep.RetryMaxTime {
+			Warn("retry_budget_exhausted",
+				"endpoint", ep.Name,
+				"url", ep.URL,
+				"attempts", len(attempts),
+				"retry_max_time", ep.RetryMaxTime.String(),
+			)
+			break
+		}
+
+		Warn("check_retry",
+			"endpoint", ep.Name,
+			"url", ep.URL,
+			"attempt", len(attempts),
+			"status", result.Status,
+			"error", result.Error,
+			"messages", result.Messages,
+			"next_in", delay.String(),
+		)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return result, false
+		}
+	}
+
+	if len(attempts) > 1 {
+		result.Attempts = attempts
+	}
+	return result, true
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+func TestRetryDelay(t *testing.T) {
+	ep := common.Endpoint{RetryBackoff: time.Second, RetryMultiplier: 2}
+	capped := ep
+	capped.RetryMaxBackoff = 5 * time.Second
+
+	tests := []struct {
+		name    string
+		ep      common.Endpoint
+		attempt int
+		want    time.Duration
+	}{
+		{"first re-check", ep, 1, time.Second},
+		{"grows", ep, 2, 2 * time.Second},
+		{"grows exponentially", ep, 4, 8 * time.Second},
+		{"below the cap", capped, 3, 4 * time.Second},
+		{"capped", capped, 4, 5 * time.Second},
+		{"stays capped", capped, 10, 5 * time.Second},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := retryDelay(tc.ep, tc.attempt); got != tc.want {
+				t.Errorf("Expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRetryDelay_Jitter(t *testing.T) {
+	ep := common.Endpoint{RetryBackoff: time.Second, RetryMultiplier: 2, RetryMaxBackoff: 30 * time.Second, RetryJitter: 0.2}
+	lo, hi := 1600*time.Millisecond, 2400*time.Millisecond
+
+	varied := false
+	for range 200 {
+		d := retryDelay(ep, 2)
+		if d < lo || d > hi {
+			t.Fatalf("Expected a delay within ±20%% of 2s, got %s", d)
+		}
+		varied = varied || d != 2*time.Second
+	}
+	if !varied {
+		t.Errorf("Expected jitter to vary the delay")
+	}
+}
+
+// failingChecker returns a checker that is down for its first failures checks,
+// then up, recording when every check ran.
+func failingChecker(failures int) (*fakeChecker, func() []time.Time) {
+	var (
+		mu    sync.Mutex
+		times []time.Time
+	)
+	checker := &fakeChecker{}
+	checker.check = func(ctx context.Context, ep common.Endpoint) common.Result {
+		mu.Lock()
+		times = append(times, time.Now())
+		n := len(times)
+		mu.Unlock()
+
+		res := upResult(ep)
+		if n <= failures {
+			res.Status, res.Error = common.StatusDown, "connection refused"
+		}
+		return res
+	}
+	return checker, func() []time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return times
+	}
+}
+
+// retryEndpoint is checked every interval with the retry defaults of the config.
+func retryEndpoint(retry int, backoff, interval time.Duration) common.Endpoint {
+	ep := common.Endpoint{ID: "api", Type: common.HTTPType, URL: "http://api.local", Retry: retry, RetryBackoff: backoff, Interval: interval}
+	applyDefaultsToEndpoint(Globals{}, &ep)
+	return ep
+}
+
+func TestCheckWithRetry_RecoversWithBackoff(t *testing.T) {
+	checker, times := failingChecker(2)
+	s := newTestScheduler(checker, SchedulerOptions{})
+	ep := retryEndpoint(3, 20*time.Millisecond, time.Minute)
+
+	res, ok := s.checkWithRetry(context.Background(), checker, ep)
+	if !ok || res.Status != common.StatusUp {
+		t.Fatalf("Expected the check to recover, got %+v (ok=%t)", res, ok)
+	}
+	if len(res.Attempts) != 3 || res.Attempts[0].Status != common.StatusDown || res.Attempts[2].Status != common.StatusUp {
+		t.Errorf("Expected 2 failed attempts then an up one, got %+v", res.Attempts)
+	}
+
+	at := times()
+	if len(at) != 3 {
+		t.Fatalf("Expected 3 checks, got %d", len(at))
+	}
+	// Backoff doubles between re-checks: 20ms, then 40ms
+	if gap := at[1].Sub(at[0]); gap < 20*time.Millisecond {
+		t.Errorf("Expected the first re-check after 20ms, got %s", gap)
+	}
+	if gap := at[2].Sub(at[1]); gap < 40*time.Millisecond {
+		t.Errorf("Expected the second re-check after 40ms, got %s", gap)
+	}
+}
+
+func TestCheckWithRetry_GivesUp(t *testing.T) {
+	checker, _ := failingChecker(10)
+	s := newTestScheduler(checker, SchedulerOptions{})
+
+	res, ok := s.checkWithRetry(context.Background(), checker, retryEndpoint(2, time.Millisecond, time.Minute))
+	if !ok || res.Status != common.StatusDown {
+		t.Fatalf("Expected the last failure, got %+v (ok=%t)", res, ok)
+	}
+	if len(res.Attempts) != 3 || checker.calls.Load() != 3 {
+		t.Errorf("Expected 3 attempts, got %d (%d checks)", len(res.Attempts), checker.calls.Load())
+	}
+}
+
+func TestCheckWithRetry_MaxTimeDefaultsToInterval(t *testing.T) {
+	checker, _ := failingChecker(10)
+	s := newTestScheduler(checker, SchedulerOptions{})
+	ep := retryEndpoint(5, 20*time.Millisecond, 50*time.Millisecond)
+	if ep.RetryMaxTime != ep.Interval {
+		t.Fatalf("Expected retry_max_time to default to the interval, got %s", ep.RetryMaxTime)
+	}
+
+	// 20ms then 40ms of backoff would end past the 50ms budget
+	res, ok := s.checkWithRetry(context.Background(), checker, ep)
+	if !ok || len(res.Attempts) != 2 {
+		t.Errorf("Expected the budget to stop after 2 attempts, got %d (ok=%t)", len(res.Attempts), ok)
+	}
+}
+
+func TestCheckWithRetry_SingleAttempt(t *testing.T) {
+	checker, _ := failingChecker(0)
+	s := newTestScheduler(checker, SchedulerOptions{})
+
+	res, ok := s.checkWithRetry(context.Background(), checker, retryEndpoint(3, time.Millisecond, time.Minute))
+	if !ok || res.Status != common.StatusUp || res.Attempts != nil {
+		t.Errorf("Expected a single attempt left unrecorded, got %+v (ok=%t)", res, ok)
+	}
+}
+
+func TestCheckWithRetry_StopsOnCancel(t *testing.T) {
+	checker, _ := failingChecker(10)
+	s := newTestScheduler(checker, SchedulerOptions{})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, ok := s.checkWithRetry(ctx, checker, retryEndpoint(3, time.Hour, 2*time.Hour))
+	if ok {
+		t.Errorf("Expected a cancelled check not to be reported")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the backoff to be cut short by the cancellation, took %s", elapsed)
+	}
+	if n := checker.calls.Load(); n != 1 {
+		t.Errorf("Expected no re-check after the cancellation, got %d checks", n)
+	}
+}
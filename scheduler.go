@@ -3,19 +3,88 @@ package main
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mohamedbeat/pulse/common"
 )
 
+// SchedulerOptions controls how checks are spread and limited.
+type SchedulerOptions struct {
+	MaxConcurrency int           // in-flight checks across all endpoints, 0 means unlimited
+	MaxPerHost     int           // in-flight checks against a single host, 0 means unlimited
+	Jitter         time.Duration // random delay added to every interval
+	// InMaintenance, when set, flags the results of endpoints under maintenance
+	InMaintenance func(ep common.Endpoint, at time.Time) bool
+}
+
 type Scheduler struct {
-	endpoints []common.Endpoint
-	checkers  map[string]Checker // "HTTP" → HTTPChecker, etc.
-	results   chan common.Result
-	stop      chan struct{}
+	checkers map[string]Checker // "HTTP" → HTTPChecker, etc.
+	results  chan common.Result
+	opts     SchedulerOptions
+
+	// Endpoints can be added, changed, paused and removed while the scheduler runs
+	endpointsMu sync.Mutex
+	endpoints   []common.Endpoint  // in configuration order, then in the order they were added
+	workers     map[string]*worker // running worker of every endpoint that is not paused
+	paused      map[string]bool
+	started     bool
+
+	global  chan struct{}            // global in-flight semaphore, nil if unlimited
+	mu      sync.Mutex               // guards perHost
+	perHost map[string]chan struct{} // per-host in-flight semaphores
+	skipped atomic.Uint64            // ticks skipped because the previous check was still running
+
+	// ctx is cancelled on Stop; every worker runs under a child of it so its
+	// in-flight check is aborted when the worker or the scheduler stops
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup // tracks runEndpoint workers, which wait for their in-flight checks
+}
+
+// worker runs the checks of an endpoint.
+type worker struct {
+	cancel context.CancelFunc // stops the worker and aborts its in-flight check
+	done   chan struct{}      // closed once the worker and its in-flight check have exited
 }
 
+// NewScheduler creates a scheduler for endpoints whose results are buffered up to bufferSize.
+func NewScheduler(endpoints []common.Endpoint, checkers map[string]Checker, bufferSize int, opts SchedulerOptions) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		checkers:  checkers,
+		results:   make(chan common.Result, bufferSize),
+		opts:      opts,
+		endpoints: slices.Clone(endpoints),
+		workers:   make(map[string]*worker),
+		paused:    make(map[string]bool),
+		perHost:   make(map[string]chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	if opts.MaxConcurrency > 0 {
+		s.global = make(chan struct{}, opts.MaxConcurrency)
+	}
+	return s
+}
+
+// Results returns the channel results are published on.
+// It is closed by Shutdown once every worker has exited.
+func (s *Scheduler) Results() <-chan common.Result {
+	return s.results
+}
+
+// Start runs the checks of every endpoint that is not paused.
 func (s *Scheduler) Start() {
+	s.endpointsMu.Lock()
+	defer s.endpointsMu.Unlock()
+
 	// Validate all endpoints have checkers before starting
 	for _, ep := range s.endpoints {
 		if _, ok := s.checkers[ep.Type]; !ok {
@@ -28,62 +97,379 @@ func (s *Scheduler) Start() {
 		}
 	}
 
-	for _, ep := range s.endpoints {
-		go s.runEndpoint(ep) // one goroutine per endpoint
+	offsets := staggerOffsets(s.endpoints)
+	for i, ep := range s.endpoints {
+		if !s.paused[ep.ID] {
+			s.startWorker(ep, offsets[i])
+		}
+	}
+	s.started = true
+}
+
+// startWorker runs the checks of ep in its own goroutine, the first one after
+// offset. Callers hold endpointsMu.
+func (s *Scheduler) startWorker(ep common.Endpoint, offset time.Duration) {
+	// Stop holds endpointsMu too, so no worker is added once Shutdown waits for them
+	if s.ctx.Err() != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	w := &worker{cancel: cancel, done: make(chan struct{})}
+	s.workers[ep.ID] = w
+	s.wg.Add(1)
+	go s.runEndpoint(ctx, ep, offset, w.done) // one goroutine per endpoint
+}
+
+// stopWorker stops the worker of an endpoint, aborting its in-flight check, and
+// waits for it to exit so it publishes nothing once stopWorker returns.
+// Callers hold endpointsMu.
+func (s *Scheduler) stopWorker(id string) {
+	w, ok := s.workers[id]
+	if !ok {
+		return
+	}
+	w.cancel()
+	delete(s.workers, id)
+	<-w.done
+}
+
+// Endpoints returns the monitored endpoints, paused ones included.
+func (s *Scheduler) Endpoints() []common.Endpoint {
+	s.endpointsMu.Lock()
+	defer s.endpointsMu.Unlock()
+	return slices.Clone(s.endpoints)
+}
+
+// Endpoint returns the monitored endpoint with the given ID.
+func (s *Scheduler) Endpoint(id string) (common.Endpoint, bool) {
+	s.endpointsMu.Lock()
+	defer s.endpointsMu.Unlock()
+	if i := s.index(id); i >= 0 {
+		return s.endpoints[i], true
+	}
+	return common.Endpoint{}, false
+}
+
+// index returns the position of an endpoint in s.endpoints, -1 if unknown.
+// Callers hold endpointsMu.
+func (s *Scheduler) index(id string) int {
+	return slices.IndexFunc(s.endpoints, func(ep common.Endpoint) bool { return ep.ID == id })
+}
+
+// Paused reports whether the checks of an endpoint are paused.
+func (s *Scheduler) Paused(id string) bool {
+	s.endpointsMu.Lock()
+	defer s.endpointsMu.Unlock()
+	return s.paused[id]
+}
+
+// Add starts monitoring ep. Once started, its first check runs right away.
+func (s *Scheduler) Add(ep common.Endpoint) error {
+	if _, ok := s.checkers[ep.Type]; !ok {
+		return fmt.Errorf("no checker registered for type %q", ep.Type)
+	}
+
+	s.endpointsMu.Lock()
+	defer s.endpointsMu.Unlock()
+	if s.index(ep.ID) >= 0 {
+		return fmt.Errorf("endpoint %q already exists", ep.ID)
+	}
+	s.endpoints = append(s.endpoints, ep)
+	if s.started {
+		s.startWorker(ep, 0)
+	}
+	return nil
+}
+
+// Update replaces the definition of an endpoint. Unless it is paused, its
+// in-flight check is aborted and it is checked again right away; results of the
+// old definition are no longer published once Update returns.
+func (s *Scheduler) Update(ep common.Endpoint) error {
+	if _, ok := s.checkers[ep.Type]; !ok {
+		return fmt.Errorf("no checker registered for type %q", ep.Type)
+	}
+
+	s.endpointsMu.Lock()
+	defer s.endpointsMu.Unlock()
+	i := s.index(ep.ID)
+	if i < 0 {
+		return fmt.Errorf("unknown endpoint %q", ep.ID)
+	}
+	s.endpoints[i] = ep
+	if s.started && !s.paused[ep.ID] {
+		s.stopWorker(ep.ID)
+		s.startWorker(ep, 0)
+	}
+	return nil
+}
+
+// Remove stops monitoring an endpoint, aborting its in-flight check. None of its
+// results are published once Remove returns.
+func (s *Scheduler) Remove(id string) error {
+	s.endpointsMu.Lock()
+	defer s.endpointsMu.Unlock()
+	i := s.index(id)
+	if i < 0 {
+		return fmt.Errorf("unknown endpoint %q", id)
+	}
+	s.stopWorker(id)
+	s.endpoints = slices.Delete(s.endpoints, i, i+1)
+	delete(s.paused, id)
+	return nil
+}
+
+// Pause stops the checks of an endpoint until Resume, aborting its in-flight check.
+func (s *Scheduler) Pause(id string) error {
+	s.endpointsMu.Lock()
+	defer s.endpointsMu.Unlock()
+	if s.index(id) < 0 {
+		return fmt.Errorf("unknown endpoint %q", id)
+	}
+	s.stopWorker(id)
+	s.paused[id] = true
+	return nil
+}
+
+// Resume restarts the checks of a paused endpoint, the first one right away.
+func (s *Scheduler) Resume(id string) error {
+	s.endpointsMu.Lock()
+	defer s.endpointsMu.Unlock()
+	i := s.index(id)
+	if i < 0 {
+		return fmt.Errorf("unknown endpoint %q", id)
+	}
+	if !s.paused[id] {
+		return nil
+	}
+	delete(s.paused, id)
+	if s.started {
+		s.startWorker(s.endpoints[i], 0)
+	}
+	return nil
+}
+
+// staggerOffsets spreads the first check of endpoints sharing the same interval
+// evenly across that interval, so they don't all fire at the same instant.
+func staggerOffsets(endpoints []common.Endpoint) []time.Duration {
+	counts := make(map[time.Duration]int)
+	for _, ep := range endpoints {
+		counts[ep.Interval]++
 	}
+
+	seen := make(map[time.Duration]int)
+	offsets := make([]time.Duration, len(endpoints))
+	for i, ep := range endpoints {
+		offsets[i] = ep.Interval * time.Duration(seen[ep.Interval]) / time.Duration(counts[ep.Interval])
+		seen[ep.Interval]++
+	}
+	return offsets
+}
+
+// nextInterval returns interval plus a random jitter in [0, opts.Jitter).
+func (s *Scheduler) nextInterval(interval time.Duration) time.Duration {
+	if s.opts.Jitter <= 0 {
+		return interval
+	}
+	return interval + rand.N(s.opts.Jitter)
 }
 
-func (s *Scheduler) runEndpoint(ep common.Endpoint) {
-	ticker := time.NewTicker(ep.Interval)
-	defer ticker.Stop()
+// runEndpoint checks ep every interval until ctx is done, then waits for its
+// in-flight check and closes done.
+func (s *Scheduler) runEndpoint(ctx context.Context, ep common.Endpoint, offset time.Duration, done chan struct{}) {
+	defer s.wg.Done()
+	defer close(done)
+	var checks sync.WaitGroup
+	defer checks.Wait()
+
+	timer := time.NewTimer(offset)
+	defer timer.Stop()
+
+	// running holds a token while a check of this endpoint is in flight
+	running := make(chan struct{}, 1)
 
 	for {
 		select {
-		case <-ticker.C:
-			checker, ok := s.checkers[ep.Type]
-			if !ok {
-				Error("missing_checker",
+		case <-timer.C:
+			timer.Reset(s.nextInterval(ep.Interval))
+
+			select {
+			case running <- struct{}{}:
+			default:
+				s.skipped.Add(1)
+				Warn("check_skipped",
 					"endpoint", ep.Name,
 					"url", ep.URL,
-					"type", ep.Type,
-					"message", "No checker registered for endpoint type, skipping check",
+					"message", "Previous check still running, skipping this tick",
 				)
-
-				messages := make([]string, 0)
-				messages = append(messages, fmt.Sprintf("Checker for type %q not found", ep.Type))
-
-				// Send an error result to maintain consistency
-				s.results <- common.Result{
-					URL:       ep.URL,
-					Status:    common.StatusUnreachable,
-					Timestamp: time.Now(),
-					Error:     "no checker registered for type",
-					Messages:  messages,
-				}
 				continue
 			}
 
-			res := checker.Check(context.Background(), ep)
+			checks.Add(1)
+			go func() {
+				defer checks.Done()
+				defer func() { <-running }()
+				s.runCheck(ctx, ep)
+			}()
+		case <-ctx.Done(): // in this case we stop
+			return
+		}
+	}
+}
 
-			// check results for retry
-			if res.Status != common.StatusUp && ep.RetryCounter > 0 {
-				Warn("Bad result", res)
-				Warn("Retrying")
-				ep.LastResult = &res
-				ep.RetryCounter -= 1
-				continue
-			}
+// runCheck performs a check of ep, retrying it if needed, and publishes its result.
+// The check is aborted once ctx is done.
+func (s *Scheduler) runCheck(ctx context.Context, ep common.Endpoint) {
+	checker, ok := s.checkers[ep.Type]
+	if !ok {
+		Error("missing_checker",
+			"endpoint", ep.Name,
+			"url", ep.URL,
+			"type", ep.Type,
+			"message", "No checker registered for endpoint type, skipping check",
+		)
 
-			// Resetting RetryCounter and LastResult
-			ep.RetryCounter = ep.Retry
-			ep.LastResult = nil
+		messages := make([]string, 0)
+		messages = append(messages, fmt.Sprintf("Checker for type %q not found", ep.Type))
 
-			s.results <- res
-		case <-s.stop: // in this case we stop
-			return
+		// Send an error result to maintain consistency
+		s.publish(ctx, s.flag(ep, common.Result{
+			EndpointID: ep.ID,
+			URL:        ep.URL,
+			Status:     common.StatusUnreachable,
+			Timestamp:  time.Now(),
+			Error:      "no checker registered for type",
+			Messages:   messages,
+		}))
+		return
+	}
+
+	res, ok := s.checkWithRetry(ctx, checker, ep)
+	if !ok {
+		return
+	}
+
+	// Checkers only know about URLs; tag the result with the endpoint it belongs to
+	res.EndpointID = ep.ID
+	s.publish(ctx, s.flag(ep, res))
+}
+
+// flag marks res as checked during maintenance when ep is under maintenance.
+func (s *Scheduler) flag(ep common.Endpoint, res common.Result) common.Result {
+	if s.opts.InMaintenance != nil {
+		res.Maintenance = s.opts.InMaintenance(ep, res.Timestamp)
+	}
+	return res
+}
+
+// acquire blocks until a global and a per-host slot are available.
+// It returns false if ctx was done while waiting.
+func (s *Scheduler) acquire(ctx context.Context, host string) (release func(), ok bool) {
+	hostSem := s.hostSemaphore(host)
+
+	if s.global != nil {
+		select {
+		case s.global <- struct{}{}:
+		case <-ctx.Done():
+			return nil, false
 		}
 	}
+	if hostSem != nil {
+		select {
+		case hostSem <- struct{}{}:
+		case <-ctx.Done():
+			if s.global != nil {
+				<-s.global
+			}
+			return nil, false
+		}
+	}
+
+	return func() {
+		if hostSem != nil {
+			<-hostSem
+		}
+		if s.global != nil {
+			<-s.global
+		}
+	}, true
+}
+
+// hostSemaphore returns the semaphore limiting checks against host, or nil if unlimited.
+func (s *Scheduler) hostSemaphore(host string) chan struct{} {
+	if s.opts.MaxPerHost <= 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sem, ok := s.perHost[host]
+	if !ok {
+		sem = make(chan struct{}, s.opts.MaxPerHost)
+		s.perHost[host] = sem
+	}
+	return sem
+}
+
+// hostKey returns the host an endpoint's checks are sent to.
+func hostKey(ep common.Endpoint) string {
+	if ep.Type == common.DNSType && ep.DNS.Resolver != "" {
+		return ep.DNS.Resolver
+	}
+	if u, err := url.Parse(ep.URL); err == nil && u.Hostname() != "" && strings.Contains(ep.URL, "://") {
+		return u.Hostname()
+	}
+	if addr, err := common.ParseHostPort(ep.URL); err == nil {
+		host, _, _ := net.SplitHostPort(addr)
+		return host
+	}
+	return ep.URL
+}
+
+// Skipped returns how many ticks were skipped because the previous check was still running.
+func (s *Scheduler) Skipped() uint64 {
+	return s.skipped.Load()
+}
+
+// publish sends res on the results channel, giving up once ctx is done: the
+// result of a check that completed as its worker was stopped is dropped.
+func (s *Scheduler) publish(ctx context.Context, res common.Result) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case s.results <- res:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
+
+// Stop signals all workers to stop and cancels in-flight checks.
 func (s *Scheduler) Stop() {
-	close(s.stop) // Signal all goroutines to stop
+	s.endpointsMu.Lock()
+	defer s.endpointsMu.Unlock()
+	s.cancel()
+}
+
+// Shutdown stops the scheduler and waits up to timeout for every worker to exit.
+// The results channel is closed only once all workers are gone, so no worker can
+// ever send on a closed channel. If the deadline passes first, an error is returned
+// and the channel is left open.
+func (s *Scheduler) Shutdown(timeout time.Duration) error {
+	s.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		close(s.results)
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("shutdown deadline of %s exceeded, workers still running", timeout)
+	}
 }
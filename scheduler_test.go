@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// fakeChecker answers checks with check, counting them and the ones in flight.
+type fakeChecker struct {
+	check       func(ctx context.Context, ep common.Endpoint) common.Result
+	calls       atomic.Int32
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (f *fakeChecker) Check(ctx context.Context, ep common.Endpoint) common.Result {
+	f.calls.Add(1)
+	n := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		peak := f.maxInFlight.Load()
+		if n <= peak || f.maxInFlight.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	return f.check(ctx, ep)
+}
+
+func upResult(ep common.Endpoint) common.Result {
+	return common.Result{URL: ep.URL, Status: common.StatusUp, Timestamp: time.Now()}
+}
+
+func testEndpoint(id string, interval time.Duration) common.Endpoint {
+	return common.Endpoint{
+		ID:              id,
+		Name:            id,
+		Type:            common.HTTPType,
+		URL:             "http://" + id + ".local",
+		Interval:        interval,
+		Timeout:         time.Second,
+		RetryMultiplier: DefaultRetryMultiplier,
+	}
+}
+
+func newTestScheduler(checker Checker, opts SchedulerOptions, endpoints ...common.Endpoint) *Scheduler {
+	return NewScheduler(endpoints, map[string]Checker{common.HTTPType: checker}, 10, opts)
+}
+
+// waitFor fails the test if ch does not receive within a second.
+func waitFor(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for %s", what)
+	}
+}
+
+func TestScheduler_ShutdownCancelsInFlightChecks(t *testing.T) {
+	started := make(chan struct{}, 1)
+	var finished atomic.Bool
+	checker := &fakeChecker{check: func(ctx context.Context, ep common.Endpoint) common.Result {
+		started <- struct{}{}
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond) // still cleaning up when Shutdown starts waiting
+		finished.Store(true)
+		return common.Result{URL: ep.URL, Status: common.StatusUnreachable, Error: ctx.Err().Error()}
+	}}
+	s := newTestScheduler(checker, SchedulerOptions{}, testEndpoint("api", time.Hour))
+	s.Start()
+	waitFor(t, started, "the first check")
+
+	if err := s.Shutdown(time.Second); err != nil {
+		t.Fatalf("Expected a clean shutdown, got %v", err)
+	}
+	if !finished.Load() {
+		t.Errorf("Expected the in-flight check to finish before Shutdown returned")
+	}
+	// The aborted check is not reported and the channel is closed
+	if res, ok := <-s.Results(); ok {
+		t.Errorf("Expected the results channel to be closed, got %+v", res)
+	}
+}
+
+func TestScheduler_ShutdownTimeout(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	checker := &fakeChecker{check: func(ctx context.Context, ep common.Endpoint) common.Result {
+		started <- struct{}{}
+		<-release // ignores ctx
+		return upResult(ep)
+	}}
+	s := newTestScheduler(checker, SchedulerOptions{}, testEndpoint("api", time.Hour))
+	s.Start()
+	waitFor(t, started, "the first check")
+
+	if err := s.Shutdown(50 * time.Millisecond); err == nil {
+		t.Errorf("Expected an error when workers outlive the deadline")
+	}
+	select {
+	case _, ok := <-s.Results():
+		if !ok {
+			t.Errorf("Expected the results channel to stay open while workers run")
+		}
+	default:
+	}
+
+	close(release)
+	s.wg.Wait()
+}
+
+func TestStaggerOffsets(t *testing.T) {
+	tests := []struct {
+		name      string
+		intervals []time.Duration
+		want      []time.Duration
+	}{
+		{"single", []time.Duration{time.Minute}, []time.Duration{0}},
+		{
+			"shared interval",
+			[]time.Duration{time.Minute, time.Minute, time.Minute, time.Minute},
+			[]time.Duration{0, 15 * time.Second, 30 * time.Second, 45 * time.Second},
+		},
+		{
+			"spread per interval",
+			[]time.Duration{10 * time.Second, time.Minute, 10 * time.Second, time.Minute},
+			[]time.Duration{0, 0, 5 * time.Second, 30 * time.Second},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoints := make([]common.Endpoint, len(tc.intervals))
+			for i, interval := range tc.intervals {
+				endpoints[i] = common.Endpoint{Interval: interval}
+			}
+			got := staggerOffsets(endpoints)
+			for i := range tc.want {
+				if got[i] != tc.want[i] {
+					t.Errorf("Expected offsets %v, got %v", tc.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestHostKey(t *testing.T) {
+	tests := []struct {
+		name string
+		ep   common.Endpoint
+		want string
+	}{
+		{"http url", common.Endpoint{Type: common.HTTPType, URL: "https://api.example.com:8443/health"}, "api.example.com"},
+		{"tcp address", common.Endpoint{Type: common.TCPType, URL: "db.local:5432"}, "db.local"},
+		{"tcp url", common.Endpoint{Type: common.TCPType, URL: "tcp://10.0.0.5:6379"}, "10.0.0.5"},
+		{"tls ipv6", common.Endpoint{Type: common.TLSType, URL: "[::1]:443"}, "::1"},
+		{"dns resolver", common.Endpoint{Type: common.DNSType, URL: "example.com", DNS: common.DNSOptions{Resolver: "1.1.1.1:53"}}, "1.1.1.1:53"},
+		{"dns system resolver", common.Endpoint{Type: common.DNSType, URL: "example.com"}, "example.com"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := hostKey(tc.ep); got != tc.want {
+				t.Errorf("Expected host %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestScheduler_Limits(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      SchedulerOptions
+		endpoints []common.Endpoint
+		want      int32 // most checks in flight at once
+	}{
+		{
+			name: "global",
+			opts: SchedulerOptions{MaxConcurrency: 2},
+			endpoints: []common.Endpoint{
+				testEndpoint("a", 10*time.Millisecond), testEndpoint("b", 10*time.Millisecond),
+				testEndpoint("c", 10*time.Millisecond), testEndpoint("d", 10*time.Millisecond),
+			},
+			want: 2,
+		},
+		{
+			name:      "per host",
+			opts:      SchedulerOptions{MaxPerHost: 1},
+			endpoints: sameHost(testEndpoint("a", 10*time.Millisecond), testEndpoint("b", 10*time.Millisecond), testEndpoint("c", 10*time.Millisecond)),
+			want:      1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := &fakeChecker{check: func(ctx context.Context, ep common.Endpoint) common.Result {
+				select {
+				case <-time.After(50 * time.Millisecond):
+				case <-ctx.Done():
+				}
+				return upResult(ep)
+			}}
+			s := newTestScheduler(checker, tc.opts, tc.endpoints...)
+			go func() {
+				for range s.Results() {
+				}
+			}()
+			s.Start()
+			time.Sleep(300 * time.Millisecond)
+			if err := s.Shutdown(time.Second); err != nil {
+				t.Fatal(err)
+			}
+
+			if peak := checker.maxInFlight.Load(); peak != tc.want {
+				t.Errorf("Expected at most %d checks in flight, got %d", tc.want, peak)
+			}
+			// Checks take longer than the interval, so ticks are skipped
+			if s.Skipped() == 0 {
+				t.Errorf("Expected ticks to be skipped while checks run")
+			}
+		})
+	}
+}
+
+// sameHost points every endpoint at the same host.
+func sameHost(endpoints ...common.Endpoint) []common.Endpoint {
+	for i := range endpoints {
+		endpoints[i].URL = "http://shared.local/" + endpoints[i].ID
+	}
+	return endpoints
+}
+
+// receive fails the test if no result is published within a second.
+func receive(t *testing.T, s *Scheduler) common.Result {
+	t.Helper()
+	select {
+	case res := <-s.Results():
+		return res
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for a result")
+		return common.Result{}
+	}
+}
+
+// expectNoResult fails the test if a result is waiting to be read.
+func expectNoResult(t *testing.T, s *Scheduler) {
+	t.Helper()
+	select {
+	case res := <-s.Results():
+		t.Errorf("Expected no result, got %+v", res)
+	default:
+	}
+}
+
+func TestScheduler_AddPauseResume(t *testing.T) {
+	checker := &fakeChecker{check: func(ctx context.Context, ep common.Endpoint) common.Result { return upResult(ep) }}
+	s := newTestScheduler(checker, SchedulerOptions{}, testEndpoint("api", time.Hour))
+	defer s.Shutdown(time.Second)
+
+	// Endpoints added before Start are started with the others (staggered on
+	// their own interval)
+	if err := s.Add(testEndpoint("web", 2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+	got := []string{receive(t, s).EndpointID, receive(t, s).EndpointID}
+	if !slices.Contains(got, "api") || !slices.Contains(got, "web") {
+		t.Errorf("Expected a first check of api and web, got %v", got)
+	}
+
+	// Added afterwards, an endpoint is checked right away
+	if err := s.Add(testEndpoint("db", time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if res := receive(t, s); res.EndpointID != "db" {
+		t.Errorf("Expected a check of db, got %+v", res)
+	}
+	if err := s.Add(testEndpoint("db", time.Hour)); err == nil {
+		t.Errorf("Expected adding db twice to fail")
+	}
+	unknown := testEndpoint("ftp", time.Hour)
+	unknown.Type = "ftp"
+	if err := s.Add(unknown); err == nil {
+		t.Errorf("Expected an endpoint without checker to be refused")
+	}
+
+	if err := s.Pause("db"); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Paused("db") || s.workers["db"] != nil {
+		t.Errorf("Expected the worker of db to be stopped")
+	}
+	if _, ok := s.Endpoint("db"); !ok {
+		t.Errorf("Expected a paused endpoint to stay monitored")
+	}
+	if err := s.Resume("db"); err != nil {
+		t.Fatal(err)
+	}
+	if res := receive(t, s); res.EndpointID != "db" || s.Paused("db") {
+		t.Errorf("Expected db to be checked again once resumed, got %+v", res)
+	}
+	if err := s.Pause("missing"); err == nil {
+		t.Errorf("Expected pausing an unknown endpoint to fail")
+	}
+}
+
+func TestScheduler_UpdateReplacesWorker(t *testing.T) {
+	started := make(chan struct{}, 1)
+	checker := &fakeChecker{check: func(ctx context.Context, ep common.Endpoint) common.Result {
+		if ep.URL == "http://api.local" {
+			started <- struct{}{}
+			<-ctx.Done() // completes as it is aborted
+		}
+		return upResult(ep)
+	}}
+	s := newTestScheduler(checker, SchedulerOptions{}, testEndpoint("api", time.Hour))
+	defer s.Shutdown(time.Second)
+	s.Start()
+	waitFor(t, started, "the first check")
+
+	ep := testEndpoint("api", time.Hour)
+	ep.URL = "http://api.local/v2"
+	if err := s.Update(ep); err != nil {
+		t.Fatal(err)
+	}
+	// The old worker is gone: only the new definition is checked and published
+	if res := receive(t, s); res.URL != ep.URL {
+		t.Errorf("Expected the result of the new definition, got %+v", res)
+	}
+	if got, _ := s.Endpoint("api"); got.URL != ep.URL {
+		t.Errorf("Expected the new definition, got %+v", got)
+	}
+	if err := s.Update(testEndpoint("missing", time.Hour)); err == nil {
+		t.Errorf("Expected updating an unknown endpoint to fail")
+	}
+}
+
+func TestScheduler_RemoveStopsPublishing(t *testing.T) {
+	started := make(chan struct{}, 1)
+	checker := &fakeChecker{check: func(ctx context.Context, ep common.Endpoint) common.Result {
+		started <- struct{}{}
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond) // still finishing when Remove starts waiting
+		return upResult(ep)
+	}}
+	s := newTestScheduler(checker, SchedulerOptions{}, testEndpoint("api", time.Hour))
+	defer s.Shutdown(time.Second)
+	s.Start()
+	waitFor(t, started, "the first check")
+
+	if err := s.Remove("api"); err != nil {
+		t.Fatal(err)
+	}
+	if n := checker.inFlight.Load(); n != 0 {
+		t.Errorf("Expected Remove to wait for the in-flight check, %d still running", n)
+	}
+	expectNoResult(t, s)
+	if _, ok := s.Endpoint("api"); ok || len(s.Endpoints()) != 0 {
+		t.Errorf("Expected api to be removed, got %+v", s.Endpoints())
+	}
+	if err := s.Remove("api"); err == nil {
+		t.Errorf("Expected removing api twice to fail")
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+	"github.com/mohamedbeat/pulse/maintenance"
+	"github.com/mohamedbeat/pulse/store"
+)
+
+// silenceRefresh is how often silences created by other processes (the CLI) are picked up.
+const silenceRefresh = 15 * time.Second
+
+// syncSilences reloads the silences from the store into m until ctx is cancelled.
+func syncSilences(ctx context.Context, st store.Store, m *maintenance.Manager) {
+	ticker := time.NewTicker(silenceRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		silences, err := st.Silences(ctx, time.Now())
+		if err != nil {
+			if ctx.Err() == nil {
+				Error("silences_refresh_failed", "error", err.Error())
+			}
+			continue
+		}
+		m.SetSilences(silences)
+	}
+}
+
+// runSilenceCommand implements "pulse silence add|list|expire" and returns the exit code.
+func runSilenceCommand(envs Env, args []string) int {
+	usage := func() int {
+		fmt.Fprintln(os.Stderr, "usage: pulse silence add -for 2h (-endpoint ID | -tag TAG | -match 'team=payments')... [-start RFC3339] [-comment TEXT]")
+		fmt.Fprintln(os.Stderr, "       pulse silence list")
+		fmt.Fprintln(os.Stderr, "       pulse silence expire ID")
+		return 2
+	}
+	if len(args) == 0 {
+		return usage()
+	}
+
+	st, err := store.Open(envs.Dbdriver, envs.Dbname)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	switch args[0] {
+	case "add":
+		var (
+			silence common.Silence
+			start   string
+			length  time.Duration
+		)
+		fs := flag.NewFlagSet("silence add", flag.ContinueOnError)
+		fs.Func("endpoint", "endpoint ID to silence (repeatable)", func(v string) error {
+			silence.Endpoints = append(silence.Endpoints, v)
+			return nil
+		})
+		fs.Func("tag", "tag of the endpoints to silence (repeatable)", func(v string) error {
+			silence.Tags = append(silence.Tags, v)
+			return nil
+		})
+		fs.Func("match", "label matcher the endpoints to silence must match, e.g. team=payments (repeatable, all must match)", func(v string) error {
+			silence.Matchers = append(silence.Matchers, v)
+			return nil
+		})
+		fs.DurationVar(&length, "for", 0, "how long the silence lasts")
+		fs.StringVar(&start, "start", "", "when the silence starts, RFC 3339 (default now)")
+		fs.StringVar(&silence.Comment, "comment", "", "why the endpoints are silenced")
+		fs.StringVar(&silence.CreatedBy, "by", os.Getenv("USER"), "who created the silence")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+
+		silence.CreatedAt = time.Now()
+		silence.StartsAt = silence.CreatedAt
+		if start != "" {
+			if silence.StartsAt, err = time.Parse(time.RFC3339, start); err != nil {
+				fmt.Fprintln(os.Stderr, "invalid start:", err)
+				return 2
+			}
+		}
+		silence.EndsAt = silence.StartsAt.Add(length)
+		if err := common.ValidateSilence(silence); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		if err := st.CreateSilence(ctx, &silence); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("silence %d created, until %s\n", silence.ID, silence.EndsAt.Format(time.RFC3339))
+		return 0
+
+	case "list":
+		silences, err := st.Silences(ctx, time.Now())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tENDPOINTS\tTAGS\tMATCHERS\tSTARTS\tENDS\tBY\tCOMMENT")
+		for _, s := range silences {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", s.ID,
+				strings.Join(s.Endpoints, ","), strings.Join(s.Tags, ","), strings.Join(s.Matchers, ","),
+				s.StartsAt.Format(time.RFC3339), s.EndsAt.Format(time.RFC3339), s.CreatedBy, s.Comment)
+		}
+		w.Flush()
+		return 0
+
+	case "expire":
+		if len(args) != 2 {
+			return usage()
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return usage()
+		}
+		if err := st.ExpireSilence(ctx, id, time.Now()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("silence %d expired\n", id)
+		return 0
+
+	default:
+		return usage()
+	}
+}
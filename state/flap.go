@@ -0,0 +1,40 @@
+package state
+
+import (
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// flapState counts the transitions of an endpoint within the flapping window.
+type flapState struct {
+	rules       common.FlapRules
+	transitions []time.Time // oldest first
+	flapping    bool
+}
+
+// observe records a transition if changed is set, forgets the ones that left the
+// window and returns whether flapping started or stopped with this result.
+func (f *flapState) observe(at time.Time, changed bool) (started, stopped bool) {
+	if !f.rules.Enabled() {
+		return false, false
+	}
+
+	if changed {
+		f.transitions = append(f.transitions, at)
+	}
+	cutoff := at.Add(-f.rules.Window)
+	for len(f.transitions) > 0 && !f.transitions[0].After(cutoff) {
+		f.transitions = f.transitions[1:]
+	}
+
+	switch n := len(f.transitions); {
+	case !f.flapping && n >= f.rules.Start:
+		f.flapping = true
+		return true, false
+	case f.flapping && n <= f.rules.Stop:
+		f.flapping = false
+		return false, true
+	}
+	return false, false
+}
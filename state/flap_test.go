@@ -0,0 +1,90 @@
+package state
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// flapRules flaps after 4 transitions within 10 minutes and settles once the window holds at most 1.
+var flapRules = common.AlertRules{
+	Failures:   1,
+	Recoveries: 1,
+	Flapping:   common.FlapRules{Window: 10 * time.Minute, Start: 4, Stop: 1},
+}
+
+// observeFlaps feeds statuses a minute apart and describes every transition as
+// "from>to" followed by its flap state, "-" where a result caused none.
+func observeFlaps(t *testing.T, tracker *Tracker, statuses string) []string {
+	t.Helper()
+	codes := map[rune]string{'u': common.StatusUp, 'd': common.StatusDown, 'g': common.StatusDegraded}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var got []string
+	for i, c := range statuses {
+		transition, err := tracker.Observe(context.Background(), result("a", codes[c], start.Add(time.Duration(i)*time.Minute)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if transition == nil {
+			got = append(got, "-")
+			continue
+		}
+		got = append(got, transition.From+">"+transition.To+":"+transition.Flap)
+	}
+	return got
+}
+
+func TestTracker_Flapping(t *testing.T) {
+	tracker := NewTracker(nil)
+	tracker.SetRules("a", flapRules)
+
+	// u g u g: the fourth transition starts flapping, the next ones are
+	// suppressed, then nine stable minutes let the window drain
+	got := observeFlaps(t, tracker, "ugugugu"+"gggggggggg")
+	want := []string{
+		"-", "up>degraded:", "degraded>up:", "up>degraded:", "degraded>up:start",
+		"up>degraded:ongoing", "degraded>up:ongoing", "up>degraded:ongoing",
+		"-", "-", "-", "-", "-", "-", "-", "-",
+		"up>degraded:stop",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("Expected\n%v\ngot\n%v", want, got)
+	}
+	if tracker.Flapping("a") {
+		t.Errorf("Expected the endpoint to be stable again")
+	}
+}
+
+func TestTracker_FlappingState(t *testing.T) {
+	store := &fakeStore{}
+	tracker := NewTracker(store)
+	tracker.SetRules("a", flapRules)
+
+	observeFlaps(t, tracker, "ududud")
+	if !tracker.Flapping("a") {
+		t.Fatalf("Expected the endpoint to be flapping")
+	}
+
+	// Incidents keep being recorded while alerts are suppressed
+	if len(store.opened) != 3 {
+		t.Errorf("Expected 3 incidents opened, got %d", len(store.opened))
+	}
+	if _, ok := tracker.OpenIncident("a"); !ok {
+		t.Errorf("Expected an open incident while down")
+	}
+}
+
+func TestTracker_FlappingDisabled(t *testing.T) {
+	tracker := NewTracker(nil)
+	tracker.SetRules("a", common.AlertRules{Failures: 1, Recoveries: 1})
+
+	for _, got := range observeFlaps(t, tracker, "ugugugugug") {
+		if strings.Contains(got, ":") && !strings.HasSuffix(got, ":") {
+			t.Errorf("Expected no flap state without flapping rules, got %s", got)
+		}
+	}
+}
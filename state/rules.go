@@ -0,0 +1,95 @@
+package state
+
+import (
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// ruleState counts the recent results of an endpoint against its alert rules.
+type ruleState struct {
+	rules common.AlertRules
+
+	failures     int       // consecutive failures
+	failingSince time.Time // first of the consecutive failures
+	ups          int       // consecutive up results
+	upSince      time.Time // first of the consecutive up results
+
+	window []time.Time // last results, oldest first: the time of failures, zero for ups
+}
+
+// evaluate records res and returns the status the tracker should follow and
+// since when. While the rules are not met the current status is kept, so
+// firing is whether the tracked status is already an alert.
+func (r *ruleState) evaluate(res common.Result, current string, firing bool) (string, time.Time) {
+	failing := res.Status != common.StatusUp
+	if failing {
+		if r.failures == 0 {
+			r.failingSince = res.Timestamp
+		}
+		r.failures++
+		r.ups = 0
+	} else {
+		if r.ups == 0 {
+			r.upSince = res.Timestamp
+		}
+		r.ups++
+		r.failures = 0
+	}
+
+	if r.rules.Window > 0 {
+		var at time.Time
+		if failing {
+			at = res.Timestamp
+		}
+		r.window = append(r.window, at)
+		if len(r.window) > r.rules.Window {
+			r.window = r.window[1:]
+		}
+	}
+
+	switch {
+	case !failing && (!firing || r.ups >= max(r.rules.Recoveries, 1)):
+		return common.StatusUp, r.upSince
+	case failing && firing:
+		// Already alerting: follow the failure, e.g. down to unreachable
+		return res.Status, res.Timestamp
+	case failing:
+		if since, ok := r.triggered(); ok {
+			return res.Status, since
+		}
+	}
+	return current, res.Timestamp
+}
+
+// triggered reports whether a rule is met and when the failures it counted began.
+func (r *ruleState) triggered() (time.Time, bool) {
+	var (
+		since time.Time
+		ok    bool
+	)
+	if r.rules.Failures > 0 && r.failures >= r.rules.Failures {
+		since, ok = r.failingSince, true
+	}
+	if r.rules.Window > 0 {
+		count := 0
+		var first time.Time
+		for _, at := range r.window {
+			if at.IsZero() {
+				continue
+			}
+			if count == 0 {
+				first = at
+			}
+			count++
+		}
+		if count >= r.rules.WindowFailures && (!ok || first.Before(since)) {
+			since, ok = first, true
+		}
+	}
+	if !ok && r.rules == (common.AlertRules{}) {
+		// No rules configured: alert on the first failure
+		return r.failingSince, true
+	}
+	return since, ok
+}
@@ -0,0 +1,155 @@
+package state
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// observeAll feeds statuses a minute apart ("u", "d", "g" for up, down and degraded)
+// and returns the transitions, "-" where a result caused none.
+func observeAll(t *testing.T, tracker *Tracker, statuses string) []string {
+	t.Helper()
+	codes := map[rune]string{'u': common.StatusUp, 'd': common.StatusDown, 'g': common.StatusDegraded}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var got []string
+	for i, c := range statuses {
+		transition, err := tracker.Observe(context.Background(), result("a", codes[c], start.Add(time.Duration(i)*time.Minute)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if transition == nil {
+			got = append(got, "-")
+			continue
+		}
+		got = append(got, transition.From+">"+transition.To)
+	}
+	return got
+}
+
+func TestTracker_Rules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    common.AlertRules
+		statuses string
+		want     string
+	}{
+		{
+			name:     "defaults alert on first failure",
+			rules:    common.AlertRules{},
+			statuses: "udu",
+			want:     "- up>down down>up",
+		},
+		{
+			name:     "consecutive failures",
+			rules:    common.AlertRules{Failures: 3},
+			statuses: "uddudddd",
+			want:     "- - - - - - up>down -",
+		},
+		{
+			name:     "degraded counts as a failure",
+			rules:    common.AlertRules{Failures: 2},
+			statuses: "ugd",
+			want:     "- - up>down",
+		},
+		{
+			name:     "failure ratio",
+			rules:    common.AlertRules{Window: 5, WindowFailures: 3},
+			statuses: "ududud",
+			want:     "- - - - - up>down",
+		},
+		{
+			name:     "failure ratio forgets old failures",
+			rules:    common.AlertRules{Window: 3, WindowFailures: 2},
+			statuses: "duudud",
+			want:     "- - - - - up>down",
+		},
+		{
+			name:     "consecutive recoveries",
+			rules:    common.AlertRules{Failures: 1, Recoveries: 3},
+			statuses: "udududuuu",
+			want:     "- up>down - - - - - - down>up",
+		},
+		{
+			name:     "follows failures while alerting",
+			rules:    common.AlertRules{Failures: 2},
+			statuses: "uddgd",
+			want:     "- - up>down down>degraded degraded>down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := tt.rules
+			if err := common.ValidateAlertRules(&rules); err != nil {
+				t.Fatal(err)
+			}
+			tracker := NewTracker(nil)
+			tracker.SetRules("a", rules)
+
+			if got := strings.Join(observeAll(t, tracker, tt.statuses), " "); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTracker_RulesIncidentSpan(t *testing.T) {
+	store := &fakeStore{}
+	tracker := NewTracker(store)
+	tracker.SetRules("a", common.AlertRules{Failures: 3, Recoveries: 2})
+
+	observeAll(t, tracker, "udddduu")
+
+	if len(store.opened) != 1 || len(store.closed) != 1 {
+		t.Fatalf("Expected one incident opened and closed, got %d/%d", len(store.opened), len(store.closed))
+	}
+	// From the first counted failure (minute 1) to the first up of the recovery (minute 5)
+	incident := store.closed[0]
+	if d := incident.Duration(time.Now()); d != 4*time.Minute {
+		t.Errorf("Expected the incident to span the counted failures (4m), got %s", d)
+	}
+}
+
+func TestTracker_RestoreUnconfirmedFailure(t *testing.T) {
+	tracker := NewTracker(nil)
+	tracker.SetRules("a", common.AlertRules{Failures: 3, Recoveries: 1})
+	tracker.Restore(map[string]common.Result{"a": result("a", common.StatusDown, time.Now())}, nil)
+
+	// The failure before the restart never alerted, so recovering must not either
+	if got := strings.Join(observeAll(t, tracker, "u"), " "); got != "-" {
+		t.Errorf("Expected no recovery transition, got %q", got)
+	}
+}
+
+func TestValidateAlertRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   common.AlertRules
+		want    common.AlertRules
+		wantErr bool
+	}{
+		{"defaults", common.AlertRules{}, common.AlertRules{Failures: 1, Recoveries: 1}, false},
+		{"window only", common.AlertRules{Window: 10, WindowFailures: 4}, common.AlertRules{Window: 10, WindowFailures: 4, Recoveries: 1}, false},
+		{"negative", common.AlertRules{Failures: -1}, common.AlertRules{}, true},
+		{"window without threshold", common.AlertRules{Window: 5}, common.AlertRules{}, true},
+		{"threshold above window", common.AlertRules{Window: 5, WindowFailures: 6}, common.AlertRules{}, true},
+		{"threshold without window", common.AlertRules{WindowFailures: 2}, common.AlertRules{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := tt.rules
+			err := common.ValidateAlertRules(&rules)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && rules != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, rules)
+			}
+		})
+	}
+}
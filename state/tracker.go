@@ -0,0 +1,233 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// IncidentStore persists the incidents opened and closed by a Tracker.
+type IncidentStore interface {
+	// OpenIncident records a new incident and sets its ID.
+	OpenIncident(ctx context.Context, incident *common.Incident) error
+	// CloseIncident records the end of an incident.
+	CloseIncident(ctx context.Context, incident common.Incident) error
+}
+
+// Tracker follows the status of every endpoint across results, reporting
+// transitions and keeping one incident open while an endpoint is failing.
+// The tracked status only changes once the endpoint's alert rules are met, so
+// it sits between raw results and the alerts they cause.
+type Tracker struct {
+	store IncidentStore
+
+	mu     sync.Mutex
+	states map[string]*endpointState
+}
+
+type endpointState struct {
+	status   string
+	incident *common.Incident // open incident, nil while healthy
+	rules    ruleState
+	flap     flapState
+	notified string // status of the last transition that was not suppressed
+}
+
+// NewTracker returns a Tracker recording incidents in store (nil keeps them in memory only).
+func NewTracker(store IncidentStore) *Tracker {
+	return &Tracker{
+		store:  store,
+		states: make(map[string]*endpointState),
+	}
+}
+
+// SetRules sets the alert rules of an endpoint. Endpoints without rules alert
+// on their first failure and recover on their first up result.
+func (t *Tracker) SetRules(endpointID string, rules common.AlertRules) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st := t.state(endpointID)
+	st.rules = ruleState{rules: rules}
+	st.flap = flapState{rules: rules.Flapping}
+}
+
+// Restore seeds the tracker after a restart with the last known result of every
+// endpoint and the incidents still open, so no transition or incident is duplicated.
+func (t *Tracker) Restore(latest map[string]common.Result, open []common.Incident) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, res := range latest {
+		t.state(id).status = res.Status
+	}
+	for _, incident := range open {
+		t.state(incident.EndpointID).incident = &incident
+	}
+	// A failure without an incident never met the alert rules before the restart
+	for _, st := range t.states {
+		if common.IsFailing(st.status) && st.incident == nil {
+			st.status = ""
+		}
+		st.notified = st.status
+	}
+}
+
+// Observe feeds a result into the tracker. It returns the transition the result
+// caused, or nil if the status did not change. The first result of an endpoint is
+// a transition from "" unless it is up. Incidents start at the first failure the
+// alert rules counted and end at the first of the up results that recovered them.
+// While the endpoint is flapping, transitions are marked as suppressed (see
+// common.Transition.Flap) and their result is flagged as flapping. A failed store write is
+// returned alongside the transition; the in-memory state is updated regardless.
+func (t *Tracker) Observe(ctx context.Context, res common.Result) (*common.Transition, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st := t.state(res.EndpointID)
+	from := st.status
+	firing := from != "" && from != common.StatusUp
+	status, since := st.rules.evaluate(res, from, firing)
+	st.status = status
+
+	var (
+		incident *common.Incident
+		err      error
+	)
+	switch {
+	case common.IsFailing(status) && st.incident == nil:
+		st.incident = &common.Incident{
+			EndpointID: res.EndpointID,
+			Status:     status,
+			Reason:     common.FailureReason(res),
+			StartedAt:  since,
+		}
+		if t.store != nil {
+			if e := t.store.OpenIncident(ctx, st.incident); e != nil {
+				err = fmt.Errorf("opening incident for %s: %w", res.EndpointID, e)
+			}
+		}
+		incident = st.incident
+
+	case !common.IsFailing(status) && st.incident != nil:
+		st.incident.EndedAt = since
+		if t.store != nil {
+			if e := t.store.CloseIncident(ctx, *st.incident); e != nil {
+				err = fmt.Errorf("closing incident %d for %s: %w", st.incident.ID, res.EndpointID, e)
+			}
+		}
+		incident = st.incident
+		st.incident = nil
+	}
+
+	changed := from != status && !(from == "" && status == common.StatusUp)
+	started, stopped := st.flap.observe(res.Timestamp, changed && from != "")
+	res.Flapping = st.flap.flapping
+
+	var flap string
+	switch {
+	case started:
+		flap = common.FlapStart
+	case stopped:
+		// Tell where the endpoint settled, even if this result changed nothing
+		flap, from = common.FlapStop, st.notified
+		if incident == nil && st.incident != nil {
+			incident = st.incident
+		}
+	case !changed:
+		return nil, err
+	case st.flap.flapping:
+		flap = common.FlapOngoing
+	}
+	if flap != common.FlapOngoing {
+		st.notified = status
+	}
+
+	var snapshot *common.Incident
+	if incident != nil {
+		copied := *incident
+		snapshot = &copied
+	}
+	return &common.Transition{
+		EndpointID: res.EndpointID,
+		From:       from,
+		To:         status,
+		At:         res.Timestamp,
+		Result:     res,
+		Incident:   snapshot,
+		Flap:       flap,
+	}, err
+}
+
+// Flapping reports whether an endpoint is currently flapping.
+func (t *Tracker) Flapping(endpointID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, ok := t.states[endpointID]
+	return ok && st.flap.flapping
+}
+
+// Status returns the last known status of an endpoint.
+func (t *Tracker) Status(endpointID string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, ok := t.states[endpointID]
+	if !ok {
+		return "", false
+	}
+	return st.status, true
+}
+
+// OpenIncident returns the ongoing incident of an endpoint, if any.
+func (t *Tracker) OpenIncident(endpointID string) (common.Incident, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, ok := t.states[endpointID]
+	if !ok || st.incident == nil {
+		return common.Incident{}, false
+	}
+	return *st.incident, true
+}
+
+// Forget drops the state of an endpoint that is no longer monitored, closing its
+// open incident at the given time. It returns the closed incident, if any, and
+// the store error closing it.
+func (t *Tracker) Forget(ctx context.Context, endpointID string, at time.Time) (*common.Incident, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, ok := t.states[endpointID]
+	if !ok {
+		return nil, nil
+	}
+	delete(t.states, endpointID)
+	if st.incident == nil {
+		return nil, nil
+	}
+
+	incident := *st.incident
+	incident.EndedAt = at
+	var err error
+	if t.store != nil {
+		if e := t.store.CloseIncident(ctx, incident); e != nil {
+			err = fmt.Errorf("closing incident %d for %s: %w", incident.ID, endpointID, e)
+		}
+	}
+	return &incident, err
+}
+
+// state returns the state of an endpoint, creating it on first use. t.mu must be held.
+func (t *Tracker) state(endpointID string) *endpointState {
+	st, ok := t.states[endpointID]
+	if !ok {
+		st = &endpointState{}
+		t.states[endpointID] = st
+	}
+	return st
+}
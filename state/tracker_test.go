@@ -0,0 +1,201 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+type fakeStore struct {
+	nextID  int64
+	opened  []common.Incident
+	closed  []common.Incident
+	openErr error
+}
+
+func (f *fakeStore) OpenIncident(_ context.Context, incident *common.Incident) error {
+	if f.openErr != nil {
+		return f.openErr
+	}
+	f.nextID++
+	incident.ID = f.nextID
+	f.opened = append(f.opened, *incident)
+	return nil
+}
+
+func (f *fakeStore) CloseIncident(_ context.Context, incident common.Incident) error {
+	f.closed = append(f.closed, incident)
+	return nil
+}
+
+func result(id, status string, at time.Time) common.Result {
+	return common.Result{EndpointID: id, URL: "http://" + id, Status: status, Timestamp: at}
+}
+
+func TestTracker_Transitions(t *testing.T) {
+	store := &fakeStore{}
+	tracker := NewTracker(store)
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		status   string
+		from     string
+		changed  bool
+		incident bool
+	}{
+		{common.StatusUp, "", false, false},
+		{common.StatusUp, "", false, false},
+		{common.StatusDegraded, common.StatusUp, true, false},
+		{common.StatusDown, common.StatusDegraded, true, true},
+		{common.StatusUnreachable, common.StatusDown, true, false},
+		{common.StatusDown, common.StatusUnreachable, true, false},
+		{common.StatusUp, common.StatusDown, true, true},
+	}
+
+	for i, step := range steps {
+		res := result("a", step.status, start.Add(time.Duration(i)*time.Minute))
+		transition, err := tracker.Observe(ctx, res)
+		if err != nil {
+			t.Fatalf("step %d: unexpected error: %v", i, err)
+		}
+		if (transition != nil) != step.changed {
+			t.Fatalf("step %d: Expected transition %v, got %+v", i, step.changed, transition)
+		}
+		if transition == nil {
+			continue
+		}
+		if transition.From != step.from || transition.To != step.status {
+			t.Errorf("step %d: Expected %s->%s, got %s->%s", i, step.from, step.status, transition.From, transition.To)
+		}
+		if (transition.Incident != nil) != step.incident {
+			t.Errorf("step %d: Expected incident %v, got %+v", i, step.incident, transition.Incident)
+		}
+	}
+
+	if len(store.opened) != 1 || len(store.closed) != 1 {
+		t.Fatalf("Expected one incident opened and closed, got %d/%d", len(store.opened), len(store.closed))
+	}
+	incident := store.closed[0]
+	if incident.ID != 1 || incident.Status != common.StatusDown || incident.Open() {
+		t.Errorf("Unexpected closed incident: %+v", incident)
+	}
+	if d := incident.Duration(time.Now()); d != 3*time.Minute {
+		t.Errorf("Expected incident to last 3m, got %s", d)
+	}
+	if _, ok := tracker.OpenIncident("a"); ok {
+		t.Errorf("Expected no open incident after recovery")
+	}
+}
+
+func TestTracker_FirstResultFailing(t *testing.T) {
+	tracker := NewTracker(nil)
+	res := result("a", common.StatusDown, time.Now())
+	res.Error = "connection refused"
+	res.Messages = []string{"ignored"}
+
+	transition, err := tracker.Observe(context.Background(), res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transition == nil || transition.From != "" || transition.Incident == nil {
+		t.Fatalf("Expected an initial transition opening an incident, got %+v", transition)
+	}
+	if transition.Incident.Reason != "connection refused" {
+		t.Errorf("Expected error as first failure reason, got %q", transition.Incident.Reason)
+	}
+}
+
+func TestTracker_SeparatesEndpoints(t *testing.T) {
+	tracker := NewTracker(nil)
+	ctx := context.Background()
+	now := time.Now()
+
+	tracker.Observe(ctx, result("a", common.StatusUp, now))
+	tracker.Observe(ctx, result("b", common.StatusUp, now))
+
+	transition, _ := tracker.Observe(ctx, result("b", common.StatusDown, now))
+	if transition == nil || transition.EndpointID != "b" {
+		t.Fatalf("Expected a transition for b, got %+v", transition)
+	}
+	if status, _ := tracker.Status("a"); status != common.StatusUp {
+		t.Errorf("Expected a to stay up, got %s", status)
+	}
+}
+
+func TestTracker_Restore(t *testing.T) {
+	store := &fakeStore{}
+	tracker := NewTracker(store)
+	ctx := context.Background()
+	now := time.Now()
+
+	tracker.Restore(
+		map[string]common.Result{"a": result("a", common.StatusDown, now.Add(-time.Minute))},
+		[]common.Incident{{ID: 7, EndpointID: "a", Status: common.StatusDown, StartedAt: now.Add(-time.Hour)}},
+	)
+
+	// Still down after the restart: no transition, no new incident
+	transition, err := tracker.Observe(ctx, result("a", common.StatusDown, now))
+	if err != nil || transition != nil {
+		t.Fatalf("Expected no transition, got %+v (%v)", transition, err)
+	}
+	if len(store.opened) != 0 {
+		t.Errorf("Expected the restored incident to be reused, got %d opened", len(store.opened))
+	}
+
+	transition, _ = tracker.Observe(ctx, result("a", common.StatusUp, now.Add(time.Minute)))
+	if transition == nil || transition.Incident == nil || transition.Incident.ID != 7 {
+		t.Fatalf("Expected recovery to close incident 7, got %+v", transition)
+	}
+	if d := transition.Incident.Duration(now); d != time.Hour+time.Minute {
+		t.Errorf("Expected duration to span the restart, got %s", d)
+	}
+}
+
+func TestTracker_StoreError(t *testing.T) {
+	store := &fakeStore{openErr: errors.New("disk full")}
+	tracker := NewTracker(store)
+
+	transition, err := tracker.Observe(context.Background(), result("a", common.StatusDown, time.Now()))
+	if err == nil {
+		t.Errorf("Expected the store error to be returned")
+	}
+	if transition == nil {
+		t.Errorf("Expected the transition to be reported despite the store error")
+	}
+	if _, ok := tracker.OpenIncident("a"); !ok {
+		t.Errorf("Expected the incident to be tracked in memory")
+	}
+}
+
+func TestTracker_Forget(t *testing.T) {
+	store := &fakeStore{}
+	tracker := NewTracker(store)
+	ctx := context.Background()
+	now := time.Now()
+
+	tracker.Observe(ctx, result("a", common.StatusDown, now))
+	incident, err := tracker.Forget(ctx, "a", now.Add(time.Minute))
+	if err != nil || incident == nil || incident.Open() {
+		t.Fatalf("Expected the open incident to be closed, got %+v (%v)", incident, err)
+	}
+	if len(store.closed) != 1 || !store.closed[0].EndedAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected the incident to be closed in the store, got %+v", store.closed)
+	}
+	if _, ok := tracker.Status("a"); ok {
+		t.Errorf("Expected the state of a to be dropped")
+	}
+
+	// Monitored again later, it starts over
+	transition, _ := tracker.Observe(ctx, result("a", common.StatusDown, now.Add(2*time.Minute)))
+	if transition == nil || transition.From != "" || transition.Incident == nil || transition.Incident.ID == incident.ID {
+		t.Errorf("Expected a fresh transition with a new incident, got %+v", transition)
+	}
+
+	if incident, err := tracker.Forget(ctx, "unknown", now); incident != nil || err != nil {
+		t.Errorf("Expected nothing to forget, got %+v (%v)", incident, err)
+	}
+}
@@ -0,0 +1,219 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// Resolution is the bucket size of an aggregate.
+type Resolution string
+
+const (
+	Minute Resolution = "minute"
+	Hour   Resolution = "hour"
+	Day    Resolution = "day"
+)
+
+// Resolutions lists every rollup level, finest first.
+var Resolutions = []Resolution{Minute, Hour, Day}
+
+// Duration returns the bucket size of r.
+func (r Resolution) Duration() time.Duration {
+	switch r {
+	case Minute:
+		return time.Minute
+	case Hour:
+		return time.Hour
+	case Day:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Truncate returns the start of the bucket containing t (buckets are aligned to UTC).
+func (r Resolution) Truncate(t time.Time) time.Time {
+	return t.UTC().Truncate(r.Duration())
+}
+
+// Retention defaults: raw rows are kept for a week, per-minute aggregates for the
+// 30 days the roadmap asks for, hourly ones for a year and daily ones forever.
+const (
+	DefaultRawRetention    = 7 * 24 * time.Hour
+	DefaultMinuteRetention = 30 * 24 * time.Hour
+	DefaultHourRetention   = 365 * 24 * time.Hour
+	DefaultDayRetention    = 0
+	DefaultMaintenanceRun  = 5 * time.Minute
+	DefaultRollupDelay     = 2 * time.Minute
+)
+
+// minRawRetention keeps raw rows around long enough to roll up a whole day.
+const minRawRetention = 48 * time.Hour
+
+// RetentionPolicy controls how long raw results and aggregates are kept.
+// Unset fields fall back to the defaults above; a Day of 0 keeps daily aggregates forever.
+type RetentionPolicy struct {
+	Raw    time.Duration `mapstructure:"raw" json:"raw" yaml:"raw"`
+	Minute time.Duration `mapstructure:"minute" json:"minute" yaml:"minute"`
+	Hour   time.Duration `mapstructure:"hour" json:"hour" yaml:"hour"`
+	Day    time.Duration `mapstructure:"day" json:"day" yaml:"day"`
+	// Interval is how often rollups and cleanup run
+	Interval time.Duration `mapstructure:"interval" json:"interval" yaml:"interval"`
+	// Delay holds back rollups of recently closed buckets so late results
+	// (slow checks, retries) still land in the right bucket
+	Delay time.Duration `mapstructure:"delay" json:"delay" yaml:"delay"`
+}
+
+// For returns the retention of aggregates at resolution r.
+func (p RetentionPolicy) For(r Resolution) time.Duration {
+	switch r {
+	case Minute:
+		return p.Minute
+	case Hour:
+		return p.Hour
+	case Day:
+		return p.Day
+	default:
+		return 0
+	}
+}
+
+// ValidateRetention applies defaults to p and validates it.
+// Raw rows must expire before any aggregate so reports never lose data.
+func ValidateRetention(p *RetentionPolicy) error {
+	if p.Raw < 0 || p.Minute < 0 || p.Hour < 0 || p.Day < 0 || p.Interval < 0 || p.Delay < 0 {
+		return fmt.Errorf("retention durations must be non-negative")
+	}
+	if p.Raw == 0 {
+		p.Raw = DefaultRawRetention
+	}
+	if p.Minute == 0 {
+		p.Minute = DefaultMinuteRetention
+	}
+	if p.Hour == 0 {
+		p.Hour = DefaultHourRetention
+	}
+	if p.Day == 0 {
+		p.Day = DefaultDayRetention
+	}
+	if p.Interval == 0 {
+		p.Interval = DefaultMaintenanceRun
+	}
+	if p.Delay == 0 {
+		p.Delay = DefaultRollupDelay
+	}
+
+	if p.Raw < minRawRetention {
+		return fmt.Errorf("retention.raw must be at least %s so daily rollups can be computed", minRawRetention)
+	}
+	for _, r := range Resolutions {
+		if keep := p.For(r); keep != 0 && keep <= p.Raw {
+			return fmt.Errorf("retention.%s (%s) must be longer than retention.raw (%s)", r, keep, p.Raw)
+		}
+	}
+	return nil
+}
+
+// Aggregate summarises the results of one endpoint within a bucket.
+// Down counts both down and unreachable results.
+type Aggregate struct {
+	Endpoint   string     `json:"endpoint"`
+	Resolution Resolution `json:"resolution"`
+	Bucket     time.Time  `json:"bucket"`
+	Count      int        `json:"count"`
+	Up         int        `json:"up"`
+	Degraded   int        `json:"degraded"`
+	Down       int        `json:"down"`
+	MinElapsed int        `json:"min_elapsed_ms"`
+	AvgElapsed int        `json:"avg_elapsed_ms"`
+	MaxElapsed int        `json:"max_elapsed_ms"`
+	P95Elapsed int        `json:"p95_elapsed_ms"`
+}
+
+// AggregateQuery selects aggregates of a single endpoint within a time range.
+type AggregateQuery struct {
+	Endpoint   string
+	Resolution Resolution
+	From       time.Time
+	To         time.Time
+}
+
+// UptimeQuery selects the results of a single endpoint within [From, To).
+type UptimeQuery struct {
+	Endpoint string
+	From     time.Time
+	To       time.Time
+}
+
+// Uptime counts the results of an endpoint within a time range. Results checked
+// during maintenance are left out. Down counts both down and unreachable results.
+type Uptime struct {
+	Endpoint string    `json:"endpoint"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Count    int       `json:"count"`
+	Up       int       `json:"up"`
+	Degraded int       `json:"degraded"`
+	Down     int       `json:"down"`
+}
+
+// Percent returns the share of results that were not down, or false without results.
+// Degraded results count as up: the endpoint answered.
+func (u Uptime) Percent() (float64, bool) {
+	if u.Count == 0 {
+		return 0, false
+	}
+	return 100 * float64(u.Up+u.Degraded) / float64(u.Count), true
+}
+
+// MaintenanceStats reports what a maintenance run did.
+type MaintenanceStats struct {
+	Aggregated        int   // buckets written
+	DeletedRaw        int64 // raw results removed
+	DeletedAggregates int64
+}
+
+// aggregate builds the aggregate of the results sharing a bucket.
+func aggregate(endpoint string, r Resolution, bucket time.Time, statuses []string, elapsed []int) Aggregate {
+	agg := Aggregate{
+		Endpoint:   endpoint,
+		Resolution: r,
+		Bucket:     bucket,
+		Count:      len(statuses),
+	}
+	for _, status := range statuses {
+		switch status {
+		case common.StatusUp:
+			agg.Up++
+		case common.StatusDegraded:
+			agg.Degraded++
+		default:
+			agg.Down++
+		}
+	}
+	if len(elapsed) == 0 {
+		return agg
+	}
+
+	sorted := slices.Clone(elapsed)
+	slices.Sort(sorted)
+	sum := 0
+	for _, e := range sorted {
+		sum += e
+	}
+	agg.MinElapsed = sorted[0]
+	agg.MaxElapsed = sorted[len(sorted)-1]
+	agg.AvgElapsed = int(math.Round(float64(sum) / float64(len(sorted))))
+	agg.P95Elapsed = percentile(sorted, 95)
+	return agg
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []int, p float64) int {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
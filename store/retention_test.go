@@ -0,0 +1,300 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+func TestAggregate(t *testing.T) {
+	statuses := []string{
+		common.StatusUp, common.StatusUp, common.StatusDegraded, common.StatusDown, common.StatusUnreachable,
+	}
+	elapsed := []int{50, 10, 30, 20, 40}
+
+	agg := aggregate("a", Minute, time.Time{}, statuses, elapsed)
+	if agg.Count != 5 || agg.Up != 2 || agg.Degraded != 1 || agg.Down != 2 {
+		t.Errorf("Expected counts 5/2/1/2, got %d/%d/%d/%d", agg.Count, agg.Up, agg.Degraded, agg.Down)
+	}
+	if agg.MinElapsed != 10 || agg.MaxElapsed != 50 || agg.AvgElapsed != 30 || agg.P95Elapsed != 50 {
+		t.Errorf("Expected min/avg/max/p95 10/30/50/50, got %d/%d/%d/%d",
+			agg.MinElapsed, agg.AvgElapsed, agg.MaxElapsed, agg.P95Elapsed)
+	}
+	if elapsed[0] != 50 {
+		t.Errorf("Expected input slice to be left untouched")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]int, 100)
+	for i := range sorted {
+		sorted[i] = i + 1
+	}
+
+	tests := []struct {
+		values   []int
+		p        float64
+		expected int
+	}{
+		{sorted, 95, 95},
+		{sorted, 50, 50},
+		{sorted, 100, 100},
+		{[]int{7}, 95, 7},
+		{[]int{1, 2}, 95, 2},
+	}
+	for _, tt := range tests {
+		if got := percentile(tt.values, tt.p); got != tt.expected {
+			t.Errorf("Expected p%v of %d values to be %d, got %d", tt.p, len(tt.values), tt.expected, got)
+		}
+	}
+}
+
+func TestValidateRetention(t *testing.T) {
+	var p RetentionPolicy
+	if err := ValidateRetention(&p); err != nil {
+		t.Fatalf("Expected defaults to be valid, got %v", err)
+	}
+	if p.Raw != DefaultRawRetention || p.Minute != DefaultMinuteRetention || p.Interval != DefaultMaintenanceRun {
+		t.Errorf("Expected defaults to be applied, got %+v", p)
+	}
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+	}{
+		{"negative", RetentionPolicy{Raw: -time.Hour}},
+		{"raw too short", RetentionPolicy{Raw: time.Hour}},
+		{"aggregate shorter than raw", RetentionPolicy{Raw: 10 * 24 * time.Hour, Minute: 5 * 24 * time.Hour}},
+		{"aggregate equal to raw", RetentionPolicy{Raw: 72 * time.Hour, Hour: 72 * time.Hour}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateRetention(&tt.policy); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+func TestSQLiteStore_Maintain(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	// Three checks in the first minute, one in the second, all on the same day
+	results := []common.Result{
+		{EndpointID: "a", Status: common.StatusUp, Timestamp: day.Add(5 * time.Second), Elapsed: 10},
+		{EndpointID: "a", Status: common.StatusDown, Timestamp: day.Add(20 * time.Second), Elapsed: 30},
+		{EndpointID: "a", Status: common.StatusUp, Timestamp: day.Add(40 * time.Second), Elapsed: 20},
+		{EndpointID: "a", Status: common.StatusDegraded, Timestamp: day.Add(90 * time.Second), Elapsed: 100},
+		{EndpointID: "b", Status: common.StatusUp, Timestamp: day.Add(10 * time.Second), Elapsed: 5},
+	}
+	for _, r := range results {
+		if err := s.SaveResult(ctx, r); err != nil {
+			t.Fatalf("save result: %v", err)
+		}
+	}
+
+	policy := RetentionPolicy{}
+	if err := ValidateRetention(&policy); err != nil {
+		t.Fatal(err)
+	}
+
+	// Shortly after the day closed: minute, hour and day buckets are all rolled up
+	now := day.Add(24*time.Hour + policy.Delay + time.Minute)
+	stats, err := s.Maintain(ctx, policy, now)
+	if err != nil {
+		t.Fatalf("maintain: %v", err)
+	}
+	// a: 2 minutes, 1 hour, 1 day; b: 1 minute, 1 hour, 1 day
+	if stats.Aggregated != 7 {
+		t.Errorf("Expected 7 aggregates, got %d", stats.Aggregated)
+	}
+	if stats.DeletedRaw != 0 {
+		t.Errorf("Expected raw rows within retention to be kept, got %d deleted", stats.DeletedRaw)
+	}
+
+	minutes, err := s.Aggregates(ctx, AggregateQuery{Endpoint: "a", Resolution: Minute})
+	if err != nil {
+		t.Fatalf("aggregates: %v", err)
+	}
+	if len(minutes) != 2 {
+		t.Fatalf("Expected 2 minute aggregates, got %d", len(minutes))
+	}
+	first := minutes[0]
+	if !first.Bucket.Equal(day) || first.Count != 3 || first.Up != 2 || first.Down != 1 || first.AvgElapsed != 20 {
+		t.Errorf("Unexpected first minute aggregate: %+v", first)
+	}
+
+	days, err := s.Aggregates(ctx, AggregateQuery{Endpoint: "a", Resolution: Day})
+	if err != nil {
+		t.Fatalf("aggregates: %v", err)
+	}
+	if len(days) != 1 || days[0].Count != 4 || days[0].Degraded != 1 || days[0].MaxElapsed != 100 {
+		t.Errorf("Unexpected day aggregates: %+v", days)
+	}
+
+	// Running again only picks up new buckets
+	stats, err = s.Maintain(ctx, policy, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("maintain: %v", err)
+	}
+	if stats.Aggregated != 0 {
+		t.Errorf("Expected no new aggregates, got %d", stats.Aggregated)
+	}
+
+	// Once raw retention passes, raw rows go but aggregates stay
+	later := day.Add(policy.Raw + 48*time.Hour)
+	stats, err = s.Maintain(ctx, policy, later)
+	if err != nil {
+		t.Fatalf("maintain: %v", err)
+	}
+	if stats.DeletedRaw != int64(len(results)) {
+		t.Errorf("Expected %d raw rows deleted, got %d", len(results), stats.DeletedRaw)
+	}
+	if stats.DeletedAggregates != 0 {
+		t.Errorf("Expected aggregates to outlive raw rows, got %d deleted", stats.DeletedAggregates)
+	}
+	history, _ := s.History(ctx, HistoryQuery{Endpoint: "a"})
+	if len(history) != 0 {
+		t.Errorf("Expected raw history to be empty, got %d", len(history))
+	}
+	hours, _ := s.Aggregates(ctx, AggregateQuery{Endpoint: "a", Resolution: Hour})
+	if len(hours) != 1 {
+		t.Errorf("Expected hourly aggregate to be kept, got %d", len(hours))
+	}
+
+	// Past the minute retention only the per-minute aggregates expire
+	stats, err = s.Maintain(ctx, policy, day.Add(policy.Minute+48*time.Hour))
+	if err != nil {
+		t.Fatalf("maintain: %v", err)
+	}
+	if stats.DeletedAggregates != 3 {
+		t.Errorf("Expected 3 minute aggregates deleted, got %d", stats.DeletedAggregates)
+	}
+}
+
+func TestSQLiteStore_MaintainHoldsBackOpenBuckets(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	now := time.Date(2026, 3, 10, 12, 30, 30, 0, time.UTC)
+	if err := s.SaveResult(ctx, common.Result{EndpointID: "a", Status: common.StatusUp, Timestamp: now.Add(-time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := RetentionPolicy{}
+	ValidateRetention(&policy)
+
+	stats, err := s.Maintain(ctx, policy, now)
+	if err != nil {
+		t.Fatalf("maintain: %v", err)
+	}
+	if stats.Aggregated != 0 {
+		t.Errorf("Expected buckets within the rollup delay to wait, got %d aggregates", stats.Aggregated)
+	}
+
+	stats, err = s.Maintain(ctx, policy, now.Add(policy.Delay+time.Minute))
+	if err != nil {
+		t.Fatalf("maintain: %v", err)
+	}
+	if stats.Aggregated != 1 {
+		t.Errorf("Expected the minute bucket to be rolled up, got %d aggregates", stats.Aggregated)
+	}
+}
+
+func TestSQLiteStore_MaintainSkipsMaintenance(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	results := []common.Result{
+		{EndpointID: "a", Status: common.StatusUp, Timestamp: day.Add(time.Hour), Elapsed: 10},
+		{EndpointID: "a", Status: common.StatusDown, Timestamp: day.Add(2 * time.Hour), Elapsed: 900, Maintenance: true},
+		{EndpointID: "a", Status: common.StatusUp, Timestamp: day.Add(3 * time.Hour), Elapsed: 20},
+	}
+	for _, r := range results {
+		if err := s.SaveResult(ctx, r); err != nil {
+			t.Fatalf("save result: %v", err)
+		}
+	}
+
+	policy := RetentionPolicy{}
+	if err := ValidateRetention(&policy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Maintain(ctx, policy, day.Add(24*time.Hour+policy.Delay+time.Minute)); err != nil {
+		t.Fatalf("maintain: %v", err)
+	}
+
+	days, err := s.Aggregates(ctx, AggregateQuery{Endpoint: "a", Resolution: Day})
+	if err != nil {
+		t.Fatalf("aggregates: %v", err)
+	}
+	if len(days) != 1 || days[0].Count != 2 || days[0].Down != 0 || days[0].MaxElapsed != 20 {
+		t.Errorf("Expected the maintenance result to be left out, got %+v", days)
+	}
+
+	// It is still in the raw history, flagged
+	history, err := s.History(ctx, HistoryQuery{Endpoint: "a"})
+	if err != nil || len(history) != 3 || !history[1].Maintenance {
+		t.Errorf("Expected the flagged result in history, got %+v (%v)", history, err)
+	}
+}
+
+func TestSQLiteStore_Uptime(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	results := []common.Result{
+		{EndpointID: "a", Status: common.StatusUp, Timestamp: day.Add(10 * time.Minute)},
+		{EndpointID: "a", Status: common.StatusDown, Timestamp: day.Add(70 * time.Minute)},
+		{EndpointID: "a", Status: common.StatusDegraded, Timestamp: day.Add(130 * time.Minute)},
+		{EndpointID: "a", Status: common.StatusUnreachable, Timestamp: day.Add(140 * time.Minute), Maintenance: true},
+		{EndpointID: "b", Status: common.StatusDown, Timestamp: day.Add(10 * time.Minute)},
+	}
+	for _, r := range results {
+		if err := s.SaveResult(ctx, r); err != nil {
+			t.Fatalf("save result: %v", err)
+		}
+	}
+
+	check := func(from, to time.Time, count, up, degraded, down int) {
+		t.Helper()
+		u, err := s.Uptime(ctx, UptimeQuery{Endpoint: "a", From: from, To: to})
+		if err != nil {
+			t.Fatalf("uptime: %v", err)
+		}
+		if u.Count != count || u.Up != up || u.Degraded != degraded || u.Down != down {
+			t.Errorf("Expected counts %d/%d/%d/%d, got %d/%d/%d/%d", count, up, degraded, down, u.Count, u.Up, u.Degraded, u.Down)
+		}
+	}
+
+	// Nothing rolled up yet: raw results only, maintenance left out
+	check(day, day.Add(24*time.Hour), 3, 1, 1, 1)
+	check(day.Add(5*time.Minute), day.Add(2*time.Hour), 2, 1, 0, 1)
+
+	// Once rolled up and the raw rows gone, whole hours come from the aggregates
+	policy := RetentionPolicy{}
+	if err := ValidateRetention(&policy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Maintain(ctx, policy, day.Add(policy.Raw+48*time.Hour)); err != nil {
+		t.Fatalf("maintain: %v", err)
+	}
+	check(day, day.Add(24*time.Hour), 3, 1, 1, 1)
+	check(day.Add(time.Hour), day.Add(3*time.Hour), 2, 0, 1, 1)
+	// Partial hours needed raw results, which have expired
+	check(day.Add(5*time.Minute), day.Add(2*time.Hour), 1, 0, 0, 1)
+
+	u, _ := s.Uptime(ctx, UptimeQuery{Endpoint: "a", From: day, To: day.Add(24 * time.Hour)})
+	if percent, ok := u.Percent(); !ok || percent < 66 || percent > 67 {
+		t.Errorf("Expected about 66.7%% uptime, got %v (%v)", percent, ok)
+	}
+	if _, ok := (Uptime{}).Percent(); ok {
+		t.Errorf("Expected no uptime without results")
+	}
+}
@@ -0,0 +1,341 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+	_ "modernc.org/sqlite"
+)
+
+// migrations are applied in order; PRAGMA user_version records how many ran.
+// Never edit an existing entry, append a new one instead.
+var migrations = []string{
+	// 1: initial schema
+	`CREATE TABLE endpoints (
+		id          TEXT PRIMARY KEY,
+		name        TEXT NOT NULL,
+		url         TEXT NOT NULL,
+		type        TEXT NOT NULL,
+		method      TEXT NOT NULL DEFAULT '',
+		interval_ms INTEGER NOT NULL,
+		timeout_ms  INTEGER NOT NULL,
+		updated_at  INTEGER NOT NULL
+	);
+	CREATE TABLE checks (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		endpoint    TEXT NOT NULL, -- endpoint ID
+		url         TEXT NOT NULL DEFAULT '',
+		status      TEXT NOT NULL,
+		status_code INTEGER NOT NULL DEFAULT 0,
+		timestamp   INTEGER NOT NULL, -- unix milliseconds
+		elapsed_ms  INTEGER NOT NULL DEFAULT 0,
+		error       TEXT NOT NULL DEFAULT '',
+		messages    TEXT NOT NULL DEFAULT '[]', -- JSON array
+		details     TEXT NOT NULL DEFAULT '{}', -- JSON: answers, tls, attempts
+		maintenance INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX checks_endpoint_timestamp ON checks (endpoint, timestamp);
+	CREATE INDEX checks_timestamp ON checks (timestamp);
+	CREATE TABLE alerts (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		endpoint   TEXT NOT NULL,
+		incident   INTEGER, -- NULL when the alert is not about an incident
+		notifier   TEXT NOT NULL,
+		old_status TEXT NOT NULL DEFAULT '',
+		new_status TEXT NOT NULL,
+		title      TEXT NOT NULL DEFAULT '',
+		error      TEXT NOT NULL DEFAULT '', -- why it was not delivered, empty once delivered
+		created_at INTEGER NOT NULL
+	);
+	CREATE INDEX alerts_endpoint_created_at ON alerts (endpoint, created_at);
+
+	-- Rollups and retention
+	CREATE TABLE aggregates (
+		endpoint   TEXT NOT NULL,
+		resolution TEXT NOT NULL, -- minute, hour, day
+		bucket     INTEGER NOT NULL, -- bucket start, unix milliseconds
+		count      INTEGER NOT NULL,
+		up         INTEGER NOT NULL,
+		degraded   INTEGER NOT NULL,
+		down       INTEGER NOT NULL,
+		min_ms     INTEGER NOT NULL,
+		avg_ms     INTEGER NOT NULL,
+		max_ms     INTEGER NOT NULL,
+		p95_ms     INTEGER NOT NULL,
+		PRIMARY KEY (endpoint, resolution, bucket)
+	);
+	CREATE INDEX aggregates_resolution_bucket ON aggregates (resolution, bucket);
+	CREATE TABLE rollups (
+		resolution   TEXT PRIMARY KEY,
+		rolled_until INTEGER NOT NULL -- buckets before this are aggregated
+	);
+
+	CREATE TABLE incidents (
+		id              INTEGER PRIMARY KEY AUTOINCREMENT,
+		endpoint        TEXT NOT NULL,
+		status          TEXT NOT NULL,
+		reason          TEXT NOT NULL DEFAULT '',
+		started_at      INTEGER NOT NULL,
+		ended_at        INTEGER, -- NULL while open
+		acknowledged_at INTEGER, -- NULL until acknowledged
+		acknowledged_by TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX incidents_endpoint_started_at ON incidents (endpoint, started_at);
+	CREATE INDEX incidents_open ON incidents (ended_at) WHERE ended_at IS NULL;
+
+	CREATE TABLE silences (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		endpoints  TEXT NOT NULL, -- JSON array of endpoint IDs
+		tags       TEXT NOT NULL, -- JSON array
+		matchers   TEXT NOT NULL DEFAULT '[]', -- JSON array of label matchers
+		starts_at  INTEGER NOT NULL,
+		ends_at    INTEGER NOT NULL,
+		comment    TEXT NOT NULL DEFAULT '',
+		created_by TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL
+	);
+	CREATE INDEX silences_ends_at ON silences (ends_at);
+
+	-- Endpoints added, changed, paused or deleted through the API
+	CREATE TABLE endpoint_changes (
+		id         TEXT PRIMARY KEY, -- endpoint ID
+		definition TEXT NOT NULL DEFAULT '', -- JSON given to the API, empty keeps the configured one
+		paused     INTEGER NOT NULL DEFAULT 0,
+		deleted    INTEGER NOT NULL DEFAULT 0,
+		updated_at INTEGER NOT NULL
+	);`,
+}
+
+// SQLiteStore is a Store backed by a SQLite database file.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// details holds the type specific parts of a result, stored as JSON.
+type details struct {
+	Answers  []string         `json:"answers,omitempty"`
+	TLS      *common.TLSInfo  `json:"tls,omitempty"`
+	Attempts []common.Attempt `json:"attempts,omitempty"`
+	Flapping bool             `json:"flapping,omitempty"`
+}
+
+// OpenSQLite opens (creating if needed) the database at path and migrates its schema.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite: database path must not be empty")
+	}
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("sqlite: creating database directory: %w", err)
+		}
+	}
+
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: open: %w", err)
+	}
+	// SQLite allows a single writer; one connection avoids SQLITE_BUSY between our own goroutines.
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteStore{db: db}
+	if err := s.migrate(context.Background()); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SQLiteStore) migrate(ctx context.Context) error {
+	var version int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("sqlite: reading schema version: %w", err)
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("sqlite: migration %d: %w", i+1, err)
+		}
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("sqlite: migration %d: %w", i+1, err)
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("sqlite: migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("sqlite: migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) SaveEndpoints(ctx context.Context, endpoints []common.Endpoint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UnixMilli()
+	for _, ep := range endpoints {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO endpoints (id, name, url, type, method, interval_ms, timeout_ms, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET
+				name = excluded.name,
+				url = excluded.url,
+				type = excluded.type,
+				method = excluded.method,
+				interval_ms = excluded.interval_ms,
+				timeout_ms = excluded.timeout_ms,
+				updated_at = excluded.updated_at`,
+			ep.ID, ep.Name, ep.URL, ep.Type, ep.Method, ep.Interval.Milliseconds(), ep.Timeout.Milliseconds(), now,
+		)
+		if err != nil {
+			return fmt.Errorf("saving endpoint %q: %w", ep.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) SaveResult(ctx context.Context, result common.Result) error {
+	messages, err := json.Marshal(nonNil(result.Messages))
+	if err != nil {
+		return err
+	}
+	extra, err := json.Marshal(details{
+		Answers:  result.Answers,
+		TLS:      result.TLS,
+		Attempts: result.Attempts,
+		Flapping: result.Flapping,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO checks (endpoint, url, status, status_code, timestamp, elapsed_ms, error, messages, details, maintenance)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		result.EndpointID, result.URL, result.Status, result.StatusCode, result.Timestamp.UnixMilli(), result.Elapsed,
+		result.Error, string(messages), string(extra), result.Maintenance,
+	)
+	if err != nil {
+		return fmt.Errorf("saving result: %w", err)
+	}
+	return nil
+}
+
+const checkColumns = "endpoint, url, status, status_code, timestamp, elapsed_ms, error, messages, details, maintenance"
+
+func (s *SQLiteStore) History(ctx context.Context, query HistoryQuery) ([]common.Result, error) {
+	where := []string{"endpoint = ?"}
+	args := []any{query.Endpoint}
+	if !query.From.IsZero() {
+		where = append(where, "timestamp >= ?")
+		args = append(args, query.From.UnixMilli())
+	}
+	if !query.To.IsZero() {
+		where = append(where, "timestamp <= ?")
+		args = append(args, query.To.UnixMilli())
+	}
+
+	stmt := "SELECT " + checkColumns + " FROM checks WHERE " + strings.Join(where, " AND ") + " ORDER BY timestamp DESC, id DESC"
+	if query.Limit > 0 {
+		stmt += " LIMIT ? OFFSET ?"
+		args = append(args, query.Limit, query.Offset)
+	}
+
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying history: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]common.Result, 0)
+	for rows.Next() {
+		res, err := scanResult(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	return results, rows.Err()
+}
+
+func (s *SQLiteStore) Latest(ctx context.Context) (map[string]common.Result, error) {
+	return s.latest(ctx, "1 = 1")
+}
+
+func (s *SQLiteStore) LatestObserved(ctx context.Context) (map[string]common.Result, error) {
+	return s.latest(ctx, "maintenance = 0")
+}
+
+// latest returns the most recent result of every endpoint among the checks matching where.
+func (s *SQLiteStore) latest(ctx context.Context, where string) (map[string]common.Result, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+checkColumns+` FROM checks
+		WHERE id IN (SELECT MAX(id) FROM checks WHERE `+where+` GROUP BY endpoint)`)
+	if err != nil {
+		return nil, fmt.Errorf("querying latest results: %w", err)
+	}
+	defer rows.Close()
+
+	latest := make(map[string]common.Result)
+	for rows.Next() {
+		res, err := scanResult(rows)
+		if err != nil {
+			return nil, err
+		}
+		latest[res.EndpointID] = res
+	}
+	return latest, rows.Err()
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanResult(row scanner) (common.Result, error) {
+	var (
+		res       common.Result
+		timestamp int64
+		messages  string
+		extra     string
+	)
+	if err := row.Scan(&res.EndpointID, &res.URL, &res.Status, &res.StatusCode, &timestamp, &res.Elapsed, &res.Error, &messages, &extra, &res.Maintenance); err != nil {
+		return res, fmt.Errorf("scanning result: %w", err)
+	}
+	res.Timestamp = time.UnixMilli(timestamp)
+
+	if err := json.Unmarshal([]byte(messages), &res.Messages); err != nil {
+		return res, fmt.Errorf("decoding messages: %w", err)
+	}
+	var d details
+	if err := json.Unmarshal([]byte(extra), &d); err != nil {
+		return res, fmt.Errorf("decoding details: %w", err)
+	}
+	res.Answers, res.TLS, res.Attempts, res.Flapping = d.Answers, d.TLS, d.Attempts, d.Flapping
+
+	return res, nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (s *SQLiteStore) SaveAlert(ctx context.Context, alert *Alert) error {
+	incident := sql.NullInt64{Int64: alert.IncidentID, Valid: alert.IncidentID != 0}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO alerts (endpoint, incident, notifier, old_status, new_status, title, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		alert.EndpointID, incident, alert.Notifier, alert.OldStatus, alert.NewStatus, alert.Title, alert.Error,
+		alert.CreatedAt.UnixMilli(),
+	)
+	if err != nil {
+		return fmt.Errorf("saving alert: %w", err)
+	}
+	alert.ID, err = res.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStore) Alerts(ctx context.Context, query AlertQuery) ([]Alert, error) {
+	where := []string{"1 = 1"}
+	args := []any{}
+	if query.Endpoint != "" {
+		where = append(where, "endpoint = ?")
+		args = append(args, query.Endpoint)
+	}
+	if !query.From.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, query.From.UnixMilli())
+	}
+	if !query.To.IsZero() {
+		where = append(where, "created_at <= ?")
+		args = append(args, query.To.UnixMilli())
+	}
+
+	stmt := `SELECT id, endpoint, incident, notifier, old_status, new_status, title, error, created_at
+		FROM alerts WHERE ` + strings.Join(where, " AND ") + " ORDER BY created_at DESC, id DESC"
+	if query.Limit > 0 {
+		stmt += " LIMIT ? OFFSET ?"
+		args = append(args, query.Limit, query.Offset)
+	}
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := make([]Alert, 0)
+	for rows.Next() {
+		var (
+			alert     Alert
+			incident  sql.NullInt64
+			createdAt int64
+		)
+		err := rows.Scan(&alert.ID, &alert.EndpointID, &incident, &alert.Notifier, &alert.OldStatus, &alert.NewStatus,
+			&alert.Title, &alert.Error, &createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("scanning alert: %w", err)
+		}
+		alert.IncidentID = incident.Int64
+		alert.CreatedAt = time.UnixMilli(createdAt)
+		alerts = append(alerts, alert)
+	}
+	return alerts, rows.Err()
+}
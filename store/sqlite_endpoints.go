@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func (s *SQLiteStore) SaveEndpointChange(ctx context.Context, change EndpointChange) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO endpoint_changes (id, definition, paused, deleted, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			definition = excluded.definition,
+			paused     = excluded.paused,
+			deleted    = excluded.deleted,
+			updated_at = excluded.updated_at`,
+		change.ID, string(change.Definition), change.Paused, change.Deleted, change.UpdatedAt.UnixMilli(),
+	)
+	if err != nil {
+		return fmt.Errorf("saving endpoint change: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) EndpointChanges(ctx context.Context) ([]EndpointChange, error) {
+	// Upserts keep the rowid, so it orders endpoints by their first change
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, definition, paused, deleted, updated_at
+		FROM endpoint_changes ORDER BY rowid`)
+	if err != nil {
+		return nil, fmt.Errorf("querying endpoint changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := make([]EndpointChange, 0)
+	for rows.Next() {
+		var (
+			change     EndpointChange
+			definition string
+			updatedAt  int64
+		)
+		if err := rows.Scan(&change.ID, &definition, &change.Paused, &change.Deleted, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scanning endpoint change: %w", err)
+		}
+		if definition != "" {
+			change.Definition = []byte(definition)
+		}
+		change.UpdatedAt = time.UnixMilli(updatedAt)
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+func (s *SQLiteStore) OpenIncident(ctx context.Context, incident *common.Incident) error {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO incidents (endpoint, status, reason, started_at) VALUES (?, ?, ?, ?)`,
+		incident.EndpointID, incident.Status, incident.Reason, incident.StartedAt.UnixMilli(),
+	)
+	if err != nil {
+		return fmt.Errorf("saving incident: %w", err)
+	}
+	incident.ID, err = res.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStore) CloseIncident(ctx context.Context, incident common.Incident) error {
+	res, err := s.db.ExecContext(ctx, "UPDATE incidents SET ended_at = ? WHERE id = ?",
+		incident.EndedAt.UnixMilli(), incident.ID)
+	if err != nil {
+		return fmt.Errorf("closing incident: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("closing incident: incident %d not found", incident.ID)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) AcknowledgeIncident(ctx context.Context, id int64, by string, at time.Time) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE incidents SET acknowledged_at = ?, acknowledged_by = ?
+		WHERE id = ? AND ended_at IS NULL AND acknowledged_at IS NULL`,
+		at.UnixMilli(), by, id)
+	if err != nil {
+		return fmt.Errorf("acknowledging incident: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		var open int
+		err := s.db.QueryRowContext(ctx, "SELECT 1 FROM incidents WHERE id = ?", id).Scan(&open)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("acknowledging incident %d: %w", id, ErrIncidentNotFound)
+		}
+		return fmt.Errorf("acknowledging incident %d: %w", id, ErrIncidentNotOpen)
+	}
+	return nil
+}
+
+const incidentColumns = "id, endpoint, status, reason, started_at, ended_at, acknowledged_at, acknowledged_by"
+
+func (s *SQLiteStore) Incidents(ctx context.Context, query IncidentQuery) ([]common.Incident, error) {
+	where := []string{"1 = 1"}
+	args := []any{}
+	if query.Endpoint != "" {
+		where = append(where, "endpoint = ?")
+		args = append(args, query.Endpoint)
+	}
+	if !query.From.IsZero() {
+		where = append(where, "started_at >= ?")
+		args = append(args, query.From.UnixMilli())
+	}
+	if !query.To.IsZero() {
+		where = append(where, "started_at <= ?")
+		args = append(args, query.To.UnixMilli())
+	}
+	if query.OnlyOpen {
+		where = append(where, "ended_at IS NULL")
+	}
+
+	stmt := "SELECT " + incidentColumns + " FROM incidents WHERE " + strings.Join(where, " AND ") + " ORDER BY started_at DESC, id DESC"
+	if query.Limit > 0 {
+		stmt += " LIMIT ? OFFSET ?"
+		args = append(args, query.Limit, query.Offset)
+	}
+	return s.queryIncidents(ctx, stmt, args...)
+}
+
+func (s *SQLiteStore) OpenIncidents(ctx context.Context) ([]common.Incident, error) {
+	return s.queryIncidents(ctx, "SELECT "+incidentColumns+" FROM incidents WHERE ended_at IS NULL ORDER BY id")
+}
+
+func (s *SQLiteStore) queryIncidents(ctx context.Context, stmt string, args ...any) ([]common.Incident, error) {
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying incidents: %w", err)
+	}
+	defer rows.Close()
+
+	incidents := make([]common.Incident, 0)
+	for rows.Next() {
+		var (
+			incident       common.Incident
+			startedAt      int64
+			endedAt        sql.NullInt64
+			acknowledgedAt sql.NullInt64
+		)
+		err := rows.Scan(&incident.ID, &incident.EndpointID, &incident.Status, &incident.Reason, &startedAt, &endedAt,
+			&acknowledgedAt, &incident.AcknowledgedBy)
+		if err != nil {
+			return nil, fmt.Errorf("scanning incident: %w", err)
+		}
+		incident.StartedAt = time.UnixMilli(startedAt)
+		if endedAt.Valid {
+			incident.EndedAt = time.UnixMilli(endedAt.Int64)
+		}
+		if acknowledgedAt.Valid {
+			incident.AcknowledgedAt = time.UnixMilli(acknowledgedAt.Int64)
+		}
+		incidents = append(incidents, incident)
+	}
+	return incidents, rows.Err()
+}
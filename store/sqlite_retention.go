@@ -0,0 +1,294 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+func (s *SQLiteStore) Aggregates(ctx context.Context, query AggregateQuery) ([]Aggregate, error) {
+	if query.Resolution.Duration() == 0 {
+		return nil, fmt.Errorf("unknown resolution: %q", query.Resolution)
+	}
+
+	stmt := `SELECT endpoint, resolution, bucket, count, up, degraded, down, min_ms, avg_ms, max_ms, p95_ms
+		FROM aggregates WHERE endpoint = ? AND resolution = ?`
+	args := []any{query.Endpoint, query.Resolution}
+	if !query.From.IsZero() {
+		stmt += " AND bucket >= ?"
+		args = append(args, query.From.UnixMilli())
+	}
+	if !query.To.IsZero() {
+		stmt += " AND bucket <= ?"
+		args = append(args, query.To.UnixMilli())
+	}
+	stmt += " ORDER BY bucket"
+
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying aggregates: %w", err)
+	}
+	defer rows.Close()
+
+	aggregates := make([]Aggregate, 0)
+	for rows.Next() {
+		var (
+			agg    Aggregate
+			bucket int64
+		)
+		err := rows.Scan(&agg.Endpoint, &agg.Resolution, &bucket, &agg.Count, &agg.Up, &agg.Degraded, &agg.Down,
+			&agg.MinElapsed, &agg.AvgElapsed, &agg.MaxElapsed, &agg.P95Elapsed)
+		if err != nil {
+			return nil, fmt.Errorf("scanning aggregate: %w", err)
+		}
+		agg.Bucket = time.UnixMilli(bucket).UTC()
+		aggregates = append(aggregates, agg)
+	}
+	return aggregates, rows.Err()
+}
+
+// Maintain rolls up every resolution first, then applies retention, so raw rows
+// are never deleted before they have been aggregated.
+func (s *SQLiteStore) Maintain(ctx context.Context, policy RetentionPolicy, now time.Time) (MaintenanceStats, error) {
+	var stats MaintenanceStats
+
+	for _, r := range Resolutions {
+		n, err := s.rollup(ctx, r, r.Truncate(now.Add(-policy.Delay)))
+		if err != nil {
+			return stats, fmt.Errorf("rolling up %s aggregates: %w", r, err)
+		}
+		stats.Aggregated += n
+	}
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM checks WHERE timestamp < ?", now.Add(-policy.Raw).UnixMilli())
+	if err != nil {
+		return stats, fmt.Errorf("deleting expired results: %w", err)
+	}
+	stats.DeletedRaw, _ = res.RowsAffected()
+
+	for _, r := range Resolutions {
+		keep := policy.For(r)
+		if keep == 0 {
+			continue
+		}
+		res, err := s.db.ExecContext(ctx, "DELETE FROM aggregates WHERE resolution = ? AND bucket < ?",
+			r, now.Add(-keep).UnixMilli())
+		if err != nil {
+			return stats, fmt.Errorf("deleting expired %s aggregates: %w", r, err)
+		}
+		n, _ := res.RowsAffected()
+		stats.DeletedAggregates += n
+	}
+
+	return stats, nil
+}
+
+// rollup aggregates the raw results between the last rolled up bucket and until.
+func (s *SQLiteStore) rollup(ctx context.Context, r Resolution, until time.Time) (int, error) {
+	from, err := s.rolledUntil(ctx, r)
+	if err != nil {
+		return 0, err
+	}
+	if from.IsZero() || !from.Before(until) {
+		return 0, nil
+	}
+
+	aggregates, err := s.aggregateRange(ctx, r, from, until)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, agg := range aggregates {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO aggregates (endpoint, resolution, bucket, count, up, degraded, down, min_ms, avg_ms, max_ms, p95_ms)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (endpoint, resolution, bucket) DO UPDATE SET
+				count = excluded.count,
+				up = excluded.up,
+				degraded = excluded.degraded,
+				down = excluded.down,
+				min_ms = excluded.min_ms,
+				avg_ms = excluded.avg_ms,
+				max_ms = excluded.max_ms,
+				p95_ms = excluded.p95_ms`,
+			agg.Endpoint, agg.Resolution, agg.Bucket.UnixMilli(), agg.Count, agg.Up, agg.Degraded, agg.Down,
+			agg.MinElapsed, agg.AvgElapsed, agg.MaxElapsed, agg.P95Elapsed,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rollups (resolution, rolled_until) VALUES (?, ?)
+		ON CONFLICT (resolution) DO UPDATE SET rolled_until = excluded.rolled_until`,
+		r, until.UnixMilli(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(aggregates), tx.Commit()
+}
+
+// rolledUntil returns where the next rollup of r starts: the stored watermark,
+// or the bucket of the oldest raw result on the first run. Zero means nothing to do.
+func (s *SQLiteStore) rolledUntil(ctx context.Context, r Resolution) (time.Time, error) {
+	var until int64
+	err := s.db.QueryRowContext(ctx, "SELECT rolled_until FROM rollups WHERE resolution = ?", r).Scan(&until)
+	if err == nil {
+		return time.UnixMilli(until), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, err
+	}
+
+	var oldest sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, "SELECT MIN(timestamp) FROM checks").Scan(&oldest); err != nil {
+		return time.Time{}, err
+	}
+	if !oldest.Valid {
+		return time.Time{}, nil
+	}
+	return r.Truncate(time.UnixMilli(oldest.Int64)), nil
+}
+
+// aggregateRange builds the aggregates of every endpoint and bucket in [from, until).
+// Results checked during maintenance are left out so they don't count against uptime.
+func (s *SQLiteStore) aggregateRange(ctx context.Context, r Resolution, from, until time.Time) ([]Aggregate, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT endpoint, timestamp, status, elapsed_ms FROM checks
+		WHERE timestamp >= ? AND timestamp < ? AND maintenance = 0
+		ORDER BY endpoint, timestamp`,
+		from.UnixMilli(), until.UnixMilli(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		aggregates []Aggregate
+		endpoint   string
+		bucket     time.Time
+		statuses   []string
+		elapsed    []int
+	)
+	flush := func() {
+		if len(statuses) > 0 {
+			aggregates = append(aggregates, aggregate(endpoint, r, bucket, statuses, elapsed))
+		}
+		statuses, elapsed = statuses[:0], elapsed[:0]
+	}
+
+	for rows.Next() {
+		var (
+			ep        string
+			timestamp int64
+			status    string
+			ms        int
+		)
+		if err := rows.Scan(&ep, &timestamp, &status, &ms); err != nil {
+			return nil, err
+		}
+		b := r.Truncate(time.UnixMilli(timestamp))
+		if ep != endpoint || !b.Equal(bucket) {
+			flush()
+			endpoint, bucket = ep, b
+		}
+		statuses = append(statuses, status)
+		elapsed = append(elapsed, ms)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	return aggregates, nil
+}
+
+// Uptime reads whole hours from the hourly aggregates once they are rolled up,
+// and the rest, including hours whose raw results may be gone, from raw results.
+func (s *SQLiteStore) Uptime(ctx context.Context, query UptimeQuery) (Uptime, error) {
+	uptime := Uptime{Endpoint: query.Endpoint, From: query.From, To: query.To}
+	if !query.From.Before(query.To) {
+		return uptime, nil
+	}
+
+	rolled, err := s.rolledUntil(ctx, Hour)
+	if err != nil {
+		return uptime, err
+	}
+	// Hours fully within the range and rolled up: [start, end)
+	start := Hour.Truncate(query.From)
+	if start.Before(query.From) {
+		start = start.Add(time.Hour)
+	}
+	end := Hour.Truncate(query.To)
+	if rolled.Before(end) {
+		end = Hour.Truncate(rolled)
+	}
+
+	if !start.Before(end) {
+		return uptime, s.countRaw(ctx, &uptime, query.From, query.To)
+	}
+	if err := s.countRaw(ctx, &uptime, query.From, start); err != nil {
+		return uptime, err
+	}
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(count), 0), COALESCE(SUM(up), 0), COALESCE(SUM(degraded), 0), COALESCE(SUM(down), 0)
+		FROM aggregates WHERE endpoint = ? AND resolution = ? AND bucket >= ? AND bucket < ?`,
+		query.Endpoint, Hour, start.UnixMilli(), end.UnixMilli(),
+	).Scan(&uptime.Count, &uptime.Up, &uptime.Degraded, &uptime.Down)
+	if err != nil {
+		return uptime, fmt.Errorf("summing aggregates: %w", err)
+	}
+	return uptime, s.countRaw(ctx, &uptime, end, query.To)
+}
+
+// countRaw adds the raw results of u's endpoint within [from, until) to u.
+func (s *SQLiteStore) countRaw(ctx context.Context, u *Uptime, from, until time.Time) error {
+	if !from.Before(until) {
+		return nil
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT status, COUNT(*) FROM checks
+		WHERE endpoint = ? AND timestamp >= ? AND timestamp < ? AND maintenance = 0
+		GROUP BY status`,
+		u.Endpoint, from.UnixMilli(), until.UnixMilli(),
+	)
+	if err != nil {
+		return fmt.Errorf("counting results: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			status string
+			n      int
+		)
+		if err := rows.Scan(&status, &n); err != nil {
+			return err
+		}
+		u.Count += n
+		switch status {
+		case common.StatusUp:
+			u.Up += n
+		case common.StatusDegraded:
+			u.Degraded += n
+		default:
+			u.Down += n
+		}
+	}
+	return rows.Err()
+}
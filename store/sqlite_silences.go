@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+func (s *SQLiteStore) CreateSilence(ctx context.Context, silence *common.Silence) error {
+	endpoints, err := json.Marshal(nonNil(silence.Endpoints))
+	if err != nil {
+		return err
+	}
+	tags, err := json.Marshal(nonNil(silence.Tags))
+	if err != nil {
+		return err
+	}
+	matchers, err := json.Marshal(nonNil(silence.Matchers))
+	if err != nil {
+		return err
+	}
+
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO silences (endpoints, tags, matchers, starts_at, ends_at, comment, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		string(endpoints), string(tags), string(matchers), silence.StartsAt.UnixMilli(), silence.EndsAt.UnixMilli(),
+		silence.Comment, silence.CreatedBy, silence.CreatedAt.UnixMilli(),
+	)
+	if err != nil {
+		return fmt.Errorf("saving silence: %w", err)
+	}
+	silence.ID, err = res.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStore) ExpireSilence(ctx context.Context, id int64, at time.Time) error {
+	res, err := s.db.ExecContext(ctx, "UPDATE silences SET ends_at = MIN(ends_at, ?) WHERE id = ?", at.UnixMilli(), id)
+	if err != nil {
+		return fmt.Errorf("expiring silence: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("expiring silence: silence %d not found", id)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Silences(ctx context.Context, now time.Time) ([]common.Silence, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, endpoints, tags, matchers, starts_at, ends_at, comment, created_by, created_at
+		FROM silences WHERE ends_at > ? ORDER BY starts_at, id`, now.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("querying silences: %w", err)
+	}
+	defer rows.Close()
+
+	silences := make([]common.Silence, 0)
+	for rows.Next() {
+		var (
+			silence                     common.Silence
+			endpoints, tags, matchers   string
+			startsAt, endsAt, createdAt int64
+		)
+		if err := rows.Scan(&silence.ID, &endpoints, &tags, &matchers, &startsAt, &endsAt, &silence.Comment, &silence.CreatedBy, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning silence: %w", err)
+		}
+		if err := json.Unmarshal([]byte(endpoints), &silence.Endpoints); err != nil {
+			return nil, fmt.Errorf("decoding silence endpoints: %w", err)
+		}
+		if err := json.Unmarshal([]byte(tags), &silence.Tags); err != nil {
+			return nil, fmt.Errorf("decoding silence tags: %w", err)
+		}
+		if err := json.Unmarshal([]byte(matchers), &silence.Matchers); err != nil {
+			return nil, fmt.Errorf("decoding silence matchers: %w", err)
+		}
+		silence.StartsAt, silence.EndsAt, silence.CreatedAt = time.UnixMilli(startsAt), time.UnixMilli(endsAt), time.UnixMilli(createdAt)
+		silences = append(silences, silence)
+	}
+	return silences, rows.Err()
+}
@@ -0,0 +1,474 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+func openTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+
+	s, err := OpenSQLite(filepath.Join(t.TempDir(), "data", "monitor.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSQLiteStore_SaveAndHistory(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := range 5 {
+		err := s.SaveResult(ctx, common.Result{
+			EndpointID: "a",
+			URL:        "http://a",
+			Status:     common.StatusUp,
+			StatusCode: 200,
+			Timestamp:  base.Add(time.Duration(i) * time.Minute),
+			Elapsed:    10 + i,
+		})
+		if err != nil {
+			t.Fatalf("save result: %v", err)
+		}
+	}
+	if err := s.SaveResult(ctx, common.Result{EndpointID: "b", URL: "http://b", Status: common.StatusDown, Timestamp: base}); err != nil {
+		t.Fatalf("save result: %v", err)
+	}
+
+	all, err := s.History(ctx, HistoryQuery{Endpoint: "a"})
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(all) != 5 {
+		t.Fatalf("Expected 5 results, got %d", len(all))
+	}
+	if all[0].Elapsed != 14 {
+		t.Errorf("Expected newest result first, got elapsed %d", all[0].Elapsed)
+	}
+
+	ranged, err := s.History(ctx, HistoryQuery{
+		Endpoint: "a",
+		From:     base.Add(1 * time.Minute),
+		To:       base.Add(3 * time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(ranged) != 3 {
+		t.Errorf("Expected 3 results in range, got %d", len(ranged))
+	}
+
+	page, err := s.History(ctx, HistoryQuery{Endpoint: "a", Limit: 2, Offset: 2})
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(page) != 2 || page[0].Elapsed != 12 {
+		t.Errorf("Expected second page starting at elapsed 12, got %+v", page)
+	}
+}
+
+func TestSQLiteStore_RoundTrip(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	want := common.Result{
+		EndpointID: "example",
+		URL:        "example.test",
+		Status:     common.StatusDegraded,
+		StatusCode: 0,
+		Timestamp:  time.UnixMilli(time.Now().UnixMilli()),
+		Elapsed:    42,
+		Error:      "boom",
+		Messages:   []string{common.UnexpectedAnswerMessage},
+		Answers:    []string{"10.0.0.1"},
+		TLS:        &common.TLSInfo{Issuer: "CN=CA", DaysUntilExpiry: 12, ChainValid: true},
+		Attempts:   []common.Attempt{{Status: common.StatusDown}, {Status: common.StatusDegraded}},
+		Flapping:   true,
+	}
+	if err := s.SaveResult(ctx, want); err != nil {
+		t.Fatalf("save result: %v", err)
+	}
+
+	got, err := s.History(ctx, HistoryQuery{Endpoint: "example"})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("history: %v (%d results)", err, len(got))
+	}
+
+	res := got[0]
+	if res.EndpointID != want.EndpointID || res.URL != want.URL || res.Status != want.Status || res.Error != want.Error || res.Elapsed != want.Elapsed || !res.Timestamp.Equal(want.Timestamp) {
+		t.Errorf("Expected %+v, got %+v", want, res)
+	}
+	if !slices.Equal(res.Messages, want.Messages) || !slices.Equal(res.Answers, want.Answers) {
+		t.Errorf("Expected messages/answers to round trip, got %v / %v", res.Messages, res.Answers)
+	}
+	if res.TLS == nil || res.TLS.DaysUntilExpiry != 12 {
+		t.Errorf("Expected TLS details to round trip, got %+v", res.TLS)
+	}
+	if len(res.Attempts) != 2 {
+		t.Errorf("Expected 2 attempts, got %d", len(res.Attempts))
+	}
+	if !res.Flapping {
+		t.Errorf("Expected the flapping flag to round trip")
+	}
+}
+
+func TestSQLiteStore_Latest(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	results := []common.Result{
+		{EndpointID: "a", URL: "http://a", Status: common.StatusUp, Timestamp: now.Add(-2 * time.Minute)},
+		{EndpointID: "a", URL: "http://a", Status: common.StatusDown, Timestamp: now.Add(-time.Minute)},
+		{EndpointID: "b", URL: "http://b", Status: common.StatusDegraded, Timestamp: now},
+	}
+	for _, r := range results {
+		if err := s.SaveResult(ctx, r); err != nil {
+			t.Fatalf("save result: %v", err)
+		}
+	}
+
+	latest, err := s.Latest(ctx)
+	if err != nil {
+		t.Fatalf("latest: %v", err)
+	}
+	if len(latest) != 2 {
+		t.Fatalf("Expected 2 endpoints, got %d", len(latest))
+	}
+	if latest["a"].Status != common.StatusDown {
+		t.Errorf("Expected latest status down for a, got %s", latest["a"].Status)
+	}
+	if latest["b"].Status != common.StatusDegraded {
+		t.Errorf("Expected latest status degraded for b, got %s", latest["b"].Status)
+	}
+
+	// Results checked during maintenance are the latest, but not observed
+	maintenance := common.Result{EndpointID: "a", URL: "http://a", Status: common.StatusUp, Timestamp: now, Maintenance: true}
+	if err := s.SaveResult(ctx, maintenance); err != nil {
+		t.Fatalf("save result: %v", err)
+	}
+	if latest, _ := s.Latest(ctx); latest["a"].Status != common.StatusUp || !latest["a"].Maintenance {
+		t.Errorf("Expected the maintenance result to be the latest of a, got %+v", latest["a"])
+	}
+	observed, err := s.LatestObserved(ctx)
+	if err != nil {
+		t.Fatalf("latest observed: %v", err)
+	}
+	if len(observed) != 2 || observed["a"].Status != common.StatusDown || observed["b"].Status != common.StatusDegraded {
+		t.Errorf("Expected the last results outside maintenance, got %+v", observed)
+	}
+}
+
+func TestSQLiteStore_SaveEndpoints(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	endpoints := []common.Endpoint{
+		{Name: "a", ID: "a", URL: "http://a", Type: common.HTTPType, Method: "GET", Interval: time.Second, Timeout: time.Second},
+	}
+	if err := s.SaveEndpoints(ctx, endpoints); err != nil {
+		t.Fatalf("save endpoints: %v", err)
+	}
+
+	// Saving again updates in place
+	endpoints[0].Name = "renamed"
+	if err := s.SaveEndpoints(ctx, endpoints); err != nil {
+		t.Fatalf("save endpoints: %v", err)
+	}
+
+	var count int
+	var name string
+	if err := s.db.QueryRow("SELECT COUNT(*), MAX(name) FROM endpoints").Scan(&count, &name); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || name != "renamed" {
+		t.Errorf("Expected one renamed endpoint, got %d %q", count, name)
+	}
+}
+
+func TestSQLiteStore_ReopenKeepsHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monitor.db")
+	ctx := context.Background()
+
+	s, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	if err := s.SaveResult(ctx, common.Result{EndpointID: "a", URL: "http://a", Status: common.StatusUp, Timestamp: time.Now()}); err != nil {
+		t.Fatalf("save result: %v", err)
+	}
+	s.Close()
+
+	s, err = OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	defer s.Close()
+
+	latest, err := s.Latest(ctx)
+	if err != nil {
+		t.Fatalf("latest: %v", err)
+	}
+	if _, ok := latest["a"]; !ok {
+		t.Errorf("Expected history to survive a restart")
+	}
+}
+
+func TestOpen_UnsupportedDriver(t *testing.T) {
+	if _, err := Open("oracle", "x"); err == nil {
+		t.Errorf("Expected an error for an unsupported driver")
+	}
+}
+
+func TestSQLiteStore_SharedURL(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	// Two endpoints checking the same URL with different expectations
+	if err := s.SaveResult(ctx, common.Result{EndpointID: "strict", URL: "http://a", Status: common.StatusDegraded, Timestamp: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveResult(ctx, common.Result{EndpointID: "lenient", URL: "http://a", Status: common.StatusUp, Timestamp: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := s.Latest(ctx)
+	if err != nil {
+		t.Fatalf("latest: %v", err)
+	}
+	if latest["strict"].Status != common.StatusDegraded || latest["lenient"].Status != common.StatusUp {
+		t.Errorf("Expected endpoints sharing a URL to be kept apart, got %+v", latest)
+	}
+}
+
+func TestSQLiteStore_Incidents(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	start := time.UnixMilli(time.Now().Add(-time.Hour).UnixMilli())
+
+	first := common.Incident{EndpointID: "a", Status: common.StatusDown, Reason: "connection refused", StartedAt: start}
+	if err := s.OpenIncident(ctx, &first); err != nil {
+		t.Fatalf("open incident: %v", err)
+	}
+	if first.ID == 0 {
+		t.Fatalf("Expected incident ID to be set")
+	}
+	second := common.Incident{EndpointID: "b", Status: common.StatusUnreachable, StartedAt: start.Add(time.Minute)}
+	if err := s.OpenIncident(ctx, &second); err != nil {
+		t.Fatalf("open incident: %v", err)
+	}
+
+	first.EndedAt = start.Add(5 * time.Minute)
+	if err := s.CloseIncident(ctx, first); err != nil {
+		t.Fatalf("close incident: %v", err)
+	}
+	if err := s.CloseIncident(ctx, common.Incident{ID: 999, EndedAt: start}); err == nil {
+		t.Errorf("Expected an error closing an unknown incident")
+	}
+
+	open, err := s.OpenIncidents(ctx)
+	if err != nil {
+		t.Fatalf("open incidents: %v", err)
+	}
+	if len(open) != 1 || open[0].ID != second.ID {
+		t.Errorf("Expected only the second incident to be open, got %+v", open)
+	}
+
+	all, err := s.Incidents(ctx, IncidentQuery{})
+	if err != nil {
+		t.Fatalf("incidents: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != second.ID {
+		t.Fatalf("Expected 2 incidents newest first, got %+v", all)
+	}
+
+	closed := all[1]
+	if closed.Open() || closed.Duration(time.Now()) != 5*time.Minute || closed.Reason != "connection refused" {
+		t.Errorf("Unexpected closed incident: %+v", closed)
+	}
+
+	byEndpoint, err := s.Incidents(ctx, IncidentQuery{Endpoint: "a"})
+	if err != nil {
+		t.Fatalf("incidents: %v", err)
+	}
+	if len(byEndpoint) != 1 || byEndpoint[0].EndpointID != "a" {
+		t.Errorf("Expected one incident for a, got %+v", byEndpoint)
+	}
+
+	// Only open incidents can be acknowledged, once
+	ackAt := time.UnixMilli(time.Now().UnixMilli())
+	if err := s.AcknowledgeIncident(ctx, second.ID, "alice", ackAt); err != nil {
+		t.Fatalf("acknowledge: %v", err)
+	}
+	if err := s.AcknowledgeIncident(ctx, second.ID, "bob", ackAt); !errors.Is(err, ErrIncidentNotOpen) {
+		t.Errorf("Expected acknowledging twice to fail, got %v", err)
+	}
+	if err := s.AcknowledgeIncident(ctx, closed.ID, "alice", ackAt); !errors.Is(err, ErrIncidentNotOpen) {
+		t.Errorf("Expected acknowledging a resolved incident to fail, got %v", err)
+	}
+	if err := s.AcknowledgeIncident(ctx, 999, "alice", ackAt); !errors.Is(err, ErrIncidentNotFound) {
+		t.Errorf("Expected acknowledging an unknown incident to fail, got %v", err)
+	}
+	open, _ = s.OpenIncidents(ctx)
+	if len(open) != 1 || !open[0].Acknowledged() || open[0].AcknowledgedBy != "alice" || !open[0].AcknowledgedAt.Equal(ackAt) {
+		t.Errorf("Expected the acknowledgement to be stored, got %+v", open)
+	}
+}
+
+func TestSQLiteStore_Schema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monitor.db")
+	s, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	// Reopening an up to date database runs no migration
+	s, err = OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	defer s.Close()
+
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil || version != len(migrations) {
+		t.Errorf("Expected schema version %d, got %d (%v)", len(migrations), version, err)
+	}
+	for _, table := range []string{"endpoints", "checks", "alerts", "aggregates", "rollups", "incidents", "silences", "endpoint_changes"} {
+		var n int
+		if err := s.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&n); err != nil || n != 1 {
+			t.Errorf("Expected table %s to exist (%v)", table, err)
+		}
+	}
+}
+
+func TestSQLiteStore_Alerts(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	now := time.UnixMilli(time.Now().UnixMilli())
+
+	alerts := []Alert{
+		{EndpointID: "api", IncidentID: 7, Notifier: "slack", OldStatus: common.StatusUp, NewStatus: common.StatusDown, Title: "[DOWN] api (was up)", CreatedAt: now.Add(-time.Minute)},
+		{EndpointID: "api", IncidentID: 7, Notifier: "pagerduty", OldStatus: common.StatusUp, NewStatus: common.StatusDown, Title: "[DOWN] api (was up)", Error: "after 4 attempts: 503", CreatedAt: now.Add(-time.Minute)},
+		{EndpointID: "db", Notifier: "slack", NewStatus: common.StatusDown, Title: "[FLAPPING] db (now down)", CreatedAt: now},
+	}
+	for i := range alerts {
+		if err := s.SaveAlert(ctx, &alerts[i]); err != nil {
+			t.Fatalf("save alert: %v", err)
+		}
+		if alerts[i].ID == 0 {
+			t.Errorf("Expected the alert to get an ID")
+		}
+	}
+
+	got, err := s.Alerts(ctx, AlertQuery{})
+	if err != nil {
+		t.Fatalf("alerts: %v", err)
+	}
+	if len(got) != 3 || got[0] != alerts[2] || got[1] != alerts[1] || got[2] != alerts[0] {
+		t.Errorf("Expected every alert newest first, got %+v", got)
+	}
+
+	got, _ = s.Alerts(ctx, AlertQuery{Endpoint: "api", Limit: 1, Offset: 1})
+	if len(got) != 1 || got[0] != alerts[0] {
+		t.Errorf("Expected the second alert of api, got %+v", got)
+	}
+	got, _ = s.Alerts(ctx, AlertQuery{From: now})
+	if len(got) != 1 || got[0].EndpointID != "db" || got[0].IncidentID != 0 {
+		t.Errorf("Expected the alert of db only, got %+v", got)
+	}
+}
+
+func TestSQLiteStore_Silences(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	now := time.UnixMilli(time.Now().UnixMilli())
+
+	silence := common.Silence{
+		Scope:     common.Scope{Endpoints: []string{"api"}, Tags: []string{"db"}, Matchers: []string{`team="payments"`}},
+		StartsAt:  now,
+		EndsAt:    now.Add(time.Hour),
+		Comment:   "db upgrade",
+		CreatedBy: "alice",
+		CreatedAt: now,
+	}
+	if err := s.CreateSilence(ctx, &silence); err != nil {
+		t.Fatalf("create silence: %v", err)
+	}
+	if silence.ID == 0 {
+		t.Fatalf("Expected the silence ID to be set")
+	}
+	ended := common.Silence{Scope: common.Scope{Tags: []string{"db"}}, StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour), CreatedAt: now}
+	if err := s.CreateSilence(ctx, &ended); err != nil {
+		t.Fatalf("create silence: %v", err)
+	}
+
+	silences, err := s.Silences(ctx, now)
+	if err != nil {
+		t.Fatalf("silences: %v", err)
+	}
+	if len(silences) != 1 {
+		t.Fatalf("Expected only the ongoing silence, got %+v", silences)
+	}
+	got := silences[0]
+	if got.ID != silence.ID || !slices.Equal(got.Endpoints, silence.Endpoints) || !slices.Equal(got.Tags, silence.Tags) ||
+		!slices.Equal(got.Matchers, silence.Matchers) ||
+		got.Comment != "db upgrade" || got.CreatedBy != "alice" || !got.EndsAt.Equal(silence.EndsAt) {
+		t.Errorf("Expected %+v, got %+v", silence, got)
+	}
+
+	if err := s.ExpireSilence(ctx, silence.ID, now); err != nil {
+		t.Fatalf("expire silence: %v", err)
+	}
+	if silences, _ := s.Silences(ctx, now); len(silences) != 0 {
+		t.Errorf("Expected no silence after expiring it, got %+v", silences)
+	}
+	if err := s.ExpireSilence(ctx, 42, now); err == nil {
+		t.Errorf("Expected an error expiring an unknown silence")
+	}
+}
+
+func TestSQLiteStore_EndpointChanges(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	now := time.UnixMilli(time.Now().UnixMilli())
+
+	for _, change := range []EndpointChange{
+		{ID: "new", Definition: []byte(`{"url":"http://new.local"}`), UpdatedAt: now},
+		{ID: "api", Paused: true, UpdatedAt: now},
+		{ID: "new", Definition: []byte(`{"url":"http://new.local/health"}`), Paused: true, UpdatedAt: now.Add(time.Minute)},
+		{ID: "db", Deleted: true, UpdatedAt: now},
+	} {
+		if err := s.SaveEndpointChange(ctx, change); err != nil {
+			t.Fatalf("save endpoint change: %v", err)
+		}
+	}
+
+	changes, err := s.EndpointChanges(ctx)
+	if err != nil {
+		t.Fatalf("endpoint changes: %v", err)
+	}
+	if len(changes) != 3 {
+		t.Fatalf("Expected one change per endpoint, got %+v", changes)
+	}
+	fresh, api, db := changes[0], changes[1], changes[2]
+	if fresh.ID != "new" || string(fresh.Definition) != `{"url":"http://new.local/health"}` || !fresh.Paused || !fresh.UpdatedAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected the latest change of new first, got %+v", fresh)
+	}
+	if api.ID != "api" || api.Definition != nil || !api.Paused || api.Deleted {
+		t.Errorf("Expected api to be paused with its configured definition, got %+v", api)
+	}
+	if db.ID != "db" || !db.Deleted {
+		t.Errorf("Expected db to be deleted, got %+v", db)
+	}
+}
@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// Supported drivers
+const (
+	DriverSQLite = "sqlite"
+)
+
+// Errors of AcknowledgeIncident
+var (
+	ErrIncidentNotFound = errors.New("incident not found")
+	ErrIncidentNotOpen  = errors.New("incident resolved or already acknowledged")
+)
+
+// Store persists check results and serves their history.
+type Store interface {
+	// SaveEndpoints records the monitored endpoint definitions.
+	SaveEndpoints(ctx context.Context, endpoints []common.Endpoint) error
+	// SaveResult appends a check result.
+	SaveResult(ctx context.Context, result common.Result) error
+	// History returns the results of an endpoint, newest first.
+	History(ctx context.Context, query HistoryQuery) ([]common.Result, error)
+	// Latest returns the most recent result of every endpoint, keyed by endpoint ID.
+	Latest(ctx context.Context) (map[string]common.Result, error)
+	// LatestObserved is Latest leaving out the results checked during maintenance,
+	// which the tracked status of an endpoint ignores.
+	LatestObserved(ctx context.Context) (map[string]common.Result, error)
+	// OpenIncident records a new incident and sets its ID.
+	OpenIncident(ctx context.Context, incident *common.Incident) error
+	// CloseIncident records the end of an incident.
+	CloseIncident(ctx context.Context, incident common.Incident) error
+	// Incidents returns the incidents of an endpoint (all endpoints if empty), newest first.
+	Incidents(ctx context.Context, query IncidentQuery) ([]common.Incident, error)
+	// AcknowledgeIncident records that someone is handling an open incident.
+	// It fails with ErrIncidentNotFound or ErrIncidentNotOpen when it cannot.
+	AcknowledgeIncident(ctx context.Context, id int64, by string, at time.Time) error
+	// OpenIncidents returns every incident that has not ended yet.
+	OpenIncidents(ctx context.Context) ([]common.Incident, error)
+	// SaveAlert records an alert handed to a notifier and sets its ID.
+	SaveAlert(ctx context.Context, alert *Alert) error
+	// Alerts returns the alerts of an endpoint (all endpoints if empty), newest first.
+	Alerts(ctx context.Context, query AlertQuery) ([]Alert, error)
+	// Aggregates returns the rolled up history of an endpoint, oldest first.
+	Aggregates(ctx context.Context, query AggregateQuery) ([]Aggregate, error)
+	// Uptime counts the results of an endpoint within a time range, from raw
+	// results and hourly aggregates alike.
+	Uptime(ctx context.Context, query UptimeQuery) (Uptime, error)
+	// Maintain rolls up closed buckets and removes data past its retention.
+	Maintain(ctx context.Context, policy RetentionPolicy, now time.Time) (MaintenanceStats, error)
+	// CreateSilence records a new silence and sets its ID.
+	CreateSilence(ctx context.Context, silence *common.Silence) error
+	// ExpireSilence ends a silence at the given time, if it was to end later.
+	ExpireSilence(ctx context.Context, id int64, at time.Time) error
+	// Silences returns the silences that have not ended at now, ordered by start.
+	Silences(ctx context.Context, now time.Time) ([]common.Silence, error)
+	// SaveEndpointChange records the runtime change of an endpoint, replacing the previous one.
+	SaveEndpointChange(ctx context.Context, change EndpointChange) error
+	// EndpointChanges returns the runtime changes of every endpoint, in the order
+	// their endpoints were first changed.
+	EndpointChanges(ctx context.Context) ([]EndpointChange, error)
+	Close() error
+}
+
+// HistoryQuery selects results of a single endpoint (by ID) within a time range.
+// Zero From/To leave the range open; Limit 0 means no limit.
+type HistoryQuery struct {
+	Endpoint string
+	From     time.Time
+	To       time.Time
+	Limit    int
+	Offset   int
+}
+
+// IncidentQuery selects incidents that started within a time range.
+// An empty Endpoint matches every endpoint; OnlyOpen skips resolved incidents.
+type IncidentQuery struct {
+	Endpoint string
+	From     time.Time
+	To       time.Time
+	OnlyOpen bool
+	Limit    int
+	Offset   int
+}
+
+// Alert is an alert of an endpoint as handed to a notifier. Alerts sent together
+// by a grouping route are recorded one by one.
+type Alert struct {
+	ID         int64
+	EndpointID string
+	IncidentID int64 // 0 when the alert is not about an incident, e.g. flapping
+	Notifier   string
+	OldStatus  string
+	NewStatus  string
+	Title      string
+	Error      string // why it was not delivered, empty once delivered
+	CreatedAt  time.Time
+}
+
+// AlertQuery selects alerts created within a time range.
+// An empty Endpoint matches every endpoint.
+type AlertQuery struct {
+	Endpoint string
+	From     time.Time
+	To       time.Time
+	Limit    int
+	Offset   int
+}
+
+// EndpointChange is how an endpoint was changed at runtime, through the API.
+// Changes outlive restarts: they are applied over the configured endpoints on every start.
+type EndpointChange struct {
+	ID string
+	// Definition is the endpoint as given to the API, in JSON. Empty keeps the
+	// configured definition, e.g. when the endpoint was only paused.
+	Definition []byte
+	Paused     bool
+	Deleted    bool
+	UpdatedAt  time.Time
+}
+
+// Open opens the store for driver; dsn is driver specific (a file path for SQLite).
+func Open(driver, dsn string) (Store, error) {
+	switch driver {
+	case DriverSQLite:
+		return OpenSQLite(dsn)
+	default:
+		return nil, fmt.Errorf("unsupported store driver: %q", driver)
+	}
+}
@@ -0,0 +1,160 @@
+package tcpchecker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// maxReplySize bounds how much of the reply is buffered for assertions.
+const maxReplySize = 4096
+
+// Dialer defines the interface for opening TCP connections
+type Dialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+type TCPChecker struct {
+	dialer Dialer
+}
+
+// NewTCPChecker creates a new checker with a default net.Dialer
+func NewTCPChecker() *TCPChecker {
+	return &TCPChecker{
+		dialer: &net.Dialer{},
+	}
+}
+
+// NewTCPCheckerWithDialer allows injection of a custom dialer (for testing)
+func NewTCPCheckerWithDialer(dialer Dialer) *TCPChecker {
+	return &TCPChecker{
+		dialer: dialer,
+	}
+}
+
+func (c *TCPChecker) Check(ctx context.Context, endpoint common.Endpoint) common.Result {
+	result := common.Result{
+		URL:      endpoint.URL,
+		Messages: make([]string, 0),
+	}
+
+	addr, err := common.ParseHostPort(endpoint.URL)
+	if err != nil {
+		result.Status = common.StatusUnreachable
+		result.Error = err.Error()
+		result.Timestamp = time.Now()
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, endpoint.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	conn, err := c.dialer.DialContext(ctx, "tcp", addr)
+	elapsed := time.Since(start)
+
+	// Elapsed only covers connection establishment, the exchange is bounded by the timeout.
+	result.Elapsed = int(elapsed.Milliseconds())
+	result.Timestamp = time.Now()
+
+	if err != nil {
+		result.Status = common.StatusUnreachable
+		result.Error = err.Error()
+		return result
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	result.Status = common.StatusUp
+
+	if endpoint.TCP.Send != "" {
+		if _, err := conn.Write([]byte(endpoint.TCP.Send)); err != nil {
+			result.Status = common.StatusDown
+			result.Error = fmt.Sprintf("write: %v", err)
+			return result
+		}
+	}
+
+	if endpoint.TCP.HasExpectation() {
+		pattern, err := expectPattern(endpoint.TCP)
+		if err != nil {
+			result.Status = common.StatusDown
+			result.Error = err.Error()
+			return result
+		}
+
+		reply, err := readReply(conn, endpoint.TCP.Expect, pattern)
+		if !matches(reply, endpoint.TCP.Expect, pattern) {
+			result.Status = common.StatusDegraded
+			result.Messages = append(result.Messages, common.UnexpectedReplyMessage)
+			if err != nil {
+				result.Error = fmt.Sprintf("read: %v", err)
+				// Nothing came back at all: the port is open but the service is not answering.
+				if len(reply) == 0 {
+					result.Status = common.StatusDown
+				}
+			}
+		}
+	}
+
+	if endpoint.MaxLatency > 0 && elapsed > endpoint.MaxLatency {
+		if result.Status == common.StatusUp {
+			result.Status = common.StatusDegraded
+		}
+		result.Messages = append(result.Messages, common.UnexpectedLatencyMessage)
+	}
+
+	return result
+}
+
+// expectPattern returns the compiled reply pattern, compiling it on the fly
+// when the endpoint did not go through config validation.
+func expectPattern(opts common.TCPOptions) (*regexp.Regexp, error) {
+	if opts.ExpectPattern != nil || opts.ExpectRegex == "" {
+		return opts.ExpectPattern, nil
+	}
+	pattern, err := regexp.Compile(opts.ExpectRegex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expect_regex: %w", err)
+	}
+	return pattern, nil
+}
+
+// readReply reads from conn until the expectations are met, the peer closes
+// the connection, maxReplySize is reached or the deadline expires.
+func readReply(conn net.Conn, expect string, pattern *regexp.Regexp) ([]byte, error) {
+	var reply bytes.Buffer
+	buf := make([]byte, 512)
+
+	for reply.Len() < maxReplySize {
+		n, err := conn.Read(buf)
+		reply.Write(buf[:min(n, maxReplySize-reply.Len())])
+
+		if matches(reply.Bytes(), expect, pattern) {
+			return reply.Bytes(), nil
+		}
+		if err != nil {
+			return reply.Bytes(), err
+		}
+	}
+	return reply.Bytes(), nil
+}
+
+func matches(reply []byte, expect string, pattern *regexp.Regexp) bool {
+	if expect != "" && !strings.Contains(string(reply), expect) {
+		return false
+	}
+	if pattern != nil && !pattern.Match(reply) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,264 @@
+package tcpchecker
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// startServer runs a loopback TCP server that handles every connection with handle.
+func startServer(t *testing.T, handle func(conn net.Conn)) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestTCPChecker_Check_Connect(t *testing.T) {
+	addr := startServer(t, func(conn net.Conn) {})
+
+	checker := NewTCPChecker()
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     addr,
+		Timeout: time.Second,
+	})
+
+	if result.Status != common.StatusUp {
+		t.Errorf("Expected status Up, got %s (error: %s)", result.Status, result.Error)
+	}
+	if result.Error != "" {
+		t.Errorf("Expected no error, got: %s", result.Error)
+	}
+}
+
+func TestTCPChecker_Check_SchemeAddress(t *testing.T) {
+	addr := startServer(t, func(conn net.Conn) {})
+
+	checker := NewTCPChecker()
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     "tcp://" + addr,
+		Timeout: time.Second,
+	})
+
+	if result.Status != common.StatusUp {
+		t.Errorf("Expected status Up, got %s (error: %s)", result.Status, result.Error)
+	}
+}
+
+func TestTCPChecker_Check_ConnectionRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	checker := NewTCPChecker()
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     addr,
+		Timeout: time.Second,
+	})
+
+	if result.Status != common.StatusUnreachable {
+		t.Errorf("Expected status Unreachable, got %s", result.Status)
+	}
+	if result.Error == "" {
+		t.Errorf("Expected a connection error")
+	}
+}
+
+func TestTCPChecker_Check_InvalidAddress(t *testing.T) {
+	checker := NewTCPChecker()
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     "localhost",
+		Timeout: time.Second,
+	})
+
+	if result.Status != common.StatusUnreachable {
+		t.Errorf("Expected status Unreachable, got %s", result.Status)
+	}
+	if !strings.Contains(result.Error, "invalid address") {
+		t.Errorf("Expected invalid address error, got: %s", result.Error)
+	}
+}
+
+func TestTCPChecker_Check_SendExpect(t *testing.T) {
+	// Minimal Redis-like PING/PONG exchange
+	addr := startServer(t, func(conn net.Conn) {
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return
+		}
+		if strings.TrimSpace(line) == "PING" {
+			conn.Write([]byte("+PONG\r\n"))
+		} else {
+			conn.Write([]byte("-ERR unknown command\r\n"))
+		}
+	})
+
+	tests := []struct {
+		name           string
+		opts           common.TCPOptions
+		expectedStatus string
+	}{
+		{"expect match", common.TCPOptions{Send: "PING\r\n", Expect: "+PONG"}, common.StatusUp},
+		{"regex match", common.TCPOptions{Send: "PING\r\n", ExpectRegex: `^\+PO[N]G`}, common.StatusUp},
+		{"expect mismatch", common.TCPOptions{Send: "HELLO\r\n", Expect: "+PONG"}, common.StatusDegraded},
+		{"regex mismatch", common.TCPOptions{Send: "HELLO\r\n", ExpectRegex: `^\+PONG`}, common.StatusDegraded},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := NewTCPChecker()
+			result := checker.Check(context.Background(), common.Endpoint{
+				URL:     addr,
+				Timeout: time.Second,
+				TCP:     tc.opts,
+			})
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %s, got %s (error: %s)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			found := slices.Contains(result.Messages, common.UnexpectedReplyMessage)
+			if found != (tc.expectedStatus != common.StatusUp) {
+				t.Errorf("Unexpected messages: %v", result.Messages)
+			}
+		})
+	}
+}
+
+func TestTCPChecker_Check_Banner(t *testing.T) {
+	addr := startServer(t, func(conn net.Conn) {
+		conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+		// Keep the connection open like a real server waiting for the client
+		time.Sleep(500 * time.Millisecond)
+	})
+
+	checker := NewTCPChecker()
+	start := time.Now()
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     addr,
+		Timeout: 2 * time.Second,
+		TCP:     common.TCPOptions{ExpectRegex: `^SSH-2\.0-`},
+	})
+
+	if result.Status != common.StatusUp {
+		t.Errorf("Expected status Up, got %s (error: %s)", result.Status, result.Error)
+	}
+	// The checker must return as soon as the banner matched instead of waiting for the timeout
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected check to return once the banner matched, took %s", time.Since(start))
+	}
+}
+
+func TestTCPChecker_Check_NoReply(t *testing.T) {
+	addr := startServer(t, func(conn net.Conn) {
+		time.Sleep(500 * time.Millisecond)
+	})
+
+	checker := NewTCPChecker()
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     addr,
+		Timeout: 100 * time.Millisecond,
+		TCP:     common.TCPOptions{Send: "PING\r\n", Expect: "+PONG"},
+	})
+
+	if result.Status != common.StatusDown {
+		t.Errorf("Expected status Down, got %s", result.Status)
+	}
+	if !strings.Contains(result.Error, "read") {
+		t.Errorf("Expected read error, got: %s", result.Error)
+	}
+}
+
+func TestTCPChecker_Check_InvalidRegex(t *testing.T) {
+	addr := startServer(t, func(conn net.Conn) {})
+
+	checker := NewTCPChecker()
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     addr,
+		Timeout: time.Second,
+		TCP:     common.TCPOptions{ExpectRegex: `(`},
+	})
+
+	if result.Status != common.StatusDown {
+		t.Errorf("Expected status Down, got %s", result.Status)
+	}
+	if !strings.Contains(result.Error, "expect_regex") {
+		t.Errorf("Expected regex error, got: %s", result.Error)
+	}
+}
+
+type slowDialer struct {
+	delay time.Duration
+}
+
+func (d *slowDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	select {
+	case <-time.After(d.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	client, server := net.Pipe()
+	go server.Close()
+	return client, nil
+}
+
+func TestTCPChecker_Check_MaxLatency(t *testing.T) {
+	checker := NewTCPCheckerWithDialer(&slowDialer{delay: 150 * time.Millisecond})
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:        "127.0.0.1:6379",
+		Timeout:    time.Second,
+		MaxLatency: 50 * time.Millisecond,
+	})
+
+	if result.Status != common.StatusDegraded {
+		t.Errorf("Expected status Degraded due to latency, got %s", result.Status)
+	}
+	if !slices.Contains(result.Messages, common.UnexpectedLatencyMessage) {
+		t.Errorf("Expected message about unexpected latency")
+	}
+	if result.Elapsed < 150 {
+		t.Errorf("Expected elapsed time ~150ms, got %dms", result.Elapsed)
+	}
+}
+
+func TestTCPChecker_Check_DialTimeout(t *testing.T) {
+	checker := NewTCPCheckerWithDialer(&slowDialer{delay: time.Second})
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     "127.0.0.1:6379",
+		Timeout: 50 * time.Millisecond,
+	})
+
+	if result.Status != common.StatusUnreachable {
+		t.Errorf("Expected status Unreachable, got %s", result.Status)
+	}
+	if !strings.Contains(result.Error, "deadline exceeded") {
+		t.Errorf("Expected timeout error, got: %s", result.Error)
+	}
+}
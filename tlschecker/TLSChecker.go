@@ -0,0 +1,194 @@
+package tlschecker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math"
+	"net"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+type TLSChecker struct {
+	roots *x509.CertPool // nil means the system pool
+}
+
+// NewTLSChecker creates a new checker verifying chains against the system roots
+func NewTLSChecker() *TLSChecker {
+	return &TLSChecker{}
+}
+
+// NewTLSCheckerWithRoots allows injection of custom root certificates (for testing)
+func NewTLSCheckerWithRoots(roots *x509.CertPool) *TLSChecker {
+	return &TLSChecker{
+		roots: roots,
+	}
+}
+
+func (c *TLSChecker) Check(ctx context.Context, endpoint common.Endpoint) common.Result {
+	result := common.Result{
+		URL:      endpoint.URL,
+		Messages: make([]string, 0),
+	}
+
+	addr, err := common.ParseHostPort(endpoint.URL)
+	if err != nil {
+		result.Status = common.StatusUnreachable
+		result.Error = err.Error()
+		result.Timestamp = time.Now()
+		return result
+	}
+
+	serverName := endpoint.TLS.ServerName
+	if serverName == "" {
+		serverName, _, _ = net.SplitHostPort(addr)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, endpoint.Timeout)
+	defer cancel()
+
+	// The chain is verified below so that expired or untrusted certificates
+	// can still be described; nothing but the handshake goes over this connection.
+	dialer := &tls.Dialer{
+		Config: &tls.Config{
+			ServerName:         serverName,
+			InsecureSkipVerify: true,
+		},
+	}
+
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	elapsed := time.Since(start)
+
+	result.Elapsed = int(elapsed.Milliseconds())
+	result.Timestamp = time.Now()
+
+	if err != nil {
+		result.Status = common.StatusUnreachable
+		result.Error = err.Error()
+		return result
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		result.Status = common.StatusDown
+		result.Error = "no certificate presented"
+		return result
+	}
+
+	chainErr := c.verify(state.PeerCertificates, serverName)
+	result.Status = common.StatusUp
+	result.TLS = Inspect(state.PeerCertificates[0], chainErr, result.Timestamp)
+	Evaluate(&result, endpoint.TLS)
+
+	if endpoint.MaxLatency > 0 && elapsed > endpoint.MaxLatency {
+		if result.Status == common.StatusUp {
+			result.Status = common.StatusDegraded
+		}
+		result.Messages = append(result.Messages, common.UnexpectedLatencyMessage)
+	}
+
+	return result
+}
+
+// verify checks the presented chain against the roots and the server name.
+func (c *TLSChecker) verify(certs []*x509.Certificate, serverName string) error {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         c.roots,
+		Intermediates: intermediates,
+		DNSName:       serverName,
+	})
+	return err
+}
+
+// Inspect describes a leaf certificate; chainErr is the outcome of chain verification.
+func Inspect(cert *x509.Certificate, chainErr error, now time.Time) *common.TLSInfo {
+	info := &common.TLSInfo{
+		Subject:         cert.Subject.String(),
+		Issuer:          cert.Issuer.String(),
+		NotBefore:       cert.NotBefore,
+		NotAfter:        cert.NotAfter,
+		DaysUntilExpiry: int(math.Floor(cert.NotAfter.Sub(now).Hours() / 24)),
+		ChainValid:      chainErr == nil,
+	}
+
+	info.SANs = append(info.SANs, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+
+	if chainErr != nil {
+		info.ChainError = chainErr.Error()
+	}
+	return info
+}
+
+// Evaluate applies the expiry thresholds and chain validity of result.TLS to result.
+// It only ever makes the status worse.
+func Evaluate(result *common.Result, opts common.TLSOptions) {
+	info := result.TLS
+	if info == nil {
+		return
+	}
+
+	warn, critical := opts.WarnDays, opts.CriticalDays
+	if warn == 0 {
+		warn = common.DefaultTLSWarnDays
+	}
+	if critical == 0 {
+		critical = common.DefaultTLSCriticalDays
+	}
+
+	switch {
+	case info.DaysUntilExpiry < 0:
+		result.Status = common.WorseStatus(result.Status, common.StatusDown)
+		result.Messages = append(result.Messages, common.CertificateExpiredMessage)
+	case info.DaysUntilExpiry <= critical:
+		result.Status = common.WorseStatus(result.Status, common.StatusDown)
+		result.Messages = append(result.Messages, common.CertificateExpiringMessage)
+	case info.DaysUntilExpiry <= warn:
+		result.Status = common.WorseStatus(result.Status, common.StatusDegraded)
+		result.Messages = append(result.Messages, common.CertificateExpiringMessage)
+	}
+
+	// An expired certificate already explains the broken chain
+	if !info.ChainValid && info.DaysUntilExpiry >= 0 {
+		result.Status = common.WorseStatus(result.Status, common.StatusDown)
+		result.Messages = append(result.Messages, common.InvalidCertificateMessage)
+		if result.Error == "" {
+			result.Error = info.ChainError
+		}
+	}
+}
+
+// FromVerificationError extracts the offending certificate from a failed handshake,
+// so HTTPS checks can still report expiry details when the client rejected the chain.
+// It returns nil if err is not a certificate verification error.
+func FromVerificationError(err error, now time.Time) *common.TLSInfo {
+	var verifyErr *tls.CertificateVerificationError
+	if errors.As(err, &verifyErr) && len(verifyErr.UnverifiedCertificates) > 0 {
+		return Inspect(verifyErr.UnverifiedCertificates[0], verifyErr.Err, now)
+	}
+
+	var invalid x509.CertificateInvalidError
+	if errors.As(err, &invalid) && invalid.Cert != nil {
+		return Inspect(invalid.Cert, invalid, now)
+	}
+	var unknown x509.UnknownAuthorityError
+	if errors.As(err, &unknown) && unknown.Cert != nil {
+		return Inspect(unknown.Cert, unknown, now)
+	}
+	var hostname x509.HostnameError
+	if errors.As(err, &hostname) && hostname.Certificate != nil {
+		return Inspect(hostname.Certificate, hostname, now)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package tlschecker
+
+import (
+	"context"
+	"crypto/tls"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mohamedbeat/pulse/common"
+)
+
+// startServer runs a loopback TLS server presenting cert and completing handshakes.
+func startServer(t *testing.T, cert tls.Certificate) string {
+	t.Helper()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func newCA(t *testing.T) *MockCA {
+	t.Helper()
+
+	ca, err := NewMockCA()
+	if err != nil {
+		t.Fatalf("create CA: %v", err)
+	}
+	return ca
+}
+
+func issue(t *testing.T, ca *MockCA, validFor time.Duration) tls.Certificate {
+	t.Helper()
+
+	cert, err := ca.Issue(time.Now().Add(validFor))
+	if err != nil {
+		t.Fatalf("issue certificate: %v", err)
+	}
+	return cert
+}
+
+func TestTLSChecker_Check_Expiry(t *testing.T) {
+	ca := newCA(t)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name           string
+		validFor       time.Duration
+		expectedStatus string
+		message        string
+	}{
+		{"valid", 90 * day, common.StatusUp, ""},
+		{"within warning", 20 * day, common.StatusDegraded, common.CertificateExpiringMessage},
+		{"within critical", 3 * day, common.StatusDown, common.CertificateExpiringMessage},
+		{"expired", -2 * day, common.StatusDown, common.CertificateExpiredMessage},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			addr := startServer(t, issue(t, ca, tc.validFor))
+
+			checker := NewTLSCheckerWithRoots(ca.Pool)
+			result := checker.Check(context.Background(), common.Endpoint{
+				URL:     "tls://" + addr,
+				Timeout: time.Second,
+			})
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %s, got %s (messages: %v, error: %s)", tc.expectedStatus, result.Status, result.Messages, result.Error)
+			}
+			if tc.message != "" && !slices.Contains(result.Messages, tc.message) {
+				t.Errorf("Expected message %q, got %v", tc.message, result.Messages)
+			}
+			if tc.message == "" && len(result.Messages) != 0 {
+				t.Errorf("Expected no messages, got %v", result.Messages)
+			}
+			if result.TLS == nil {
+				t.Fatalf("Expected TLS details")
+			}
+			// A few milliseconds have passed since issuing, so the floor is one day lower
+			wantDays := int(tc.validFor/day) - 1
+			if got := result.TLS.DaysUntilExpiry; got != wantDays && got != wantDays+1 {
+				t.Errorf("Expected ~%d days until expiry, got %d", wantDays, got)
+			}
+		})
+	}
+}
+
+func TestTLSChecker_Check_Details(t *testing.T) {
+	ca := newCA(t)
+	addr := startServer(t, issue(t, ca, 90*24*time.Hour))
+
+	checker := NewTLSCheckerWithRoots(ca.Pool)
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     addr,
+		Timeout: time.Second,
+	})
+
+	if result.TLS == nil {
+		t.Fatalf("Expected TLS details")
+	}
+	if !result.TLS.ChainValid {
+		t.Errorf("Expected valid chain, got error %q", result.TLS.ChainError)
+	}
+	if result.TLS.Issuer != "CN=Pulse Test CA" {
+		t.Errorf("Expected issuer CN=Pulse Test CA, got %q", result.TLS.Issuer)
+	}
+	if !slices.Equal(result.TLS.SANs, []string{"localhost", "127.0.0.1"}) {
+		t.Errorf("Expected SANs [localhost 127.0.0.1], got %v", result.TLS.SANs)
+	}
+}
+
+func TestTLSChecker_Check_Thresholds(t *testing.T) {
+	ca := newCA(t)
+	addr := startServer(t, issue(t, ca, 20*24*time.Hour))
+
+	checker := NewTLSCheckerWithRoots(ca.Pool)
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     addr,
+		Timeout: time.Second,
+		TLS:     common.TLSOptions{WarnDays: 14, CriticalDays: 3},
+	})
+
+	if result.Status != common.StatusUp {
+		t.Errorf("Expected status Up with custom thresholds, got %s (messages: %v)", result.Status, result.Messages)
+	}
+}
+
+func TestTLSChecker_Check_UntrustedChain(t *testing.T) {
+	ca := newCA(t)
+	other := newCA(t)
+	addr := startServer(t, issue(t, ca, 90*24*time.Hour))
+
+	checker := NewTLSCheckerWithRoots(other.Pool)
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     addr,
+		Timeout: time.Second,
+	})
+
+	if result.Status != common.StatusDown {
+		t.Errorf("Expected status Down, got %s", result.Status)
+	}
+	if !slices.Contains(result.Messages, common.InvalidCertificateMessage) {
+		t.Errorf("Expected message %q, got %v", common.InvalidCertificateMessage, result.Messages)
+	}
+	if result.TLS == nil || result.TLS.ChainValid || result.TLS.ChainError == "" {
+		t.Errorf("Expected invalid chain details, got %+v", result.TLS)
+	}
+}
+
+func TestTLSChecker_Check_HostnameMismatch(t *testing.T) {
+	ca := newCA(t)
+	addr := startServer(t, issue(t, ca, 90*24*time.Hour))
+
+	checker := NewTLSCheckerWithRoots(ca.Pool)
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     addr,
+		Timeout: time.Second,
+		TLS:     common.TLSOptions{ServerName: "api.example.com"},
+	})
+
+	if result.Status != common.StatusDown {
+		t.Errorf("Expected status Down, got %s", result.Status)
+	}
+	if !slices.Contains(result.Messages, common.InvalidCertificateMessage) {
+		t.Errorf("Expected message %q, got %v", common.InvalidCertificateMessage, result.Messages)
+	}
+}
+
+func TestTLSChecker_Check_Unreachable(t *testing.T) {
+	checker := NewTLSChecker()
+	result := checker.Check(context.Background(), common.Endpoint{
+		URL:     "127.0.0.1:1",
+		Timeout: time.Second,
+	})
+
+	if result.Status != common.StatusUnreachable {
+		t.Errorf("Expected status Unreachable, got %s", result.Status)
+	}
+	if result.TLS != nil {
+		t.Errorf("Expected no TLS details")
+	}
+}